@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+	"github.com/zuhrulumam/csv_processor/internal/output"
+)
+
+// command is a processor subcommand
+type command struct {
+	name string
+	run  func(args []string) int
+}
+
+// commands are dispatched by their first argument; anything else runs the
+// processing pipeline, so "processor run" and "processor" are the same
+var commands = []command{
+	{"run", runProcess},
+	{"validate", runValidate},
+	{"count", runCount},
+	{"head", runHead},
+	{"convert", runConvert},
+	{"sort", runSort},
+	{"dedup", runDedup},
+	{"diff", runDiff},
+	{"watch", runWatch},
+	{"config", runConfig},
+}
+
+// lookupCommand finds a subcommand by name
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// readRecords streams records from the reader to fn until fn returns false
+// and returns the read errors, which are not reported once fn has stopped
+func readRecords(ctx context.Context, config csvproc.ReaderConfig, fn func(*csvproc.Record) bool) []error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	recordCh, errCh := csvproc.NewReader(config).Read(ctx)
+
+	stopped := false
+	for record := range recordCh {
+		if stopped {
+			continue
+		}
+		if !fn(record) {
+			stopped = true
+			cancel()
+		}
+	}
+
+	var errs []error
+	for err := range errCh {
+		if !stopped {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// parseDelimiter parses a delimiter flag: a single character, or tab
+func parseDelimiter(value string) (rune, error) {
+	switch value {
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	delim, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) || delim == '"' || delim == '\r' || delim == '\n' || delim == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q (want a single character or tab)", value)
+	}
+
+	return delim, nil
+}
+
+// createOutput opens path for writing, or returns stdout when path is empty
+func createOutput(path string) (*os.File, func(), error) {
+	if path == "" {
+		return os.Stdout, func() {}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, func() { file.Close() }, nil
+}
+
+// newRowWriter creates the output writer for a format: csv, tsv or json
+func newRowWriter(file *os.File, format string, config output.Config) (output.RowWriter, error) {
+	switch format {
+	case "csv":
+		return output.NewWriterWithConfig(file, config), nil
+	case "tsv":
+		config.Delimiter = '\t'
+		return output.NewWriterWithConfig(file, config), nil
+	case "json":
+		return output.NewJSONWriter(file), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q (want csv, tsv or json)", format)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/internal/jobconfig"
+)
+
+// envPrefix starts every environment variable the processor reads
+const envPrefix = "CSVPROC_"
+
+// inputsKey names the input file list in config files and the environment
+const inputsKey = "inputs"
+
+// applyConfig fills the options not given on the command line from the
+// environment and then from the config file, and resolves the input files.
+// It returns where each option's value came from.
+func applyConfig(fs *flag.FlagSet, configFile string) (map[string]string, []string, error) {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	configEnv := envPrefix + "CONFIG"
+	if configFile == "" {
+		configFile = os.Getenv(configEnv)
+		if configFile != "" {
+			fs.Set("config", configFile)
+		}
+	}
+
+	fileValues := jobconfig.Values{}
+	if configFile != "" {
+		values, err := jobconfig.Load(configFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileValues = values
+	}
+
+	envValues := jobconfig.FromEnv(envPrefix, os.Environ(), configEnv)
+
+	for _, name := range fileValues.Keys() {
+		if !configurable(fs, name) {
+			return nil, nil, fmt.Errorf("unknown option %q in %s", name, configFile)
+		}
+	}
+
+	// Other programs may use the prefix too; unknownEnv lists what is skipped
+	for _, name := range envValues.Keys() {
+		if !configurable(fs, name) {
+			delete(envValues, name)
+		}
+	}
+
+	sources := make(map[string]string)
+	var setErr error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if setErr != nil {
+			return
+		}
+
+		_, repeatable := f.Value.(*stringList)
+
+		var values []string
+		switch {
+		case explicit[f.Name]:
+			sources[f.Name] = "flag"
+			return
+		case f.Name == "config" && configFile != "":
+			sources[f.Name] = "env"
+			return
+		case envValues[f.Name] != nil:
+			sources[f.Name] = "env"
+			values = envValues[f.Name]
+			if repeatable {
+				values = jobconfig.SplitList(values[0])
+			}
+		case fileValues[f.Name] != nil:
+			sources[f.Name] = "file"
+			values = fileValues[f.Name]
+		default:
+			sources[f.Name] = "default"
+			return
+		}
+
+		if len(values) > 1 && !repeatable {
+			setErr = fmt.Errorf("option %s takes a single value, got %d from %s", f.Name, len(values), sources[f.Name])
+			return
+		}
+
+		for _, value := range values {
+			if err := fs.Set(f.Name, value); err != nil {
+				setErr = fmt.Errorf("invalid value %q for %s from %s: %w", value, f.Name, sources[f.Name], err)
+				return
+			}
+		}
+	})
+	if setErr != nil {
+		return nil, nil, setErr
+	}
+
+	// Input files follow the same precedence
+	var inputs []string
+	switch {
+	case fs.NArg() > 0:
+		sources[inputsKey] = "flag"
+		inputs = fs.Args()
+	case envValues[inputsKey] != nil:
+		sources[inputsKey] = "env"
+		inputs = jobconfig.SplitList(envValues[inputsKey][0])
+	case fileValues[inputsKey] != nil:
+		sources[inputsKey] = "file"
+		inputs = fileValues[inputsKey]
+	default:
+		sources[inputsKey] = "default"
+	}
+
+	return sources, inputs, nil
+}
+
+// unknownEnv returns the environment variables with the processor's prefix
+// that set no option, to warn about
+func unknownEnv(fs *flag.FlagSet) []string {
+	var names []string
+	for _, name := range jobconfig.FromEnv(envPrefix, os.Environ(), envPrefix+"CONFIG").Keys() {
+		if !configurable(fs, name) {
+			names = append(names, jobconfig.EnvName(envPrefix, name))
+		}
+	}
+	return names
+}
+
+// configurable reports whether an option may be set from a file or the environment
+func configurable(fs *flag.FlagSet, name string) bool {
+	if name == inputsKey {
+		return true
+	}
+	return name != "config" && name != "version" && fs.Lookup(name) != nil
+}
+
+// runConfig implements the config subcommand
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor config print [options] [file1.csv ...]
+
+Prints the effective configuration after applying flags, CSVPROC_* environment
+variables and the -config file, with the source of every value. The output is
+a valid YAML config file.
+`)
+		return 1
+	}
+
+	config, fs, err := parseFlags(args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	printConfig(os.Stdout, fs, config)
+	return 0
+}
+
+// printConfig writes the effective configuration as YAML
+func printConfig(w io.Writer, fs *flag.FlagSet, config *Config) {
+	fmt.Fprintln(w, "# Effective configuration (flags > environment > config file > defaults)")
+	if config.configFile != "" {
+		fmt.Fprintf(w, "# Config file: %s\n", config.configFile)
+	}
+	for _, name := range config.unknownEnv {
+		fmt.Fprintf(w, "# Ignored unknown environment variable %s\n", name)
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if !configurable(fs, f.Name) {
+			return
+		}
+
+		if list, ok := f.Value.(*stringList); ok {
+			printList(w, f.Name, *list, config.sources[f.Name])
+			return
+		}
+
+		fmt.Fprintf(w, "%s: %s  # %s\n", f.Name, yamlScalar(f.Value.String()), config.sources[f.Name])
+	})
+
+	printList(w, inputsKey, config.inputFiles, config.sources[inputsKey])
+}
+
+// reportConfig returns the effective configuration for the run report: the
+// value of every option config print shows, with where it came from
+func reportConfig(fs *flag.FlagSet, config *Config) map[string]any {
+	options := make(map[string]any)
+	fs.VisitAll(func(f *flag.Flag) {
+		if configurable(fs, f.Name) {
+			options[f.Name] = map[string]any{"value": optionValue(f.Value), "source": config.sources[f.Name]}
+		}
+	})
+
+	inputs := config.inputFiles
+	if inputs == nil {
+		inputs = []string{}
+	}
+	options[inputsKey] = map[string]any{"value": inputs, "source": config.sources[inputsKey]}
+
+	return map[string]any{
+		"config_file": config.configFile,
+		"options":     options,
+	}
+}
+
+// optionValue returns a flag's value as a JSON scalar or list; durations and
+// other values keep their flag syntax
+func optionValue(value flag.Value) any {
+	if list, ok := value.(*stringList); ok {
+		return append([]string{}, *list...)
+	}
+
+	if getter, ok := value.(flag.Getter); ok {
+		switch v := getter.Get().(type) {
+		case bool, int, int64, uint, uint64, float64, string:
+			return v
+		}
+	}
+
+	return value.String()
+}
+
+// printList writes a YAML block list, or [] when empty
+func printList(w io.Writer, name string, items []string, source string) {
+	if len(items) == 0 {
+		fmt.Fprintf(w, "%s: []  # %s\n", name, source)
+		return
+	}
+
+	fmt.Fprintf(w, "%s:  # %s\n", name, source)
+	for _, item := range items {
+		fmt.Fprintf(w, "  - %s\n", yamlScalar(item))
+	}
+}
+
+// yamlScalar quotes values that would not read back as the same plain scalar
+func yamlScalar(value string) string {
+	if value == "" || value != strings.TrimSpace(value) ||
+		strings.ContainsAny(value, "#'\"[]{}") || strings.Contains(value, ": ") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+	"github.com/zuhrulumam/csv_processor/internal/output"
+)
+
+// runConvert implements the convert subcommand
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+
+	hasHeader := fs.Bool("header", true, "CSV files have header row")
+	delimiter := fs.String("delimiter", ",", "Input field delimiter (a character or tab)")
+	format := fs.String("to", "csv", "Output format: csv, tsv or json (JSON Lines)")
+	outDelimiter := fs.String("out-delimiter", "", "Output field delimiter for -to csv (default: ,)")
+	crlf := fs.Bool("crlf", false, "End output lines with CRLF (csv and tsv)")
+	outputFile := fs.String("output", "", "Output file path (default: stdout)")
+	quiet := fs.Bool("quiet", false, "Suppress all output except errors")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor convert [-to csv|tsv|json] [options] <file1.csv> [file2.csv ...]
+
+Converts between CSV dialects, TSV and JSON Lines. Files are written in order
+with a single header; every file must have the same header. JSON Lines output
+has one object per record keyed by the header, or one array per record when
+-header=false.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: no input files specified\n")
+		return 1
+	}
+
+	inDelim, err := parseDelimiter(*delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	outputConfig := output.Config{CRLF: *crlf}
+	if *outDelimiter != "" {
+		if *format != "csv" {
+			fmt.Fprintf(os.Stderr, "Configuration error: -out-delimiter requires -to csv\n")
+			return 1
+		}
+		if outputConfig.Delimiter, err = parseDelimiter(*outDelimiter); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+
+	out, closeOutput, err := createOutput(*outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+		return 1
+	}
+	defer closeOutput()
+
+	writer, err := newRowWriter(out, *format, outputConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// Files are streamed one at a time to keep their order
+	var header []string
+	var writeErr error
+
+	for _, file := range files {
+		errs := readRecords(ctx, csvproc.ReaderConfig{
+			Files:     []string{file},
+			HasHeader: *hasHeader,
+			Delimiter: inDelim,
+		}, func(record *csvproc.Record) bool {
+			if *hasHeader && header == nil {
+				header = record.Headers
+				if writeErr = writer.WriteHeader(header); writeErr != nil {
+					return false
+				}
+			}
+			if *hasHeader && strings.Join(record.Headers, "\x00") != strings.Join(header, "\x00") {
+				writeErr = fmt.Errorf("%s: header does not match the first file", file)
+				return false
+			}
+
+			writeErr = writer.Write(record.Data)
+			return writeErr == nil
+		})
+
+		if writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Conversion failed: %v\n", writeErr)
+			return 1
+		}
+		if len(errs) > 0 {
+			writer.Flush()
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			return 1
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Output error: %v\n", err)
+		return 1
+	}
+
+	if !*quiet && *outputFile != "" {
+		fmt.Printf("Converted %d records from %d files to %s\n", writer.Rows(), len(files), *outputFile)
+	}
+
+	if ctx.Err() != nil {
+		return 1
+	}
+	return 0
+}
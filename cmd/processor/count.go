@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// runCount implements the count subcommand
+func runCount(args []string) int {
+	fs := flag.NewFlagSet("count", flag.ExitOnError)
+
+	hasHeader := fs.Bool("header", true, "CSV files have header row, which is not counted")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor count [options] <file1.csv> [file2.csv ...]
+
+Counts data records per file without parsing fields. Line breaks inside quoted
+fields are handled, and empty lines are not counted. Files are counted
+concurrently, and .gz files are decompressed as they are read.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: no input files specified\n")
+		return 1
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	counts, errs := reader.CountFiles(ctx, files, *hasHeader)
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Count interrupted\n")
+		return 1
+	}
+
+	var total int64
+	failed := 0
+	for i, file := range files {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, errs[i])
+			failed++
+			continue
+		}
+		total += counts[i]
+		fmt.Printf("%12d  %s\n", counts[i], file)
+	}
+
+	if len(files) > 1 {
+		fmt.Printf("%12d  total\n", total)
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+)
+
+// currentPipeline is the pipeline running now, shown by the diagnostics
+// endpoint; an incremental run replaces it for every file
+var currentPipeline atomic.Pointer[csvproc.Pipeline]
+
+// debugStarted is when the process started, for the diagnostics uptime
+var debugStarted = time.Now()
+
+// runtimeStats are the Go runtime figures shown by the diagnostics endpoint
+type runtimeStats struct {
+	Goroutines     int     `json:"goroutines"`
+	HeapAllocBytes uint64  `json:"heap_alloc_bytes"`
+	HeapObjects    uint64  `json:"heap_objects"`
+	SysBytes       uint64  `json:"sys_bytes"`
+	GCCycles       uint32  `json:"gc_cycles"`
+	GCPauseSeconds float64 `json:"gc_pause_total_seconds"`
+	GOMAXPROCS     int     `json:"gomaxprocs"`
+	UptimeSeconds  float64 `json:"uptime_seconds"`
+}
+
+// diagnostics is the body of /debug/diagnostics
+type diagnostics struct {
+	Runtime  runtimeStats         `json:"runtime"`
+	Pipeline *csvproc.Diagnostics `json:"pipeline"`
+}
+
+// debugHandler serves net/http/pprof under /debug/pprof/ and a JSON snapshot
+// of the runtime and the current pipeline at /debug/diagnostics
+func debugHandler() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/diagnostics", func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		body := diagnostics{
+			Runtime: runtimeStats{
+				Goroutines:     runtime.NumGoroutine(),
+				HeapAllocBytes: mem.HeapAlloc,
+				HeapObjects:    mem.HeapObjects,
+				SysBytes:       mem.Sys,
+				GCCycles:       mem.NumGC,
+				GCPauseSeconds: time.Duration(mem.PauseTotalNs).Seconds(),
+				GOMAXPROCS:     runtime.GOMAXPROCS(0),
+				UptimeSeconds:  time.Since(debugStarted).Seconds(),
+			},
+		}
+
+		if pipe := currentPipeline.Load(); pipe != nil {
+			d := pipe.Diagnostics()
+			body.Pipeline = &d
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(body)
+	})
+
+	return mux
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// runDedup implements the dedup subcommand
+func runDedup(args []string) int {
+	fs := flag.NewFlagSet("dedup", flag.ExitOnError)
+
+	key := fs.String("key", "", "Comma-separated key columns (default: full row)")
+	keep := fs.String("keep", "first", "Which duplicate survives: first or last")
+	output := fs.String("output", "", "Output file path (default: stdout)")
+	removed := fs.String("removed", "", "Write removed rows with their surviving row to this CSV file")
+	hasHeader := fs.Bool("header", true, "CSV files have header row")
+	delimiter := fs.String("delimiter", ",", "Field delimiter of the input and output (a character or tab)")
+	quiet := fs.Bool("quiet", false, "Suppress all output except errors")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor dedup [-key COLS] [-keep first|last] [options] <file1.csv> [file2.csv ...]
+
+Removes duplicate rows by key columns or full row, preserving file and line order.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: no input files specified\n")
+		return 1
+	}
+
+	delim, err := parseDelimiter(*delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	var keyColumns []string
+	if *key != "" {
+		keyColumns = strings.Split(*key, ",")
+	}
+
+	// Cancel on SIGINT/SIGTERM; the partial output is removed
+	ctx, stop := signalContext()
+	defer stop()
+
+	dedup, err := processor.NewDedupProcessor(ctx, processor.DedupConfig{
+		Files:      files,
+		HasHeader:  *hasHeader,
+		Delimiter:  delim,
+		KeyColumns: keyColumns,
+		Keep:       processor.KeepPolicy(*keep),
+	})
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Dedup interrupted\n")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create dedup processor: %v\n", err)
+		return 1
+	}
+
+	var created []string
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		created = append(created, *output)
+		out = file
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Comma = delim
+
+	var removedWriter *csv.Writer
+	if *removed != "" {
+		file, err := os.Create(*removed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create removed rows file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		created = append(created, *removed)
+
+		removedWriter = csv.NewWriter(file)
+		removedWriter.Write([]string{"file", "line", "survivor_file", "survivor_line"})
+	}
+
+	// Files are streamed one at a time so survivors keep their original order
+	kept := 0
+	headerWritten := false
+
+	for _, file := range files {
+		csvReader := reader.NewCSVReader(reader.Config{
+			Files:     []string{file},
+			HasHeader: *hasHeader,
+			Delimiter: delim,
+		})
+
+		recordCh, errCh := csvReader.Read(ctx)
+
+		for record := range recordCh {
+			if ctx.Err() != nil {
+				continue // drain so the reader can exit
+			}
+
+			if !headerWritten && record.Headers != nil {
+				writer.Write(record.Headers)
+				headerWritten = true
+			}
+
+			result, err := dedup.Process(ctx, record)
+			if err != nil && ctx.Err() != nil {
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Dedup failed: %v\n", err)
+				return 1
+			}
+
+			if duplicate, ok := result.ProcessedData.(processor.Duplicate); ok {
+				if removedWriter != nil {
+					removedWriter.Write([]string{
+						record.FileName,
+						strconv.Itoa(record.LineNumber),
+						duplicate.Survivor.FileName,
+						strconv.Itoa(duplicate.Survivor.LineNumber),
+					})
+				}
+				continue
+			}
+
+			writer.Write(record.Data)
+			kept++
+		}
+
+		for err := range errCh {
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Dedup failed: %v\n", err)
+			return 1
+		}
+
+		if ctx.Err() != nil {
+			for _, path := range created {
+				os.Remove(path)
+			}
+			fmt.Fprintf(os.Stderr, "Dedup interrupted\n")
+			return 1
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+		return 1
+	}
+
+	if removedWriter != nil {
+		removedWriter.Flush()
+		if err := removedWriter.Error(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write removed rows: %v\n", err)
+			return 1
+		}
+	}
+
+	if !*quiet && *output != "" {
+		fmt.Printf("Kept %d records, removed %d duplicates\n", kept, dedup.Duplicates())
+	}
+
+	return 0
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/internal/diff"
+)
+
+// runDiff implements the diff subcommand. It exits 0 when the files match,
+// 1 when they differ and 2 on error, like diff(1).
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+
+	key := fs.String("key", "", "Comma-separated key columns identifying rows (required)")
+	delimiter := fs.String("delimiter", ",", "Field delimiter of both inputs (a character or tab)")
+	format := fs.String("format", "csv", "Output format: csv or json")
+	output := fs.String("output", "", "Output file path (default: stdout)")
+	memory := fs.String("memory", "64MB", "Memory budget for sorting each input (e.g. 512MB, 2GB)")
+	workers := fs.Int("workers", runtime.NumCPU(), "Number of workers building sorted runs")
+	tempDir := fs.String("tmp", "", "Directory for temporary files (default: system temp)")
+	quiet := fs.Bool("quiet", false, "Suppress all output except errors")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor diff -key COLS [options] <old.csv> <new.csv>
+
+Reports rows added, removed and changed between two CSV files with header rows.
+Both files are sorted by key on disk, so they may be larger than memory.
+
+Exit status is 0 if the files match, 1 if they differ and 2 on error.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if *key == "" {
+		fmt.Fprintf(os.Stderr, "Configuration error: -key is required\n")
+		return 2
+	}
+
+	files := fs.Args()
+	if len(files) != 2 {
+		fmt.Fprintf(os.Stderr, "Configuration error: expected exactly two input files\n")
+		return 2
+	}
+
+	delim, err := parseDelimiter(*delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 2
+	}
+
+	budget, err := parseByteSize(*memory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 2
+	}
+
+	keyColumns := strings.Split(*key, ",")
+
+	differ, err := diff.NewDiffer(diff.Config{
+		KeyColumns:   keyColumns,
+		Delimiter:    delim,
+		MemoryBudget: budget,
+		Workers:      *workers,
+		TempDir:      *tempDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create differ: %v\n", err)
+		return 2
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 2
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer, err := diff.NewWriter(out, diff.Format(*format), keyColumns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 2
+	}
+
+	// Cancel on SIGINT/SIGTERM so the sorted copies are removed
+	ctx, stop := signalContext()
+	defer stop()
+
+	stats, err := differ.Diff(ctx, files[0], files[1], writer.Write)
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Diff interrupted\n")
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Diff failed: %v\n", err)
+		return 2
+	}
+
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+		return 2
+	}
+
+	if !*quiet && *output != "" {
+		fmt.Printf("Added %d, removed %d, changed %d, unchanged %d\n",
+			stats.Added, stats.Removed, stats.Changed, stats.Unchanged)
+	}
+
+	if !stats.Identical() {
+		return 1
+	}
+
+	return 0
+}
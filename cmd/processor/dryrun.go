@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+	"github.com/zuhrulumam/csv_processor/internal/manifest"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// dryRunSample is how many records are read from each file for the plan
+const dryRunSample = 1000
+
+// dryRunPlan collects what a dry run found
+type dryRunPlan struct {
+	files    []*reader.FileInfo
+	header   []string
+	problems []string
+	notes    []string
+}
+
+// problem records something that would make the run fail or misbehave
+func (p *dryRunPlan) problem(format string, args ...interface{}) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+// note records something worth knowing that does not block the run
+func (p *dryRunPlan) note(format string, args ...interface{}) {
+	p.notes = append(p.notes, fmt.Sprintf(format, args...))
+}
+
+// runDryRun inspects inputs, references and outputs and prints what a run
+// would do, without processing or writing anything. It returns 1 when the
+// run would fail.
+func runDryRun(config *Config) int {
+	plan := &dryRunPlan{}
+
+	for _, name := range config.unknownEnv {
+		plan.note("ignoring unknown environment variable %s", name)
+	}
+
+	plan.inspectInputs(config)
+	plan.checkColumns(config)
+	plan.checkOutputs(config)
+
+	var pending []string
+	if config.incremental {
+		pending = plan.checkManifest(config)
+	}
+
+	processRate := plan.sampleThroughput(config)
+
+	printDryRun(config, plan, processRate, pending)
+
+	if len(plan.problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// inspectInputs reads the header and first records of every input
+func (p *dryRunPlan) inspectInputs(config *Config) {
+	for _, file := range config.inputFiles {
+		info, err := reader.Inspect(file, config.hasHeader, config.delim, dryRunSample)
+		if err != nil {
+			p.problem("%s: %v", file, err)
+			continue
+		}
+		p.files = append(p.files, info)
+
+		if info.Delimiter != config.delim && misdelimited(info) {
+			p.problem("%s: looks %s-delimited, but the delimiter is %s", file, delimiterName(info.Delimiter), delimiterName(config.delim))
+		}
+		if info.BOM {
+			p.problem("%s: starts with a UTF-8 byte order mark, which becomes part of the first column name", file)
+		}
+		if info.HeaderErr != nil {
+			p.problem("%s: invalid header: %v", file, info.HeaderErr)
+		}
+		if info.SampleErr != nil {
+			p.problem("%s: %v", file, info.SampleErr)
+		}
+		if info.CRLF {
+			p.note("%s: uses CRLF line endings", file)
+		}
+
+		if !config.hasHeader {
+			continue
+		}
+
+		if p.header == nil {
+			p.header = info.Header
+			continue
+		}
+
+		if config.validateHeader && strings.Join(info.Header, ",") != strings.Join(p.header, ",") {
+			p.problem("%s: header %s does not match %s", file, strings.Join(info.Header, ","), strings.Join(p.header, ","))
+		}
+	}
+}
+
+// checkColumns checks that the columns used by aggregation, enrichment and
+// integrity checks exist in the inputs and reference files
+func (p *dryRunPlan) checkColumns(config *Config) {
+	if p.header == nil {
+		return
+	}
+
+	require := func(what, column string) {
+		if !containsColumn(p.header, column) {
+			p.problem("%s column %q is not in the input header", what, column)
+		}
+	}
+
+	if config.groupBy != "" {
+		for _, column := range strings.Split(config.groupBy, ",") {
+			require("-group-by", column)
+		}
+	}
+
+	if config.aggregates != "" {
+		aggs, err := csvproc.ParseAggregates(config.aggregates)
+		if err != nil {
+			p.problem("-agg: %v", err)
+		}
+		for _, agg := range aggs {
+			if agg.Column != "" {
+				require("-agg", agg.Column)
+			}
+		}
+	}
+
+	for _, spec := range config.foreignKeys {
+		fk, err := parseForeignKey(spec)
+		if err != nil {
+			p.problem("%v", err)
+			continue
+		}
+		require("-ref", fk.Column)
+
+		refColumn := fk.RefColumn
+		if refColumn == "" {
+			refColumn = fk.Column
+		}
+		p.checkReference("-ref", fk.RefFile, config.refDelim, refColumn)
+	}
+
+	for _, spec := range config.enrichRefs {
+		ref, err := parseReference(spec)
+		if err != nil {
+			p.problem("%v", err)
+			continue
+		}
+		require("-enrich lookup", ref.LookupColumn)
+		p.checkReference("-enrich", ref.File, config.refDelim, append([]string{ref.KeyColumn}, ref.Columns...)...)
+	}
+}
+
+// checkReference checks that a reference file exists and has the given columns
+func (p *dryRunPlan) checkReference(what, file string, delimiter rune, columns ...string) {
+	info, err := reader.Inspect(file, true, delimiter, 0)
+	if err != nil {
+		p.problem("%s reference %s: %v", what, file, err)
+		return
+	}
+
+	for _, column := range columns {
+		if !containsColumn(info.Header, column) {
+			p.problem("%s reference %s has no column %q", what, file, column)
+		}
+	}
+}
+
+// checkOutputs checks that the output, checkpoint, run report and manifest
+// locations are usable
+func (p *dryRunPlan) checkOutputs(config *Config) {
+	for _, file := range []string{config.outputFile, config.checkpointFile, config.runReport} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(filepath.Dir(file)); err != nil || !info.IsDir() {
+			p.problem("directory for %s does not exist", file)
+		}
+	}
+
+	if config.resume {
+		if _, err := os.Stat(config.checkpointFile); os.IsNotExist(err) {
+			p.note("checkpoint %s does not exist; the run starts from the beginning", config.checkpointFile)
+		}
+	}
+}
+
+// checkManifest returns the inputs an incremental run would process
+func (p *dryRunPlan) checkManifest(config *Config) []string {
+	m, err := manifest.Load(config.manifestFile)
+	if err != nil {
+		p.problem("%v", err)
+		return nil
+	}
+
+	var pending []string
+	for _, file := range config.inputFiles {
+		fp, err := manifest.Stat(file)
+		if err != nil {
+			continue
+		}
+		done, err := m.Processed(&fp)
+		if err != nil {
+			p.problem("%s: %v", file, err)
+			continue
+		}
+		if !done {
+			pending = append(pending, file)
+		}
+	}
+
+	return pending
+}
+
+// sampleThroughput times the processor over the sampled records on the
+// configured number of workers and returns records per second. Enrichment
+// and integrity lookups are left out so that no reference index is built.
+func (p *dryRunPlan) sampleThroughput(config *Config) float64 {
+	var proc csvproc.Processor = csvproc.NewDefaultProcessor()
+	if config.aggregates != "" {
+		if aggProc, err := buildProcessor(context.Background(), config); err == nil {
+			proc = aggProc
+		}
+	}
+
+	var records []*csvproc.Record
+	for _, info := range p.files {
+		for i, data := range info.Sample {
+			records = append(records, csvproc.NewRecord(i+2, filepath.Base(info.Path), data, info.Header))
+		}
+	}
+	if len(records) == 0 {
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan *csvproc.Record, len(records))
+	for _, record := range records {
+		next <- record
+	}
+	close(next)
+
+	start := time.Now()
+	for i := 0; i < config.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range next {
+				proc.Process(context.Background(), record)
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(len(records)) / elapsed.Seconds()
+}
+
+// printDryRun prints the plan
+func printDryRun(config *Config, plan *dryRunPlan, processRate float64, pending []string) {
+	fmt.Println("========================================")
+	fmt.Println("Dry Run Plan")
+	fmt.Println("========================================")
+
+	var totalRows int64
+	var sampled int
+	var parseTime time.Duration
+
+	fmt.Printf("Inputs:         %d files\n", len(config.inputFiles))
+	for _, info := range plan.files {
+		rows := info.EstimatedRows()
+		totalRows += rows
+		sampled += len(info.Sample)
+		parseTime += info.ParseTime
+
+		estimate := "~"
+		if info.Complete {
+			estimate = ""
+		}
+
+		path, _ := filepath.Abs(info.Path)
+		fmt.Printf("  %s  %s  %s%d rows\n", path, formatByteSize(info.Size), estimate, rows)
+	}
+	fmt.Printf("Delimiter:      %s\n", delimiterName(config.delim))
+	if plan.header != nil {
+		fmt.Printf("Columns:        %s\n", strings.Join(plan.header, ","))
+	}
+
+	if config.incremental {
+		fmt.Printf("Incremental:    %d of %d files pending (manifest %s)\n", len(pending), len(config.inputFiles), config.manifestFile)
+	}
+
+	fmt.Printf("Workers:        %d\n", config.workers)
+	fmt.Printf("Buffer Size:    %d\n", config.bufferSize)
+	fmt.Printf("Processor:      %s\n", describeProcessor(config))
+
+	switch {
+	case config.outputFile != "" && config.resume:
+		fmt.Printf("Output File:    %s (appended after resume)\n", config.outputFile)
+	case config.outputFile != "":
+		if _, err := os.Stat(config.outputFile); err == nil {
+			fmt.Printf("Output File:    %s (will be overwritten)\n", config.outputFile)
+		} else {
+			fmt.Printf("Output File:    %s (will be created)\n", config.outputFile)
+		}
+	case config.aggregates != "":
+		fmt.Println("Output File:    stdout")
+	default:
+		fmt.Println("Output File:    none")
+	}
+
+	if config.checkpointFile != "" {
+		fmt.Printf("Checkpoint:     %s every %s\n", config.checkpointFile, config.checkpointInterval)
+	}
+	if config.errorThreshold > 0 {
+		fmt.Printf("Error Threshold: %.1f%% (abort: %v)\n", config.errorThreshold*100, config.abortOnError)
+	}
+
+	if sampled > 0 && parseTime > 0 {
+		// Files are read concurrently, one goroutine each
+		readers := len(plan.files)
+		if readers > runtime.NumCPU() {
+			readers = runtime.NumCPU()
+		}
+		parseRate := float64(sampled) / parseTime.Seconds() * float64(readers)
+
+		expected := parseRate
+		if processRate > 0 && processRate < expected {
+			expected = processRate
+		}
+
+		fmt.Printf("Sample:         %d records (parse %.0f rec/s, process %.0f rec/s)\n", sampled, parseRate, processRate)
+		if config.follow {
+			fmt.Printf("Expected:       ~%.0f records/sec\n", expected)
+		} else {
+			eta := time.Duration(float64(totalRows) / expected * float64(time.Second))
+			fmt.Printf("Expected:       ~%.0f records/sec, ~%s for ~%d rows\n", expected, eta.Round(time.Second), totalRows)
+		}
+	}
+
+	for _, note := range plan.notes {
+		fmt.Printf("Note: %s\n", note)
+	}
+
+	if len(plan.problems) > 0 {
+		fmt.Println("----------------------------------------")
+		fmt.Printf("Problems:       %d\n", len(plan.problems))
+		for _, problem := range plan.problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+	}
+
+	fmt.Println("========================================")
+}
+
+// describeProcessor summarizes the processor a run would use
+func describeProcessor(config *Config) string {
+	var parts []string
+
+	switch {
+	case config.aggregates != "":
+		parts = append(parts, fmt.Sprintf("aggregate %s", config.aggregates))
+		if config.groupBy != "" {
+			parts[0] += " by " + config.groupBy
+		}
+	case len(config.enrichRefs) > 0:
+		parts = append(parts, fmt.Sprintf("enrich from %d references (%s index, not sampled)", len(config.enrichRefs), config.enrichIndex))
+	default:
+		parts = append(parts, "default")
+	}
+
+	if len(config.foreignKeys) > 0 {
+		parts = append(parts, fmt.Sprintf("%d integrity checks (not sampled)", len(config.foreignKeys)))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// containsColumn reports whether header has column
+func containsColumn(header []string, column string) bool {
+	for _, name := range header {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// misdelimited reports whether the first row of a file, read with the
+// configured delimiter, is a single field holding the guessed delimiter
+func misdelimited(info *reader.FileInfo) bool {
+	row := info.Header
+	if row == nil && len(info.Sample) > 0 {
+		row = info.Sample[0]
+	}
+	return len(row) == 1 && strings.ContainsRune(row[0], info.Delimiter)
+}
+
+// delimiterName names a delimiter for messages
+func delimiterName(delim rune) string {
+	switch delim {
+	case ',':
+		return "comma"
+	case '\t':
+		return "tab"
+	case ';':
+		return "semicolon"
+	case '|':
+		return "pipe"
+	}
+	return string(delim)
+}
+
+// formatByteSize formats a size such as 12.3MB
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
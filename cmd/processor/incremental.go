@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+	"github.com/zuhrulumam/csv_processor/internal/manifest"
+)
+
+// runIncremental processes the input files that the manifest does not list as
+// processed successfully, one pipeline per file, and records each outcome
+func runIncremental(ctx context.Context, config *Config, base csvproc.Config) int {
+	m, err := manifest.Load(config.manifestFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load manifest: %v\n", err)
+		return 1
+	}
+
+	var pending []manifest.Fingerprint
+	skipped := 0
+
+	for _, file := range config.inputFiles {
+		fp, err := manifest.Stat(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to stat %s: %v\n", file, err)
+			return 1
+		}
+
+		done, err := m.Processed(&fp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to check %s: %v\n", file, err)
+			return 1
+		}
+
+		if done {
+			skipped++
+			if !config.quiet {
+				fmt.Printf("Skipping %s (already processed)\n", file)
+			}
+			continue
+		}
+
+		pending = append(pending, fp)
+	}
+
+	// Persist mtimes refreshed by hash matches
+	if err := m.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save manifest: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+
+	for _, fp := range pending {
+		// Hash before processing so a file changed mid-run is picked up next time
+		if fp.Hash == "" {
+			if fp.Hash, err = manifest.HashFile(fp.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to hash %s: %v\n", fp.Path, err)
+				return 1
+			}
+		}
+
+		pipelineConfig := base
+		pipelineConfig.Files = []string{fp.Path}
+
+		pipe, err := csvproc.NewPipeline(pipelineConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create pipeline: %v\n", err)
+			return 1
+		}
+
+		if !config.quiet {
+			fmt.Printf("Processing %s\n", fp.Path)
+		}
+
+		entry := manifest.Entry{Fingerprint: fp, ProcessedAt: time.Now()}
+		currentPipeline.Store(pipe)
+		runErr := pipe.Run(ctx)
+
+		// An interrupted file is neither done nor failed; leave it for the next
+		// run, exiting like an interrupted single run
+		if ctx.Err() != nil {
+			slog.Warn("interrupted, file left for the next run", "file", fp.Path)
+			return exitCode(ctx.Err())
+		}
+
+		summary := pipe.Summary()
+		entry.Status, entry.Error = fileOutcome(pipe, runErr)
+		entry.Records = summary.TotalRecords()
+		entry.Succeeded = summary.SuccessCount()
+		entry.Failed = summary.FailedCount()
+		entry.Errors = pipe.Errors().Count()
+		entry.Duration = summary.Duration()
+
+		m.Record(entry)
+		if err := m.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save manifest: %v\n", err)
+			return 1
+		}
+
+		if entry.Status == manifest.StatusFailed {
+			failed++
+			slog.Error("file failed", "file", fp.Path, "error", entry.Error)
+		}
+
+		if !config.quiet {
+			printFinalSummary(pipe, config.slowRecords)
+		}
+	}
+
+	if !config.quiet {
+		fmt.Printf("\nIncremental run: %d processed, %d skipped, %d failed\n", len(pending), skipped, failed)
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// fileOutcome decides whether a file was processed successfully. Record-level
+// failures are counted but do not fail the file unless the error threshold was
+// exceeded; file-level errors such as read failures always do.
+func fileOutcome(pipe *csvproc.Pipeline, runErr error) (manifest.Status, string) {
+	if runErr != nil {
+		return manifest.StatusFailed, runErr.Error()
+	}
+
+	if pipe.Errors().ThresholdExceeded() {
+		return manifest.StatusFailed, "error threshold exceeded"
+	}
+
+	for _, entry := range pipe.Errors().Errors() {
+		if entry.Record == nil {
+			return manifest.StatusFailed, entry.Error.Error()
+		}
+	}
+
+	return manifest.StatusSuccess, ""
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"text/tabwriter"
 	"time"
 
-	"github.com/zuhrulumam/csv_processor/internal/pipeline"
-	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/csvproc"
 )
 
 var (
@@ -19,53 +25,217 @@ var (
 )
 
 func main() {
-	// Parse command line flags
-	config := parseFlags()
+	// Dispatch subcommands; anything else runs the processing pipeline
+	if len(os.Args) > 1 {
+		if cmd, ok := lookupCommand(os.Args[1]); ok {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+
+	os.Exit(runProcess(os.Args[1:]))
+}
+
+// runProcess implements the default command: run files through the pipeline
+func runProcess(args []string) int {
+	// Resolve options from flags, environment and config file
+	config, fs, err := parseFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
 
 	// Show version and exit
 	if config.showVersion {
 		printVersion()
-		os.Exit(0)
+		return 0
 	}
 
 	// Validate configuration
 	if err := config.validate(); err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+
+	// Print the plan without processing anything
+	if config.dryRun {
+		return runDryRun(config)
+	}
+
+	// Every log line of this invocation carries its run ID, including those
+	// of each pipeline in an incremental run
+	logger, err := newLogger(os.Stderr, config.logFormat, config.logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	runID := csvproc.NewRunID()
+	slog.SetDefault(logger.With("run_id", runID))
+
+	for _, name := range config.unknownEnv {
+		slog.Warn("ignoring unknown environment variable", "name", name)
+	}
+
+	// Cancel on SIGINT/SIGTERM so the pipeline can shut down gracefully
+	ctx, stop := signalContext()
+	defer stop()
+
+	// Build the record processor
+	proc, err := buildProcessor(ctx, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create processor: %v\n", err)
+		return 1
+	}
+	if closer, ok := proc.(interface{ Close() error }); ok {
+		defer closer.Close()
 	}
 
 	// Create pipeline configuration
-	pipelineConfig := pipeline.Config{
+	pipelineConfig := csvproc.Config{
 		Files:          config.inputFiles,
 		HasHeader:      config.hasHeader,
 		ValidateHeader: config.validateHeader,
+		Delimiter:      config.delim,
+		Follow:         config.follow,
+		FollowPoll:     config.followPoll,
 		Workers:        config.workers,
-		Processor:      processor.NewDefaultProcessor(),
+		Processor:      proc,
 		BufferSize:     config.bufferSize,
 		MaxErrors:      config.maxErrors,
 		ErrorThreshold: config.errorThreshold,
 		AbortOnError:   config.abortOnError,
 		ShowProgress:   config.showProgress,
 		VerboseOutput:  config.verbose,
+		ProgressFormat: config.progressFormat,
+		Prescan:        config.prescan,
+		SlowRecords:    config.slowRecords,
+
+		CheckpointFile:     config.checkpointFile,
+		CheckpointInterval: config.checkpointInterval,
+		Resume:             config.resume,
+
+		// The error report at the end of the run is logged in -log-format too
+		Logger:   logger,
+		RunID:    runID,
+		ExitCode: exitCode,
 	}
 
-	// Open output file if specified
+	// The report is written at the end of the run, so check where it goes first
+	if config.runReport != "" {
+		if info, err := os.Stat(filepath.Dir(config.runReport)); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Configuration error: directory for run report %s does not exist\n", config.runReport)
+			return 1
+		}
+
+		pipelineConfig.RunReport = config.runReport
+		pipelineConfig.ReportConfig = reportConfig(fs, config)
+	}
+
+	// Serve metrics for the whole run, including every incremental file
+	if config.metricsAddr != "" {
+		pipelineConfig.Metrics = csvproc.NewMetricsRegistry()
+	}
+	if config.metricsAddr != "" && config.metricsAddr != config.debugAddr {
+		shutdown, err := startMetricsServer(config.metricsAddr, pipelineConfig.Metrics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start metrics server: %v\n", err)
+			return 1
+		}
+		defer shutdown()
+	}
+
+	// Serve pprof and diagnostics, with metrics too when given the same address
+	if config.debugAddr != "" {
+		mux := debugHandler()
+		if config.metricsAddr == config.debugAddr {
+			mux.Handle("/metrics", pipelineConfig.Metrics.Handler())
+		}
+
+		shutdown, err := startServer(config.debugAddr, mux, "Debug")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start debug server: %v\n", err)
+			return 1
+		}
+		defer shutdown()
+	}
+
+	// Trace every pipeline of the run, written once it ends
+	if config.traceFile != "" {
+		file, err := os.Create(config.traceFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create trace file: %v\n", err)
+			return 1
+		}
+
+		pipelineConfig.Tracer = csvproc.NewTracer()
+		defer writeTrace(file, pipelineConfig.Tracer)
+	}
+
+	// Progress goes to stdout unless -progress-output names a file or socket
+	if config.showProgress {
+		writer, closeProgress, err := openProgressOutput(config.progressOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open progress output: %v\n", err)
+			return 1
+		}
+		defer closeProgress()
+
+		pipelineConfig.ProgressWriter = writer
+
+		// A terminal gets bars per file, a throughput sparkline and recent
+		// errors; piped or redirected output keeps the plain progress line
+		if config.dashboard && config.progressFormat == csvproc.ProgressText && !config.verbose && csvproc.IsTerminal(writer) {
+			pipelineConfig.ProgressFormat = csvproc.ProgressDashboard
+		}
+	}
+
+	// Open output file if specified; a resumed run keeps the existing output
 	if config.outputFile != "" {
-		file, err := os.Create(config.outputFile)
+		openOutput := os.Create
+		if config.resume {
+			openOutput = func(name string) (*os.File, error) {
+				return os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+			}
+		}
+
+		file, err := openOutput(config.outputFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
-			os.Exit(1)
+			return 1
 		}
 		defer file.Close()
 
 		pipelineConfig.OutputWriter = file
+	} else if config.aggregates != "" {
+		// Aggregates go to stdout, so keep the terminal output clean
+		pipelineConfig.OutputWriter = os.Stdout
+		pipelineConfig.ShowProgress = false
+		config.quiet = true
+	}
+
+	// Parse referential integrity constraints
+	for _, spec := range config.foreignKeys {
+		fk, err := parseForeignKey(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+		fk.Delimiter = config.refDelim
+		pipelineConfig.ForeignKeys = append(pipelineConfig.ForeignKeys, fk)
+	}
+
+	// Incremental runs use one pipeline per file not yet processed
+	if config.incremental {
+		if !config.quiet {
+			printStartupInfo(config)
+		}
+		return runIncremental(ctx, config, pipelineConfig)
 	}
 
 	// Create and run pipeline
-	pipe, err := pipeline.NewPipeline(pipelineConfig)
+	pipe, err := csvproc.NewPipeline(pipelineConfig)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create pipeline: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	// Print startup info
@@ -73,16 +243,29 @@ func main() {
 		printStartupInfo(config)
 	}
 
-	// Run pipeline
-	if err := pipe.Run(); err != nil {
+	currentPipeline.Store(pipe)
+
+	// Run pipeline; an interrupted run still prints its summary
+	if err := pipe.Run(ctx); exitCode(err) != 0 {
 		fmt.Fprintf(os.Stderr, "Pipeline execution failed: %v\n", err)
-		os.Exit(1)
+		return exitCode(err)
 	}
 
 	// Print final summary
 	if !config.quiet {
-		printFinalSummary(pipe)
+		printFinalSummary(pipe, config.slowRecords)
+	}
+
+	return 0
+}
+
+// exitCode is the exit status for the error a run ended with. An interrupted
+// run succeeds, since its summary is still printed.
+func exitCode(err error) int {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return 0
 	}
+	return 1
 }
 
 // Config holds command line configuration
@@ -91,6 +274,10 @@ type Config struct {
 	inputFiles     []string
 	hasHeader      bool
 	validateHeader bool
+	delimiter      string
+	delim          rune
+	follow         bool
+	followPoll     time.Duration
 
 	// Processing
 	workers    int
@@ -101,55 +288,162 @@ type Config struct {
 	errorThreshold float64
 	abortOnError   bool
 
+	// Referential integrity
+	foreignKeys  stringList
+	refDelimiter string
+	refDelim     rune
+
+	// Aggregation
+	groupBy    string
+	aggregates string
+
+	// Enrichment
+	enrichRefs    stringList
+	enrichMissing string
+	enrichDefault string
+	enrichReplace bool
+	enrichIndex   string
+
+	// Checkpointing
+	checkpointFile     string
+	checkpointInterval time.Duration
+	resume             bool
+
+	// Incremental runs
+	incremental  bool
+	manifestFile string
+
 	// Output
-	outputFile   string
-	showProgress bool
-	verbose      bool
-	quiet        bool
+	outputFile     string
+	runReport      string
+	showProgress   bool
+	progressFormat string
+	progressOutput string
+	prescan        bool
+	dashboard      bool
+	verbose        bool
+	quiet          bool
+	slowRecords    int
+
+	// Monitoring
+	metricsAddr string
+	debugAddr   string
+	traceFile   string
+
+	// Logging
+	logFormat string
+	logLevel  string
 
 	// Meta
+	configFile  string
+	dryRun      bool
 	showVersion bool
+
+	// sources records where each option's value came from: flag, env, file or default
+	sources map[string]string
+
+	// unknownEnv lists CSVPROC_ variables that set no option and are ignored
+	unknownEnv []string
 }
 
-// parseFlags parses command line flags
-func parseFlags() *Config {
+// parseFlags resolves the configuration from args, CSVPROC_* environment
+// variables and the -config file, in that order of precedence
+func parseFlags(args []string) (*Config, *flag.FlagSet, error) {
 	config := &Config{}
+	fs := flag.NewFlagSet("processor", flag.ExitOnError)
 
 	// Input options
-	flag.BoolVar(&config.hasHeader, "header", true, "CSV files have header row")
-	flag.BoolVar(&config.validateHeader, "validate-header", true, "Validate header consistency across files")
+	fs.BoolVar(&config.hasHeader, "header", true, "CSV files have header row")
+	fs.BoolVar(&config.validateHeader, "validate-header", true, "Validate header consistency across files")
+	fs.StringVar(&config.delimiter, "delimiter", ",", "Field delimiter of the input and output files (a character or tab)")
+	fs.BoolVar(&config.follow, "follow", false, "Keep reading files as rows are appended, until interrupted")
+	fs.DurationVar(&config.followPoll, "follow-poll", 500*time.Millisecond, "How often to check followed files for new rows")
 
 	// Processing options
-	flag.IntVar(&config.workers, "workers", runtime.NumCPU(), "Number of worker goroutines")
-	flag.IntVar(&config.bufferSize, "buffer", 100, "Channel buffer size")
+	fs.IntVar(&config.workers, "workers", runtime.NumCPU(), "Number of worker goroutines")
+	fs.IntVar(&config.bufferSize, "buffer", 100, "Channel buffer size")
 
 	// Error handling
-	flag.IntVar(&config.maxErrors, "max-errors", 0, "Maximum errors to collect (0 = unlimited)")
-	flag.Float64Var(&config.errorThreshold, "error-threshold", 0.0, "Error rate threshold (0.0-1.0, 0 = disabled)")
-	flag.BoolVar(&config.abortOnError, "abort-on-error", false, "Abort when error threshold is exceeded")
+	fs.IntVar(&config.maxErrors, "max-errors", 0, "Maximum errors to collect (0 = unlimited)")
+	fs.Float64Var(&config.errorThreshold, "error-threshold", 0.0, "Error rate threshold (0.0-1.0, 0 = disabled)")
+	fs.BoolVar(&config.abortOnError, "abort-on-error", false, "Abort when error threshold is exceeded")
+
+	// Referential integrity
+	fs.Var(&config.foreignKeys, "ref", "Require COLUMN values to exist in FILE[:REFCOLUMN], as COLUMN=FILE[:REFCOLUMN] (repeatable)")
+	fs.StringVar(&config.refDelimiter, "ref-delimiter", "", "Field delimiter of -ref and -enrich reference files (default: -delimiter)")
+
+	// Aggregation
+	fs.StringVar(&config.groupBy, "group-by", "", "Comma-separated columns to group by")
+	fs.StringVar(&config.aggregates, "agg", "", "Aggregates per group, e.g. sum(amount),count(),avg(fee)")
+
+	// Enrichment
+	fs.Var(&config.enrichRefs, "enrich", "Reference join FILE:LOOKUP[=KEY][:COL,...] (repeatable)")
+	fs.StringVar(&config.enrichMissing, "enrich-missing", "fail", "Missing key policy: fail, skip or default")
+	fs.StringVar(&config.enrichDefault, "enrich-default", "", "Value for enrichment columns when -enrich-missing=default")
+	fs.BoolVar(&config.enrichReplace, "enrich-replace", false, "Replace existing columns instead of appending")
+	fs.StringVar(&config.enrichIndex, "enrich-index", "memory", "Reference index: memory or disk")
+
+	// Checkpointing
+	fs.StringVar(&config.checkpointFile, "checkpoint", "", "Save completed lines to this file for -resume")
+	fs.DurationVar(&config.checkpointInterval, "checkpoint-interval", 5*time.Second, "How often to save the checkpoint")
+	fs.BoolVar(&config.resume, "resume", false, "Skip records completed in the checkpoint and append to -output")
+
+	// Incremental runs
+	fs.BoolVar(&config.incremental, "incremental", false, "Skip files the manifest lists as processed successfully")
+	fs.StringVar(&config.manifestFile, "manifest", ".csvproc-manifest.json", "Manifest file for -incremental")
 
 	// Output options
-	flag.StringVar(&config.outputFile, "output", "", "Output file path (default: none)")
-	flag.BoolVar(&config.showProgress, "progress", true, "Show progress updates")
-	flag.BoolVar(&config.verbose, "verbose", false, "Verbose output")
-	flag.BoolVar(&config.quiet, "quiet", false, "Suppress all output except errors")
+	fs.StringVar(&config.outputFile, "output", "", "Output file path (default: none)")
+	fs.StringVar(&config.runReport, "run-report", "", "Write a JSON report of the run with input and output checksums to this file")
+	fs.BoolVar(&config.showProgress, "progress", true, "Show progress updates")
+	fs.StringVar(&config.progressFormat, "progress-format", "text", "Progress format: text or json (one event per line)")
+	fs.StringVar(&config.progressOutput, "progress-output", "-", "Write progress to a file or unix:SOCKET instead of stdout")
+	fs.BoolVar(&config.prescan, "prescan", false, "Count records before processing for exact percent complete and ETA")
+	fs.BoolVar(&config.dashboard, "dashboard", true, "Show a live dashboard in place of the progress line when stdout is a terminal")
+	fs.BoolVar(&config.verbose, "verbose", false, "Verbose output")
+	fs.BoolVar(&config.quiet, "quiet", false, "Suppress all output except errors")
+	fs.IntVar(&config.slowRecords, "slow", 0, "List the N slowest records in the summary")
+
+	// Monitoring
+	fs.StringVar(&config.metricsAddr, "metrics-addr", "", "Serve Prometheus metrics at /metrics on this address, e.g. :9090")
+	fs.StringVar(&config.debugAddr, "debug-addr", "", "Serve pprof and JSON diagnostics under /debug/ on this address, e.g. localhost:6060")
+	fs.StringVar(&config.traceFile, "trace", "", "Write a Chrome trace of the run to this file")
+
+	// Logging
+	fs.StringVar(&config.logFormat, "log-format", "text", "Log format on stderr: text or json")
+	fs.StringVar(&config.logLevel, "log-level", "info", "Minimum log level: debug, info, warn or error")
 
 	// Meta
-	flag.BoolVar(&config.showVersion, "version", false, "Show version information")
+	fs.StringVar(&config.configFile, "config", "", "Read options from a JSON, YAML or TOML file")
+	fs.BoolVar(&config.dryRun, "dry-run", false, "Check inputs and print the plan without processing anything")
+	fs.BoolVar(&config.showVersion, "version", false, "Show version information")
 
-	flag.Usage = printUsage
-	flag.Parse()
+	fs.Usage = printUsage
+	fs.Parse(args)
 
-	// Remaining arguments are input files
-	config.inputFiles = flag.Args()
+	sources, inputs, err := applyConfig(fs, config.configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.sources = sources
+	config.inputFiles = inputs
+	config.unknownEnv = unknownEnv(fs)
 
-	// Quiet mode overrides other output options
+	// Quiet mode overrides other output options; a JSON stream sent to a file
+	// or socket is not terminal output, so it is kept
 	if config.quiet {
-		config.showProgress = false
+		if config.progressFormat != "json" || progressToStdout(config.progressOutput) {
+			config.showProgress = false
+		}
 		config.verbose = false
 	}
 
-	return config
+	// JSON events on stdout replace the startup info and summary text
+	if config.showProgress && config.progressFormat == "json" && progressToStdout(config.progressOutput) {
+		config.quiet = true
+	}
+
+	return config, fs, nil
 }
 
 // validate validates the configuration
@@ -162,32 +456,235 @@ func (c *Config) validate() error {
 		return fmt.Errorf("workers must be at least 1")
 	}
 
+	delim, err := parseDelimiter(c.delimiter)
+	if err != nil {
+		return err
+	}
+	c.delim = delim
+
+	// Reference files are read like the inputs unless told otherwise
+	c.refDelim = delim
+	if c.refDelimiter != "" {
+		if c.refDelim, err = parseDelimiter(c.refDelimiter); err != nil {
+			return fmt.Errorf("-ref-delimiter: %w", err)
+		}
+	}
+
 	if c.errorThreshold < 0 || c.errorThreshold > 1 {
 		return fmt.Errorf("error threshold must be between 0.0 and 1.0")
 	}
 
+	if c.progressFormat != "text" && c.progressFormat != "json" {
+		return fmt.Errorf("progress format must be text or json")
+	}
+
+	if _, err := newLogger(io.Discard, c.logFormat, c.logLevel); err != nil {
+		return err
+	}
+
+	if len(c.enrichRefs) > 0 && !c.hasHeader {
+		return fmt.Errorf("enrichment requires CSV files with a header row")
+	}
+
+	if c.groupBy != "" && c.aggregates == "" {
+		return fmt.Errorf("-group-by requires -agg")
+	}
+
+	if c.aggregates != "" && !c.hasHeader {
+		return fmt.Errorf("aggregation requires CSV files with a header row")
+	}
+
+	if c.aggregates != "" && len(c.enrichRefs) > 0 {
+		return fmt.Errorf("-agg cannot be combined with -enrich")
+	}
+
+	if c.resume && c.checkpointFile == "" {
+		return fmt.Errorf("-resume requires -checkpoint")
+	}
+
+	if c.checkpointFile != "" && c.aggregates != "" {
+		return fmt.Errorf("-checkpoint cannot be combined with -agg")
+	}
+
+	if c.follow && (c.aggregates != "" || c.checkpointFile != "" || c.incremental) {
+		return fmt.Errorf("-follow cannot be combined with -agg, -checkpoint or -incremental")
+	}
+
+	if c.incremental && c.aggregates != "" {
+		return fmt.Errorf("-incremental cannot be combined with -agg")
+	}
+
+	if c.incremental && c.checkpointFile != "" {
+		return fmt.Errorf("-incremental cannot be combined with -checkpoint")
+	}
+
+	// Each file of an incremental run is a run of its own, recorded in the manifest
+	if c.incremental && c.runReport != "" {
+		return fmt.Errorf("-incremental cannot be combined with -run-report")
+	}
+
+	if len(c.foreignKeys) > 0 && !c.hasHeader {
+		return fmt.Errorf("referential integrity checks require CSV files with a header row")
+	}
+
+	return nil
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+// String implements flag.Value
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set implements flag.Value
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
 	return nil
 }
 
+// buildProcessor creates the record processor selected by the flags
+func buildProcessor(ctx context.Context, config *Config) (csvproc.Processor, error) {
+	if config.aggregates != "" {
+		aggs, err := csvproc.ParseAggregates(config.aggregates)
+		if err != nil {
+			return nil, err
+		}
+
+		var groupBy []string
+		if config.groupBy != "" {
+			groupBy = strings.Split(config.groupBy, ",")
+		}
+
+		return csvproc.NewAggregateProcessor(csvproc.AggregateConfig{
+			GroupBy:    groupBy,
+			Aggregates: aggs,
+			Workers:    config.workers,
+		})
+	}
+
+	if len(config.enrichRefs) == 0 {
+		return csvproc.NewDefaultProcessor(), nil
+	}
+
+	enrichConfig := csvproc.EnrichConfig{}
+
+	for _, spec := range config.enrichRefs {
+		ref, err := parseReference(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		ref.Replace = config.enrichReplace
+		ref.Missing = csvproc.MissingKeyPolicy(config.enrichMissing)
+		ref.Index = csvproc.IndexMode(config.enrichIndex)
+		ref.Delimiter = config.refDelim
+		ref.Default = config.enrichDefault
+
+		enrichConfig.References = append(enrichConfig.References, ref)
+	}
+
+	return csvproc.NewEnrichProcessor(ctx, enrichConfig)
+}
+
+// parseForeignKey parses a -ref spec of the form COLUMN=FILE[:REFCOLUMN]
+func parseForeignKey(spec string) (csvproc.ForeignKey, error) {
+	column, target, found := strings.Cut(spec, "=")
+	if !found || column == "" || target == "" {
+		return csvproc.ForeignKey{}, fmt.Errorf("invalid -ref spec %q (want COLUMN=FILE[:REFCOLUMN])", spec)
+	}
+
+	fk := csvproc.ForeignKey{Column: column, RefFile: target}
+	if file, refColumn, ok := strings.Cut(target, ":"); ok {
+		fk.RefFile = file
+		fk.RefColumn = refColumn
+	}
+
+	return fk, nil
+}
+
+// parseReference parses an -enrich spec of the form FILE:LOOKUP[=KEY][:COL,...]
+func parseReference(spec string) (csvproc.Reference, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return csvproc.Reference{}, fmt.Errorf("invalid -enrich spec %q (want FILE:LOOKUP[=KEY][:COL,...])", spec)
+	}
+
+	ref := csvproc.Reference{File: parts[0]}
+
+	lookup, key, found := strings.Cut(parts[1], "=")
+	ref.LookupColumn = lookup
+	ref.KeyColumn = lookup
+	if found {
+		ref.KeyColumn = key
+	}
+
+	if len(parts) == 3 && parts[2] != "" {
+		ref.Columns = strings.Split(parts[2], ",")
+	}
+
+	return ref, nil
+}
+
 // printUsage prints usage information
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `CSV Processor - Concurrent CSV file processor
 
 Usage:
-  processor [options] <file1.csv> [file2.csv ...]
+  processor [run] [options] <file1.csv> [file2.csv ...]
+  processor validate [options] <file1.csv> [file2.csv ...]
+  processor count [options] <file1.csv> [file2.csv ...]
+  processor head [-n N] [options] <file1.csv> [file2.csv ...]
+  processor convert [-to csv|tsv|json] [options] <file1.csv> [file2.csv ...]
+  processor sort -by KEYS [options] <file1.csv> [file2.csv ...]
+  processor dedup [-key COLS] [-keep first|last] [options] <file1.csv> [file2.csv ...]
+  processor diff -key COLS [-format csv|json] [options] <old.csv> <new.csv>
+  processor watch [options] <DIR>
+  processor config print [options] [file1.csv ...]
 
 Options:
   -header             CSV files have header row (default: true)
   -validate-header    Validate header consistency (default: true)
+  -delimiter C        Field delimiter of the inputs and output, a character or tab (default: ,)
+  -follow             Keep reading files as rows are appended, until interrupted (default: false)
+  -follow-poll D      How often to check followed files for new rows (default: 500ms)
   -workers N          Number of worker goroutines (default: NumCPU)
   -buffer N           Channel buffer size (default: 100)
   -max-errors N       Maximum errors to collect (default: 0 = unlimited)
   -error-threshold F  Error rate threshold 0.0-1.0 (default: 0.0 = disabled)
   -abort-on-error     Abort when error threshold exceeded (default: false)
+  -group-by COLS      Columns to group by for -agg
+  -agg AGGS           Aggregates per group: sum(c), count(), avg(c), min(c), max(c)
+  -ref SPEC           Require COLUMN values to exist in FILE, COLUMN=FILE[:REFCOLUMN] (repeatable)
+  -ref-delimiter C    Field delimiter of -ref and -enrich reference files (default: -delimiter)
+  -enrich SPEC        Join a reference CSV, FILE:LOOKUP[=KEY][:COL,...] (repeatable)
+  -enrich-missing P   Missing key policy: fail, skip or default (default: fail)
+  -enrich-default V   Value used when -enrich-missing=default (default: "")
+  -enrich-replace     Replace existing columns instead of appending (default: false)
+  -enrich-index M     Reference index: memory or disk (default: memory)
+  -checkpoint FILE    Save completed lines to FILE for -resume (default: none)
+  -checkpoint-interval D  How often to save the checkpoint (default: 5s)
+  -resume             Skip records completed in the checkpoint, append to -output (default: false)
+  -incremental        Skip files the manifest lists as processed successfully (default: false)
+  -manifest FILE      Manifest for -incremental (default: .csvproc-manifest.json)
   -output FILE        Output file path (default: none)
+  -run-report FILE    Write a JSON report of the run with checksums to FILE (default: none)
   -progress           Show progress updates (default: true)
+  -progress-format F  Progress format: text or json, one event per line (default: text)
+  -progress-output D  Write progress to a file or unix:SOCKET instead of stdout (default: -)
+  -prescan            Count records before processing for exact percent and ETA (default: false)
+  -dashboard          Show a live dashboard when stdout is a terminal (default: true)
   -verbose            Verbose output (default: false)
   -quiet              Suppress all output except errors (default: false)
+  -slow N             List the N slowest records in the summary (default: 0)
+  -metrics-addr ADDR  Serve Prometheus metrics at /metrics on ADDR, e.g. :9090 (default: none)
+  -debug-addr ADDR    Serve pprof and diagnostics under /debug/ on ADDR (default: none)
+  -trace FILE         Write a Chrome trace of the run to FILE (default: none)
+  -log-format F       Log format on stderr: text or json (default: text)
+  -log-level L        Minimum log level: debug, info, warn or error (default: info)
+  -config FILE        Read options of the main command from a JSON, YAML or TOML file (default: none)
+  -dry-run            Check inputs and print the plan without processing anything (default: false)
   -version            Show version information
 
 Examples:
@@ -203,6 +700,62 @@ Examples:
   # Quiet mode with output file
   processor -quiet -output results.csv data.csv
 
+  # Options from a job file, with one overridden by the environment
+  CSVPROC_WORKERS=16 processor -config job.yaml
+
+  # Show the effective configuration and where each value came from
+  processor config print -config job.yaml
+
+  # Check a large job before launching it
+  processor -dry-run -config job.yaml
+
+  # Stream JSON progress events to a monitoring socket
+  processor -quiet -progress-format json -progress-output unix:/run/ui.sock data.csv
+
+  # Checkpoint a long job, then pick up where it stopped after an interruption
+  processor -checkpoint job.ckpt -output out.csv big.csv
+  processor -checkpoint job.ckpt -resume -output out.csv big.csv
+
+  # Process rows as they are appended to a live file
+  processor -follow -output processed.csv /var/log/app/events.csv
+
+  # Hourly cron job: only process files that are new or changed
+  processor -incremental -manifest /var/lib/csvproc/manifest.json /data/drop/*.csv
+
+  # Report orders whose customer_id is missing from customers.csv
+  processor -ref customer_id=customers.csv:id orders.csv
+
+  # Add merchant names from a reference table
+  processor -enrich merchants.csv:merchant_id=id:name -output out.csv tx.csv
+
+  # Totals per merchant per day
+  processor -group-by merchant,date -agg "sum(amount),count(),avg(fee)" tx.csv
+
+  # Check headers and field counts in CI; the exit status reports the result
+  processor validate -quiet data/*.csv
+
+  # Row counts per file, and the first 5 records as a table
+  processor count data/*.csv
+  processor head -n 5 data.csv
+
+  # Semicolon-separated export to JSON Lines
+  processor convert -delimiter ";" -to json -output data.jsonl export.csv
+
+  # Sort by amount (largest first), then date, within a 512MB budget
+  processor sort -by amount:num:desc,date -memory 512MB -output sorted.csv data.csv
+
+  # Keep the latest row per customer across daily files
+  processor dedup -key customer_id -keep last -output customers.csv day1.csv day2.csv
+
+  # Rows added, removed and changed between two snapshots, as JSON Lines
+  processor diff -key id -format json yesterday.csv today.csv
+
+  # Process files as they land in a drop directory
+  processor watch -error-threshold 0.05 -output-dir /data/out /data/drop
+
+  # Expose metrics for Prometheus while following a live file
+  processor -follow -metrics-addr :9090 -output processed.csv events.csv
+
 For more information, visit: https://github.com/zuhrulumam/csv_processor
 `)
 }
@@ -238,8 +791,10 @@ func printStartupInfo(config *Config) {
 	fmt.Println()
 }
 
-// printFinalSummary prints final processing summary
-func printFinalSummary(pipe *pipeline.Pipeline) {
+// printFinalSummary prints final processing summary, with latency
+// percentiles per processor, a table of input files when there are several,
+// and up to slow of the slowest records
+func printFinalSummary(pipe *csvproc.Pipeline, slow int) {
 	summary := pipe.Summary()
 
 	fmt.Println()
@@ -251,5 +806,43 @@ func printFinalSummary(pipe *pipeline.Pipeline) {
 	fmt.Printf("Failed:           %d (%.1f%%)\n", summary.FailedCount(), summary.FailureRate())
 	fmt.Printf("Duration:         %s\n", summary.Duration().Round(time.Millisecond))
 	fmt.Printf("Throughput:       %.0f records/sec\n", summary.Throughput())
+
+	latency := summary.Latency()
+	for _, name := range latency.Names() {
+		fmt.Printf("Latency (%s): %s\n", name, latency.Get(name))
+	}
+
+	if files := pipe.Files(); len(files) > 1 {
+		fmt.Println("----------------------------------------")
+		printFileTable(files)
+	}
+
+	if records := summary.SlowRecords(); slow > 0 && len(records) > 0 {
+		if len(records) > slow {
+			records = records[:slow]
+		}
+
+		fmt.Println("----------------------------------------")
+		fmt.Println("Slowest Records:")
+		for _, record := range records {
+			fmt.Printf("  %s:%d  %s  %s  %s\n", record.FileName, record.LineNumber,
+				csvproc.RoundLatency(record.Duration), record.Processor, strings.ToLower(string(record.Status)))
+		}
+	}
+
 	fmt.Println("========================================")
 }
+
+// printFileTable prints the records, failures and speed of each input file
+func printFileTable(files []csvproc.FileStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "File\tRecords\tFailed\tError Rate\tDuration\tThroughput")
+	for _, file := range files {
+		duration := time.Duration(file.Duration * float64(time.Second))
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t%s\t%.0f rec/s\n",
+			file.File, file.Processed, file.Failed, file.ErrorRate(), csvproc.RoundLatency(duration), file.Throughput)
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+)
+
+// startMetricsServer serves registry at /metrics on addr and returns a
+// function that shuts the server down
+func startMetricsServer(addr string, registry *csvproc.MetricsRegistry) (func(), error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+
+	return startServer(addr, mux, "Metrics")
+}
+
+// startServer serves handler on addr and returns a function that shuts the
+// server down. name labels errors from the server.
+func startServer(addr string, handler http.Handler, name string) (func(), error) {
+	// Listen first so a bad address or a port in use is reported before the run
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "%s server error: %v\n", name, err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// progressToStdout reports whether -progress-output names standard output
+func progressToStdout(dest string) bool {
+	return dest == "" || dest == "-"
+}
+
+// openProgressOutput opens the -progress-output destination: "-" for stdout,
+// unix:PATH to connect to a listening Unix socket, or a file to create. The
+// returned function closes it.
+func openProgressOutput(dest string) (io.Writer, func(), error) {
+	if progressToStdout(dest) {
+		return os.Stdout, func() {}, nil
+	}
+
+	if path, ok := strings.CutPrefix(dest, "unix:"); ok {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("connect to progress socket: %w", err)
+		}
+		return conn, func() { conn.Close() }, nil
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create progress output: %w", err)
+	}
+	return file, func() { file.Close() }, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/sorter"
+)
+
+// runSort implements the sort subcommand
+func runSort(args []string) int {
+	fs := flag.NewFlagSet("sort", flag.ExitOnError)
+
+	by := fs.String("by", "", "Sort keys, e.g. amount:num:desc,merchant (required)")
+	output := fs.String("output", "", "Output file path (default: stdout)")
+	hasHeader := fs.Bool("header", true, "CSV files have header row")
+	delimiter := fs.String("delimiter", ",", "Field delimiter of the input and output (a character or tab)")
+	memory := fs.String("memory", "64MB", "Memory budget for in-memory runs (e.g. 512MB, 2GB)")
+	workers := fs.Int("workers", runtime.NumCPU(), "Number of workers building sorted runs")
+	tempDir := fs.String("tmp", "", "Directory for temporary run files (default: system temp)")
+	quiet := fs.Bool("quiet", false, "Suppress all output except errors")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor sort -by KEYS [options] <file1.csv> [file2.csv ...]
+
+Sorts CSV files larger than memory with an external merge sort.
+
+Keys are comma-separated columns with optional modifiers:
+  :asc / :desc          Sort direction (default: asc)
+  :string / :num / :time  Comparison type (default: string)
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	keys, err := sorter.ParseKeys(*by)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	delim, err := parseDelimiter(*delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	budget, err := parseByteSize(*memory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: no input files specified\n")
+		return 1
+	}
+
+	s, err := sorter.NewSorter(sorter.Config{
+		Keys:         keys,
+		HasHeader:    *hasHeader,
+		Delimiter:    delim,
+		MemoryBudget: budget,
+		Workers:      *workers,
+		TempDir:      *tempDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create sorter: %v\n", err)
+		return 1
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	// Cancel on SIGINT/SIGTERM so the run files are removed
+	ctx, stop := signalContext()
+	defer stop()
+
+	start := time.Now()
+
+	stats, err := s.Sort(ctx, files, out)
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Sort interrupted\n")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Sort failed: %v\n", err)
+		return 1
+	}
+
+	if !*quiet && *output != "" {
+		fmt.Printf("Sorted %d records in %s (%d runs)\n", stats.Records, time.Since(start).Round(time.Millisecond), stats.Runs)
+	}
+
+	return 0
+}
+
+// parseByteSize parses sizes such as 512MB, 2G or 1048576
+func parseByteSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30}, {"G", 1 << 30},
+		{"MB", 1 << 20}, {"M", 1 << 20},
+		{"KB", 1 << 10}, {"K", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return n * multiplier, nil
+}
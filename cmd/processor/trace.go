@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+)
+
+// writeTrace writes the spans recorded during the run to file, for loading
+// in chrome://tracing or Perfetto. The file is created before the run, so a
+// bad path is reported before any work is done.
+func writeTrace(file *os.File, tracer *csvproc.Tracer) {
+	defer file.Close()
+
+	if err := tracer.Write(file); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write trace: %v\n", err)
+		return
+	}
+
+	recorded, dropped := tracer.Events()
+	slog.Info("trace written", "file", file.Name(), "events", recorded, "dropped", dropped)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+)
+
+// fileCheck is what validate found in one file
+type fileCheck struct {
+	records  int
+	problems []problem
+
+	// fields is the expected field count: the header's, or the first record's
+	fields int
+
+	// header is the file's header, known once a record has been read
+	header []string
+}
+
+// problem is one validation failure; line is 0 for file-level problems
+type problem struct {
+	line int
+	err  error
+}
+
+// runValidate implements the validate subcommand
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+
+	hasHeader := fs.Bool("header", true, "CSV files have header row")
+	validateHeader := fs.Bool("validate-header", true, "Require the same header in every file")
+	delimiter := fs.String("delimiter", ",", "Field delimiter (a character or tab)")
+	maxProblems := fs.Int("max-problems", 10, "Problems to list per file (0 = all)")
+	quiet := fs.Bool("quiet", false, "Print nothing; the exit status reports the result")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor validate [options] <file1.csv> [file2.csv ...]
+
+Checks headers, header consistency across files, field counts and CSV syntax
+without processing records. Exits 0 when every file is valid, 1 when problems
+are found and 2 on usage errors.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "Configuration error: no input files specified\n")
+		return 2
+	}
+
+	delim, err := parseDelimiter(*delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	// One reader over all files; records are attributed to files by path, as
+	// files in different directories may share a name
+	checks := make(map[string]*fileCheck, len(files))
+	for _, file := range files {
+		checks[file] = &fileCheck{}
+	}
+
+	errs := readRecords(ctx, csvproc.ReaderConfig{
+		Files:          files,
+		HasHeader:      *hasHeader,
+		Delimiter:      delim,
+		VariableFields: true,
+	}, func(record *csvproc.Record) bool {
+		check := checks[record.Path]
+		check.records++
+
+		if check.fields == 0 {
+			check.header = record.Headers
+			check.fields = len(record.Headers)
+			if check.fields == 0 {
+				check.fields = len(record.Data)
+			}
+		}
+		if len(record.Data) != check.fields {
+			err := fmt.Errorf("field count mismatch: expected %d fields, got %d", check.fields, len(record.Data))
+			check.problems = append(check.problems, problem{line: record.LineNumber, err: err})
+		}
+		return true
+	})
+
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Validation interrupted\n")
+		return 2
+	}
+
+	unattributed := 0
+	for _, err := range errs {
+		var file string
+		if pe, ok := err.(*csvproc.ProcessingError); ok {
+			file = pe.FileName
+			err = pe.Err
+		}
+		if check, ok := checks[file]; ok {
+			check.problems = append(check.problems, problem{err: err})
+		} else {
+			unattributed++
+			if !*quiet {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+	}
+
+	// Headers are compared with the first file's, so the file that differs is reported
+	if *hasHeader && *validateHeader {
+		first := checks[files[0]].header
+		for _, file := range files[1:] {
+			check := checks[file]
+			if first != nil && check.header != nil && strings.Join(check.header, "\x00") != strings.Join(first, "\x00") {
+				err := fmt.Errorf("header %s does not match %s in %s", strings.Join(check.header, ","), strings.Join(first, ","), files[0])
+				check.problems = append(check.problems, problem{err: err})
+			}
+		}
+	}
+
+	invalid := 0
+	for _, file := range files {
+		check := checks[file]
+		if len(check.problems) > 0 {
+			invalid++
+		}
+		if !*quiet {
+			printFileCheck(file, check, *maxProblems)
+		}
+	}
+
+	if !*quiet && len(files) > 1 {
+		fmt.Printf("%d of %d files valid\n", len(files)-invalid, len(files))
+	}
+
+	if invalid > 0 || unattributed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printFileCheck prints the result for one file
+func printFileCheck(file string, check *fileCheck, maxProblems int) {
+	if len(check.problems) == 0 {
+		fmt.Printf("%s: ok (%d records)\n", file, check.records)
+		return
+	}
+
+	noun := "problems"
+	if len(check.problems) == 1 {
+		noun = "problem"
+	}
+	fmt.Printf("%s: %d %s (%d records read)\n", file, len(check.problems), noun, check.records)
+
+	sort.SliceStable(check.problems, func(i, j int) bool {
+		return check.problems[i].line < check.problems[j].line
+	})
+
+	for i, p := range check.problems {
+		if maxProblems > 0 && i == maxProblems {
+			fmt.Printf("  ... %d more\n", len(check.problems)-maxProblems)
+			break
+		}
+		if p.line > 0 {
+			fmt.Printf("  line %d: %v\n", p.line, p.err)
+		} else {
+			fmt.Printf("  %v\n", p.err)
+		}
+	}
+}
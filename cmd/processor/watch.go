@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+	"github.com/zuhrulumam/csv_processor/internal/manifest"
+	"github.com/zuhrulumam/csv_processor/internal/watcher"
+)
+
+// watchConfig holds watch subcommand configuration
+type watchConfig struct {
+	dir       string
+	doneDir   string
+	failedDir string
+	outputDir string
+
+	metricsAddr string
+
+	logFormat string
+	logLevel  string
+	delimiter string
+
+	pattern  string
+	poll     time.Duration
+	settle   time.Duration
+	template csvproc.Config
+}
+
+// batchStats summarizes one batch of files
+type batchStats struct {
+	files     int
+	done      int
+	failed    int
+	records   int
+	succeeded int
+	errored   int
+}
+
+// runWatch implements the watch subcommand
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+
+	config := watchConfig{}
+
+	fs.StringVar(&config.pattern, "pattern", "*.csv", "File name pattern to process")
+	fs.DurationVar(&config.poll, "poll", time.Second, "How often to list the directory")
+	fs.DurationVar(&config.settle, "settle", 2*time.Second, "How long a file must stop growing before it is processed")
+	fs.StringVar(&config.doneDir, "done-dir", "", "Where processed files are moved (default: DIR/done)")
+	fs.StringVar(&config.failedDir, "failed-dir", "", "Where failed files are moved (default: DIR/failed)")
+	fs.StringVar(&config.outputDir, "output-dir", "", "Write each file's output to this directory (default: none)")
+	fs.StringVar(&config.metricsAddr, "metrics-addr", "", "Serve Prometheus metrics at /metrics on this address, e.g. :9090")
+	fs.StringVar(&config.logFormat, "log-format", "text", "Activity log format on stdout: text or json")
+	fs.StringVar(&config.logLevel, "log-level", "info", "Minimum log level: debug, info, warn or error")
+
+	fs.BoolVar(&config.template.HasHeader, "header", true, "CSV files have header row")
+	fs.StringVar(&config.delimiter, "delimiter", ",", "Field delimiter of the input and output files (a character or tab)")
+	fs.IntVar(&config.template.Workers, "workers", runtime.NumCPU(), "Number of worker goroutines")
+	fs.IntVar(&config.template.BufferSize, "buffer", 100, "Channel buffer size")
+	fs.IntVar(&config.template.MaxErrors, "max-errors", 0, "Maximum errors to collect per file (0 = unlimited)")
+	fs.Float64Var(&config.template.ErrorThreshold, "error-threshold", 0.0, "Error rate above which a file fails (0.0-1.0, 0 = disabled)")
+	fs.BoolVar(&config.template.AbortOnError, "abort-on-error", false, "Stop processing a file once its error threshold is exceeded")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  processor watch [options] <DIR>
+
+Processes files as they arrive in DIR. A file is picked up once its size and
+modification time have not changed for -settle, run through the pipeline, and
+moved to the done or failed directory depending on the error threshold outcome.
+
+Options:
+`)
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Configuration error: expected exactly one directory\n")
+		return 1
+	}
+	config.dir = fs.Arg(0)
+
+	if config.template.ErrorThreshold < 0 || config.template.ErrorThreshold > 1 {
+		fmt.Fprintf(os.Stderr, "Configuration error: error threshold must be between 0.0 and 1.0\n")
+		return 1
+	}
+
+	delim, err := parseDelimiter(config.delimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	config.template.Delimiter = delim
+
+	// The activity log goes to stdout; each file's pipeline logs with its own run ID
+	logger, err := newLogger(os.Stdout, config.logFormat, config.logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	slog.SetDefault(logger)
+	config.template.Logger = logger
+
+	if config.doneDir == "" {
+		config.doneDir = filepath.Join(config.dir, "done")
+	}
+	if config.failedDir == "" {
+		config.failedDir = filepath.Join(config.dir, "failed")
+	}
+
+	for _, dir := range []string{config.doneDir, config.failedDir, config.outputDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create directory: %v\n", err)
+			return 1
+		}
+	}
+
+	w, err := watcher.NewWatcher(watcher.Config{
+		Dir:          config.dir,
+		Pattern:      config.pattern,
+		PollInterval: config.poll,
+		SettleTime:   config.settle,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to watch directory: %v\n", err)
+		return 1
+	}
+
+	config.template.ValidateHeader = config.template.HasHeader
+	config.template.Processor = csvproc.NewDefaultProcessor()
+
+	// Every file's pipeline reports to the same registry
+	if config.metricsAddr != "" {
+		config.template.Metrics = csvproc.NewMetricsRegistry()
+
+		shutdown, err := startMetricsServer(config.metricsAddr, config.template.Metrics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start metrics server: %v\n", err)
+			return 1
+		}
+		defer shutdown()
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	logger.Info("watching directory", "dir", config.dir, "pattern", config.pattern,
+		"done_dir", config.doneDir, "failed_dir", config.failedDir)
+
+	batchCh, errCh := w.Watch(ctx)
+
+	// The watcher reports a file once, so processed files that could not be
+	// moved away are tracked here and their move retried every poll
+	unmoved := make(map[string]string)
+	retry := time.NewTicker(config.poll)
+	defer retry.Stop()
+
+	for {
+		select {
+		case batch, ok := <-batchCh:
+			if !ok {
+				for file := range unmoved {
+					logger.Warn("file left in place after failed move", "file", filepath.Base(file))
+				}
+				logger.Info("stopped watching", "dir", config.dir)
+				return 0
+			}
+			processBatch(ctx, config, batch, unmoved, logger)
+
+		case <-retry.C:
+			retryMoves(unmoved, logger)
+
+		case err, ok := <-errCh:
+			if ok {
+				logger.Error("watch failed", "error", err.Error())
+			}
+		}
+	}
+}
+
+// processBatch runs each file of a batch through its own pipeline and moves it
+// to the done or failed directory. Files that cannot be moved are added to
+// unmoved, keyed by path, with their target directory.
+func processBatch(ctx context.Context, config watchConfig, batch []string, unmoved map[string]string, logger *slog.Logger) {
+	stats := batchStats{files: len(batch)}
+
+	for _, file := range batch {
+		// Files not yet started stay in place and are picked up after a restart
+		if ctx.Err() != nil {
+			break
+		}
+
+		// A file changed after a failed move is processed again
+		delete(unmoved, file)
+
+		pipe, status, reason := processWatchedFile(ctx, config, file)
+		if ctx.Err() != nil {
+			logger.Warn("interrupted, file left in place", "file", filepath.Base(file))
+			break
+		}
+
+		if pipe != nil {
+			summary := pipe.Summary()
+			stats.records += summary.TotalRecords()
+			stats.succeeded += summary.SuccessCount()
+			stats.errored += pipe.Errors().Count()
+		}
+
+		target := config.doneDir
+		if status == manifest.StatusFailed {
+			target = config.failedDir
+			stats.failed++
+			logger.Error("file failed", "file", filepath.Base(file), "reason", reason)
+		} else {
+			stats.done++
+		}
+
+		dest, err := moveFile(file, target)
+		if err != nil {
+			logger.Error("move failed, retrying every poll", "file", filepath.Base(file), "error", err.Error())
+			unmoved[file] = target
+			continue
+		}
+		logger.Info("moved file", "file", filepath.Base(file), "dest", dest)
+	}
+
+	logger.Info("batch complete", "files", stats.files, "done", stats.done, "failed", stats.failed,
+		"records", stats.records, "successful", stats.succeeded, "errors", stats.errored)
+}
+
+// retryMoves retries moving the files in unmoved, forgetting those that were
+// moved or removed
+func retryMoves(unmoved map[string]string, logger *slog.Logger) {
+	for file, target := range unmoved {
+		dest, err := moveFile(file, target)
+		if err != nil {
+			if _, statErr := os.Stat(file); os.IsNotExist(statErr) {
+				logger.Warn("unmoved file disappeared", "file", filepath.Base(file))
+				delete(unmoved, file)
+			}
+			continue
+		}
+		logger.Info("moved file", "file", filepath.Base(file), "dest", dest)
+		delete(unmoved, file)
+	}
+}
+
+// processWatchedFile runs the pipeline over a single file and decides its outcome
+func processWatchedFile(ctx context.Context, config watchConfig, file string) (*csvproc.Pipeline, manifest.Status, string) {
+	pipelineConfig := config.template
+	pipelineConfig.Files = []string{file}
+
+	if config.outputDir != "" {
+		out, err := os.Create(filepath.Join(config.outputDir, filepath.Base(file)))
+		if err != nil {
+			return nil, manifest.StatusFailed, fmt.Sprintf("create output: %v", err)
+		}
+		defer out.Close()
+		pipelineConfig.OutputWriter = out
+	}
+
+	pipe, err := csvproc.NewPipeline(pipelineConfig)
+	if err != nil {
+		return nil, manifest.StatusFailed, err.Error()
+	}
+
+	status, reason := fileOutcome(pipe, pipe.Run(ctx))
+	return pipe, status, reason
+}
+
+// moveFile moves file into dir, adding a timestamp if the name is taken
+func moveFile(file, dir string) (string, error) {
+	base := filepath.Base(file)
+	dest := filepath.Join(dir, base)
+
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(base)
+		stamp := time.Now().Format("20060102T150405.000000000")
+		dest = filepath.Join(dir, strings.TrimSuffix(base, ext)+"-"+stamp+ext)
+	}
+
+	if err := os.Rename(file, dest); err != nil {
+		return "", err
+	}
+
+	return dest, nil
+}
@@ -0,0 +1,127 @@
+package csvproc
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/pipeline"
+)
+
+// Config holds pipeline configuration. It is copied into the pipeline's own
+// configuration by NewPipeline, so fields can be added here without exposing
+// how the pipeline is put together.
+type Config struct {
+	// Files are the input CSV files, plain or gzip-compressed
+	Files []string
+
+	// HasHeader indicates the files have a header row; ValidateHeader
+	// requires every file to have the same one. Delimiter separates the
+	// fields of the input files and the output (default: ',').
+	HasHeader      bool
+	ValidateHeader bool
+	Delimiter      rune
+
+	// Follow keeps reading input files as rows are appended, like tail -F,
+	// until the run is stopped. FollowPoll is how often to check for new data.
+	Follow     bool
+	FollowPoll time.Duration
+
+	// Workers is the number of concurrent workers (0 = NumCPU), each calling
+	// Processor (default: NewDefaultProcessor()). BufferSize is the size of
+	// the record and result queues.
+	Workers    int
+	Processor  Processor
+	BufferSize int
+
+	// ForeignKeys are referential integrity checks, resolved before the main pass
+	ForeignKeys []ForeignKey
+
+	// MaxErrors limits the errors collected (0 = unlimited). With
+	// AbortOnError, the run stops once the error rate exceeds ErrorThreshold.
+	MaxErrors      int
+	ErrorThreshold float64
+	AbortOnError   bool
+
+	// Progress tracking. ProgressFormat is ProgressText (default),
+	// ProgressJSON or ProgressDashboard, written to ProgressWriter (default:
+	// os.Stdout).
+	ShowProgress   bool
+	VerboseOutput  bool
+	ProgressFormat string
+	ProgressWriter io.Writer
+
+	// Prescan counts the records of every input before processing, so
+	// progress shows exact percent complete and ETA
+	Prescan bool
+
+	// SlowRecords is how many of the slowest records the summary keeps
+	SlowRecords int
+
+	// OutputWriter receives the output rows. Checkpointing needs it to be an
+	// *os.File, and the run report has its size and checksum only then.
+	OutputWriter io.Writer
+
+	// Checkpointing: completed lines are saved to CheckpointFile every
+	// CheckpointInterval (default 5s) and at the end of the run. With Resume,
+	// completed records are skipped and the output is truncated to the size
+	// recorded in the checkpoint, then appended to.
+	CheckpointFile     string
+	CheckpointInterval time.Duration
+	Resume             bool
+
+	// Metrics, if set, receives record, error, queue and latency metrics
+	Metrics *MetricsRegistry
+
+	// Logger receives diagnostics and the error report at the end of the
+	// run, each line tagged with RunID (default: discard). RunID is generated
+	// when empty.
+	Logger *slog.Logger
+	RunID  string
+
+	// RunReport, if set, is where a RunReport is written as JSON at the end
+	// of the run, recording ReportConfig as the effective configuration.
+	// ExitCode maps the error Run returns to the exit status it records.
+	RunReport    string
+	ReportConfig any
+	ExitCode     func(runErr error) int
+
+	// Tracer, if set, records spans of the run for writing as a Chrome trace
+	Tracer *Tracer
+}
+
+// pipelineConfig returns the pipeline's configuration for c
+func (c Config) pipelineConfig() pipeline.Config {
+	return pipeline.Config{
+		Files:              c.Files,
+		HasHeader:          c.HasHeader,
+		ValidateHeader:     c.ValidateHeader,
+		Delimiter:          c.Delimiter,
+		Follow:             c.Follow,
+		FollowPoll:         c.FollowPoll,
+		Workers:            c.Workers,
+		Processor:          c.Processor,
+		BufferSize:         c.BufferSize,
+		ForeignKeys:        c.ForeignKeys,
+		MaxErrors:          c.MaxErrors,
+		ErrorThreshold:     c.ErrorThreshold,
+		AbortOnError:       c.AbortOnError,
+		ShowProgress:       c.ShowProgress,
+		VerboseOutput:      c.VerboseOutput,
+		ProgressFormat:     c.ProgressFormat,
+		ProgressWriter:     c.ProgressWriter,
+		Prescan:            c.Prescan,
+		SlowRecords:        c.SlowRecords,
+		OutputWriter:       c.OutputWriter,
+		CheckpointFile:     c.CheckpointFile,
+		CheckpointInterval: c.CheckpointInterval,
+		Resume:             c.Resume,
+		Metrics:            c.Metrics,
+		Logger:             c.Logger,
+		RunID:              c.RunID,
+		RunReport:          c.RunReport,
+		ReportConfig:       c.ReportConfig,
+		ExitCode:           c.ExitCode,
+		Tracer:             c.Tracer,
+	}
+}
@@ -0,0 +1,31 @@
+package csvproc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/pipeline"
+)
+
+// Every pipeline option must be settable through the public Config
+func TestConfig_Fields(t *testing.T) {
+	public := reflect.TypeOf(Config{})
+	internal := reflect.TypeOf(pipeline.Config{})
+
+	if public.NumField() != internal.NumField() {
+		t.Errorf("expected %d fields, got %d", internal.NumField(), public.NumField())
+	}
+
+	for i := 0; i < internal.NumField(); i++ {
+		field := internal.Field(i)
+		if _, ok := public.FieldByName(field.Name); !ok {
+			t.Errorf("missing field %s", field.Name)
+		}
+	}
+
+	config := Config{Files: []string{"a.csv"}, Workers: 3, RunReport: "report.json", Resume: true}
+	converted := config.pipelineConfig()
+	if converted.Files[0] != "a.csv" || converted.Workers != 3 || converted.RunReport != "report.json" || !converted.Resume {
+		t.Errorf("unexpected pipeline config: %+v", converted)
+	}
+}
@@ -0,0 +1,189 @@
+// Package csvproc is the public API of the CSV processor. It exposes pipeline
+// construction, the Processor interfaces, the record and result models and the
+// CSV reader so other modules can embed the processor without copying
+// internal packages. The processor CLI is built on this package.
+//
+// A Pipeline reads its input files concurrently, passes every record to a
+// Processor on a pool of workers, and collects results, errors and a Summary:
+//
+//	pipe, err := csvproc.NewPipeline(csvproc.Config{
+//		Files:     []string{"data.csv"},
+//		HasHeader: true,
+//		Workers:   4,
+//		Processor: csvproc.NewDefaultProcessor(),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if err := pipe.Run(ctx); err != nil {
+//		return err
+//	}
+//	fmt.Println(pipe.Summary().SuccessCount())
+package csvproc
+
+import (
+	"io"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/metrics"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/pipeline"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+	"github.com/zuhrulumam/csv_processor/internal/tracing"
+	"github.com/zuhrulumam/csv_processor/internal/tracker"
+)
+
+// Pipeline orchestrates reading, processing and output for one run
+type Pipeline = pipeline.Pipeline
+
+// NewPipeline creates a new processing pipeline. A Pipeline is single-use;
+// create a new one for every Run.
+func NewPipeline(config Config) (*Pipeline, error) {
+	return pipeline.NewPipeline(config.pipelineConfig())
+}
+
+// Diagnostics is a snapshot of a run's queues, per-worker counts and errors,
+// as returned by Pipeline.Diagnostics
+type Diagnostics = pipeline.Diagnostics
+
+// RunReport is the machine-readable record of a run written to
+// Config.RunReport, as returned by Pipeline.Report
+type RunReport = pipeline.RunReport
+
+// MetricsRegistry collects pipeline metrics and serves them in the Prometheus
+// text format; set it as Config.Metrics
+type MetricsRegistry = metrics.Registry
+
+// NewRunID returns a random identifier for Config.RunID, to share one run ID
+// across pipelines run for the same job
+func NewRunID() string {
+	return pipeline.NewRunID()
+}
+
+// NewMetricsRegistry creates an empty MetricsRegistry
+func NewMetricsRegistry() *MetricsRegistry {
+	return metrics.NewRegistry()
+}
+
+// Tracer records spans of a run for writing as a Chrome trace; set it as
+// Config.Tracer
+type Tracer = tracing.Tracer
+
+// NewTracer creates a Tracer that starts its clock now
+func NewTracer() *Tracer {
+	return tracing.New()
+}
+
+// Reader reads CSV files concurrently and sends records to a channel
+type Reader = reader.CSVReader
+
+// ReaderConfig holds configuration for Reader
+type ReaderConfig = reader.Config
+
+// NewReader creates a new Reader
+func NewReader(config ReaderConfig) *Reader {
+	return reader.NewCSVReader(config)
+}
+
+// Record is a single CSV record with its file name and line number
+type Record = models.Record
+
+// RecordRef identifies a record by file and line
+type RecordRef = models.RecordRef
+
+// NewRecord creates a new Record
+func NewRecord(lineNumber int, fileName string, data []string, headers []string) *Record {
+	return models.NewRecord(lineNumber, fileName, data, headers)
+}
+
+// Status is the outcome of processing a record
+type Status = models.ProcessingStatus
+
+const (
+	StatusSuccess = models.StatusSuccess
+	StatusFailed  = models.StatusFailed
+	StatusSkipped = models.StatusSkipped
+)
+
+// Result is the outcome of processing a single record
+type Result = models.Result
+
+// Summary aggregates the results of a run
+type Summary = models.Summary
+
+// LatencySet holds a run's processing latency histogram per processor
+type LatencySet = models.LatencySet
+
+// LatencyHistogram counts processing latencies in fixed memory for percentiles
+type LatencyHistogram = models.LatencyHistogram
+
+// SlowRecord is one of the slowest records of a run
+type SlowRecord = models.SlowRecord
+
+// ProgressEvent is one line of the JSON progress stream written when
+// Config.ProgressFormat is "json"
+type ProgressEvent = tracker.Event
+
+// FileStats is the progress of one input file, as returned by
+// Pipeline.Files and included in a ProgressEvent
+type FileStats = tracker.FileStats
+
+// Progress formats for Config.ProgressFormat
+const (
+	ProgressText      = tracker.FormatText
+	ProgressJSON      = tracker.FormatJSON
+	ProgressDashboard = tracker.FormatDashboard
+)
+
+// IsTerminal reports whether w is a terminal, where ProgressDashboard can be
+// used in place of the progress line
+func IsTerminal(w io.Writer) bool {
+	return tracker.IsTerminal(w)
+}
+
+// RoundLatency rounds a duration to three or four significant digits for display
+func RoundLatency(d time.Duration) time.Duration {
+	return models.RoundLatency(d)
+}
+
+// ErrorCollector collects the errors of a run
+type ErrorCollector = errors.Collector
+
+// ErrorEntry is one collected error with its record and category
+type ErrorEntry = errors.ErrorEntry
+
+// ErrorCategory categorizes collected errors
+type ErrorCategory = errors.ErrorCategory
+
+const (
+	CategoryValidation = errors.CategoryValidation
+	CategoryProcessing = errors.CategoryProcessing
+	CategoryIO         = errors.CategoryIO
+	CategoryTimeout    = errors.CategoryTimeout
+	CategoryUnknown    = errors.CategoryUnknown
+)
+
+// ValidationError reports an invalid field value
+type ValidationError = errors.ValidationError
+
+// ProcessingError wraps an error with the operation, file and line
+type ProcessingError = errors.ProcessingError
+
+// NewValidationError creates a ValidationError
+func NewValidationError(field, value, message string) *ValidationError {
+	return errors.NewValidationError(field, value, message)
+}
+
+// NewProcessingError creates a ProcessingError
+func NewProcessingError(op, fileName string, lineNumber int, err error) *ProcessingError {
+	return errors.NewProcessingError(op, fileName, lineNumber, err)
+}
+
+var (
+	// ErrInvalidRecord is returned for malformed records
+	ErrInvalidRecord = errors.ErrInvalidRecord
+
+	// ErrPipelineAlreadyRun is returned by Run on a pipeline that already ran
+	ErrPipelineAlreadyRun = errors.ErrPipelineAlreadyRun
+)
@@ -0,0 +1,104 @@
+package csvproc_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/csvproc"
+)
+
+// writeExampleFile writes a small CSV file to a temporary directory
+func writeExampleFile() (string, func()) {
+	dir, err := os.MkdirTemp("", "csvproc-example")
+	if err != nil {
+		panic(err)
+	}
+
+	path := filepath.Join(dir, "orders.csv")
+	content := "id,customer,amount\n1,alice,10.50\n2,bob,oops\n3,carol,7.25\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		panic(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+// Embedding the pipeline with a custom processor
+func Example() {
+	path, cleanup := writeExampleFile()
+	defer cleanup()
+
+	// Reject rows whose amount is not a number and upper-case the customer
+	proc := csvproc.ProcessorFunc(func(ctx context.Context, record *csvproc.Record) (*csvproc.Result, error) {
+		start := time.Now()
+
+		amount := record.GetFieldByName("amount")
+		if _, err := strconv.ParseFloat(amount, 64); err != nil {
+			err := csvproc.NewValidationError("amount", amount, "not a number")
+			return csvproc.NewFailedResult(record, err, time.Since(start)), nil
+		}
+
+		row := append([]string(nil), record.Data...)
+		row[1] = strings.ToUpper(row[1])
+		return csvproc.NewSuccessResult(record, row, time.Since(start)), nil
+	})
+
+	pipe, err := csvproc.NewPipeline(csvproc.Config{
+		Files:     []string{path},
+		HasHeader: true,
+		Workers:   2,
+		Processor: proc,
+	})
+	if err != nil {
+		fmt.Println("config:", err)
+		return
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		fmt.Println("run:", err)
+		return
+	}
+
+	summary := pipe.Summary()
+	fmt.Printf("records: %d, succeeded: %d, failed: %d\n",
+		summary.TotalRecords(), summary.SuccessCount(), summary.FailedCount())
+
+	for _, entry := range pipe.Errors().Errors() {
+		fmt.Printf("line %d: %v\n", entry.Record.LineNumber, entry.Error)
+	}
+
+	// Output:
+	// records: 3, succeeded: 2, failed: 1
+	// line 3: validation error: field=amount, value=oops, message=not a number
+}
+
+// Reading records without a pipeline
+func ExampleNewReader() {
+	path, cleanup := writeExampleFile()
+	defer cleanup()
+
+	r := csvproc.NewReader(csvproc.ReaderConfig{
+		Files:     []string{path},
+		HasHeader: true,
+	})
+
+	recordCh, errCh := r.Read(context.Background())
+	go func() {
+		for range errCh {
+		}
+	}()
+
+	for record := range recordCh {
+		fmt.Println(record.LineNumber, record.GetFieldByName("customer"))
+	}
+
+	// Output:
+	// 2 alice
+	// 3 bob
+	// 4 carol
+}
@@ -0,0 +1,111 @@
+package csvproc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/aggregate"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+)
+
+// Processor processes a single record. It is called concurrently from every worker.
+type Processor = processor.Processor
+
+// ProcessorFunc adapts a function to the Processor interface
+type ProcessorFunc = processor.ProcessorFunc
+
+// Flusher is implemented by processors that write their output once all
+// records are processed, such as aggregations
+type Flusher = processor.Flusher
+
+// HeaderProvider is implemented by processors that change the output columns;
+// the pipeline writes the header it returns before the first output row
+type HeaderProvider = processor.HeaderProvider
+
+// Named is implemented by processors that report a name; latency statistics
+// are kept per name, and processors without one are reported as "custom"
+type Named = processor.Named
+
+// NewDefaultProcessor creates a processor that validates records and passes them through
+func NewDefaultProcessor() Processor {
+	return processor.NewDefaultProcessor()
+}
+
+// NewSuccessResult creates a successful result. processedData of type
+// []string is written to the output instead of the record's fields.
+func NewSuccessResult(record *Record, processedData interface{}, duration time.Duration) *Result {
+	return models.NewSuccessResult(record, processedData, duration)
+}
+
+// NewFailedResult creates a failed result
+func NewFailedResult(record *Record, err error, duration time.Duration) *Result {
+	return models.NewFailedResult(record, err, duration)
+}
+
+// NewSkippedResult creates a skipped result
+func NewSkippedResult(record *Record, processedData interface{}, duration time.Duration) *Result {
+	return models.NewSkippedResult(record, processedData, duration)
+}
+
+// WorkerID returns the ID of the worker calling Process, or -1 outside a worker
+func WorkerID(ctx context.Context) int {
+	return processor.WorkerID(ctx)
+}
+
+// ForeignKey requires a column's values to exist in a column of another file
+type ForeignKey = processor.ForeignKey
+
+// Enrichment
+
+// EnrichProcessor appends or replaces columns from reference CSV files
+type EnrichProcessor = processor.EnrichProcessor
+
+// EnrichConfig holds configuration for EnrichProcessor
+type EnrichConfig = processor.EnrichConfig
+
+// Reference describes one reference file join
+type Reference = processor.Reference
+
+// MissingKeyPolicy selects what happens when a lookup key is not found
+type MissingKeyPolicy = processor.MissingKeyPolicy
+
+const (
+	MissingKeyFail    = processor.MissingKeyFail
+	MissingKeySkip    = processor.MissingKeySkip
+	MissingKeyDefault = processor.MissingKeyDefault
+)
+
+// IndexMode selects how reference files are indexed
+type IndexMode = processor.IndexMode
+
+const (
+	IndexMemory = processor.IndexMemory
+	IndexDisk   = processor.IndexDisk
+)
+
+// NewEnrichProcessor loads the reference files and creates an EnrichProcessor
+func NewEnrichProcessor(ctx context.Context, config EnrichConfig) (*EnrichProcessor, error) {
+	return processor.NewEnrichProcessor(ctx, config)
+}
+
+// Aggregation
+
+// AggregateProcessor computes group-by aggregates
+type AggregateProcessor = aggregate.Processor
+
+// AggregateConfig holds configuration for AggregateProcessor
+type AggregateConfig = aggregate.Config
+
+// Aggregate is one aggregate column such as sum(amount)
+type Aggregate = aggregate.Aggregate
+
+// ParseAggregates parses a list such as "sum(amount),count(),avg(fee)"
+func ParseAggregates(spec string) ([]Aggregate, error) {
+	return aggregate.ParseAggregates(spec)
+}
+
+// NewAggregateProcessor creates an AggregateProcessor
+func NewAggregateProcessor(config AggregateConfig) (*AggregateProcessor, error) {
+	return aggregate.NewProcessor(config)
+}
@@ -0,0 +1,448 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+)
+
+// timeLayouts are tried in order when comparing min/max values as timestamps
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Config holds configuration for the aggregation Processor
+type Config struct {
+	// GroupBy are the grouping columns (empty = a single group)
+	GroupBy []string
+
+	// Aggregates are computed for every group
+	Aggregates []Aggregate
+
+	// Workers is the number of pipeline workers (0 = NumCPU). Each gets a
+	// partial table up front, so Process finds it without locking.
+	Workers int
+}
+
+// Processor computes group-by aggregates. Each worker accumulates into its own
+// partial table, and the partials are merged when Flush is called.
+type Processor struct {
+	config Config
+
+	// workers holds the partial of each worker ID below Config.Workers
+	workers []*partial
+
+	// mu protects others, the partials of any other callers
+	mu     sync.Mutex
+	others map[int]*partial
+}
+
+// partial is one worker's aggregates
+type partial struct {
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// group holds the accumulators for one distinct group key
+type group struct {
+	key  []string
+	accs []accumulator
+}
+
+// accumulator holds the running state of one aggregate
+type accumulator struct {
+	count    int64
+	sum      float64
+	min, max value
+}
+
+// valueKind is the parsed type of a min/max value. Values of different kinds
+// order by kind: numbers, then timestamps, then text.
+type valueKind int
+
+const (
+	kindNone valueKind = iota
+	kindNumber
+	kindTime
+	kindString
+)
+
+// value is a typed field value for min/max comparisons
+type value struct {
+	raw  string
+	num  float64
+	kind valueKind
+}
+
+// NewProcessor creates a new aggregation Processor
+func NewProcessor(config Config) (*Processor, error) {
+	if len(config.Aggregates) == 0 {
+		return nil, fmt.Errorf("no aggregates specified")
+	}
+
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	p := &Processor{
+		config:  config,
+		workers: make([]*partial, config.Workers),
+		others:  make(map[int]*partial),
+	}
+	for i := range p.workers {
+		p.workers[i] = newPartial()
+	}
+
+	return p, nil
+}
+
+// newPartial creates an empty partial table
+func newPartial() *partial {
+	return &partial{groups: make(map[string]*group)}
+}
+
+// partial returns the partial table for a worker. Callers without a worker
+// ID, or with one beyond Config.Workers, share partials created on first use.
+func (p *Processor) partial(workerID int) *partial {
+	if workerID >= 0 && workerID < len(p.workers) {
+		return p.workers[workerID]
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	part, ok := p.others[workerID]
+	if !ok {
+		part = newPartial()
+		p.others[workerID] = part
+	}
+
+	return part
+}
+
+// Name implements the processor.Named interface
+func (p *Processor) Name() string {
+	return "aggregate"
+}
+
+// Process implements the processor.Processor interface
+func (p *Processor) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !record.IsValid() {
+		return models.NewFailedResult(record, errors.ErrInvalidRecord, 0), nil
+	}
+
+	key := make([]string, len(p.config.GroupBy))
+	for i, column := range p.config.GroupBy {
+		index := fieldIndex(record, column)
+		if index < 0 {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				column, "", "group-by column not present in record",
+			), 0), nil
+		}
+		key[i] = record.Data[index]
+	}
+
+	// Parse every value before touching the accumulators so a bad field
+	// leaves the group unchanged
+	values := make([]value, len(p.config.Aggregates))
+	for i, agg := range p.config.Aggregates {
+		if agg.Column == "" {
+			continue
+		}
+
+		index := fieldIndex(record, agg.Column)
+		if index < 0 {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				agg.Column, "", "aggregate column not present in record",
+			), 0), nil
+		}
+
+		v, err := parseValue(record.Data[index], agg.Func)
+		if err != nil {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				agg.Column, record.Data[index], err.Error(),
+			), 0), nil
+		}
+		values[i] = v
+	}
+
+	part := p.partial(processor.WorkerID(ctx))
+	part.mu.Lock()
+	part.add(groupKey(key), key, p.config.Aggregates, values)
+	part.mu.Unlock()
+
+	return models.NewSuccessResult(record, nil, 0), nil
+}
+
+// Flush merges the partial aggregates and returns one row per group, sorted by group key
+func (p *Processor) Flush(ctx context.Context) ([]string, [][]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	merged := make(map[string]*group)
+
+	parts := append([]*partial(nil), p.workers...)
+	for _, part := range p.others {
+		parts = append(parts, part)
+	}
+
+	for _, part := range parts {
+		part.mu.Lock()
+		for k, g := range part.groups {
+			target, ok := merged[k]
+			if !ok {
+				merged[k] = g
+				continue
+			}
+			for i := range target.accs {
+				target.accs[i].merge(g.accs[i])
+			}
+		}
+		part.groups = make(map[string]*group)
+		part.mu.Unlock()
+	}
+
+	groups := make([]*group, 0, len(merged))
+	for _, g := range merged {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return compareKeys(groups[i].key, groups[j].key) < 0
+	})
+
+	rows := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+
+		row := append([]string(nil), g.key...)
+		for i, agg := range p.config.Aggregates {
+			row = append(row, g.accs[i].result(agg.Func))
+		}
+		rows = append(rows, row)
+	}
+
+	return p.Header(), rows, nil
+}
+
+// Header returns the output column names
+func (p *Processor) Header() []string {
+	header := append([]string(nil), p.config.GroupBy...)
+	for _, agg := range p.config.Aggregates {
+		header = append(header, agg.String())
+	}
+	return header
+}
+
+// add folds one record's values into its group
+func (part *partial) add(k string, key []string, aggs []Aggregate, values []value) {
+	g, ok := part.groups[k]
+	if !ok {
+		g = &group{key: key, accs: make([]accumulator, len(aggs))}
+		part.groups[k] = g
+	}
+
+	for i, agg := range aggs {
+		g.accs[i].add(agg, values[i])
+	}
+}
+
+// add folds a single value into the accumulator
+func (a *accumulator) add(agg Aggregate, v value) {
+	// count() counts rows; every other aggregate ignores empty fields
+	if agg.Column == "" {
+		a.count++
+		return
+	}
+	if v.kind == kindNone {
+		return
+	}
+
+	a.count++
+	a.sum += v.num
+
+	if a.min.kind == kindNone || compareValues(v, a.min) < 0 {
+		a.min = v
+	}
+	if a.max.kind == kindNone || compareValues(v, a.max) > 0 {
+		a.max = v
+	}
+}
+
+// merge combines another partial accumulator into this one
+func (a *accumulator) merge(other accumulator) {
+	a.count += other.count
+	a.sum += other.sum
+
+	if other.min.kind != kindNone && (a.min.kind == kindNone || compareValues(other.min, a.min) < 0) {
+		a.min = other.min
+	}
+	if other.max.kind != kindNone && (a.max.kind == kindNone || compareValues(other.max, a.max) > 0) {
+		a.max = other.max
+	}
+}
+
+// result formats the final aggregate value
+func (a *accumulator) result(fn Func) string {
+	switch fn {
+	case FuncCount:
+		return strconv.FormatInt(a.count, 10)
+	case FuncSum:
+		if a.count == 0 {
+			return ""
+		}
+		return formatNumber(a.sum)
+	case FuncAvg:
+		if a.count == 0 {
+			return ""
+		}
+		return formatNumber(a.sum / float64(a.count))
+	case FuncMin:
+		return a.min.raw
+	case FuncMax:
+		return a.max.raw
+	default:
+		return ""
+	}
+}
+
+// parseValue parses a field for the given aggregate function.
+// sum and avg require numbers; min and max accept numbers, timestamps or text.
+func parseValue(raw string, fn Func) (value, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return value{}, nil
+	}
+
+	if isDecimal(trimmed) {
+		// Only overflow can fail here, which would turn the sum infinite
+		if n, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return value{raw: raw, num: n, kind: kindNumber}, nil
+		}
+	}
+
+	if fn == FuncSum || fn == FuncAvg {
+		return value{}, fmt.Errorf("%s requires a finite decimal number", fn)
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			return value{raw: raw, num: float64(t.UnixNano()), kind: kindTime}, nil
+		}
+	}
+
+	return value{raw: raw, kind: kindString}, nil
+}
+
+// isDecimal reports whether s is a plain decimal number with an optional sign,
+// fraction and exponent. Unlike strconv.ParseFloat it rejects NaN, Inf, hex
+// and underscores.
+func isDecimal(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+
+	digits := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return false
+	}
+
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		start := i
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		}
+		if i == start {
+			return false
+		}
+	}
+
+	return i == len(s)
+}
+
+// compareValues orders two typed values, first by kind so that the order is
+// consistent when a column mixes numbers, timestamps and text
+func compareValues(a, b value) int {
+	switch {
+	case a.kind != b.kind:
+		if a.kind < b.kind {
+			return -1
+		}
+		return 1
+	case a.kind == kindString:
+		return strings.Compare(a.raw, b.raw)
+	case a.num < b.num:
+		return -1
+	case a.num > b.num:
+		return 1
+	}
+	return 0
+}
+
+// fieldIndex returns the position of column in the record headers, or -1
+func fieldIndex(record *models.Record, column string) int {
+	for i, header := range record.Headers {
+		if header == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupKey encodes group values unambiguously as a map key
+func groupKey(values []string) string {
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteString(strconv.Itoa(len(v)))
+		b.WriteByte(':')
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// compareKeys compares group keys column by column
+func compareKeys(a, b []string) int {
+	for i := range a {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// formatNumber formats a float without trailing zeros
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
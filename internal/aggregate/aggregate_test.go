@@ -0,0 +1,173 @@
+package aggregate
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+)
+
+func TestParseAggregates(t *testing.T) {
+	aggs, err := ParseAggregates("sum(amount), count(), avg(fee),min(ts),max(ts)")
+	if err != nil {
+		t.Fatalf("ParseAggregates() error: %v", err)
+	}
+
+	want := []Aggregate{
+		{Func: FuncSum, Column: "amount"},
+		{Func: FuncCount},
+		{Func: FuncAvg, Column: "fee"},
+		{Func: FuncMin, Column: "ts"},
+		{Func: FuncMax, Column: "ts"},
+	}
+
+	if !reflect.DeepEqual(aggs, want) {
+		t.Errorf("ParseAggregates() = %+v, want %+v", aggs, want)
+	}
+
+	for _, spec := range []string{"median(x)", "sum()", "sum", ""} {
+		if _, err := ParseAggregates(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestProcessor_PerWorkerPartials(t *testing.T) {
+	aggs, _ := ParseAggregates("sum(amount),count(),avg(amount),min(ts),max(ts)")
+
+	proc, err := NewProcessor(Config{
+		GroupBy:    []string{"merchant"},
+		Aggregates: aggs,
+		Workers:    2,
+	})
+	if err != nil {
+		t.Fatalf("NewProcessor() error: %v", err)
+	}
+
+	headers := []string{"merchant", "amount", "ts"}
+	rows := [][]string{
+		{"a", "10", "2024-01-02"},
+		{"b", "1.5", "2024-01-05"},
+		{"a", "20", "2024-01-01"},
+		{"a", "", "2024-01-10"},
+		{"b", "2.5", "2023-12-31"},
+	}
+
+	// Spread records across workers so Flush has to merge partials; the
+	// third worker is beyond Config.Workers and gets a shared partial
+	const workers = 3
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			ctx := processor.WithWorkerID(context.Background(), id)
+			for i := id; i < len(rows); i += workers {
+				record := models.NewRecord(i+2, "tx.csv", rows[i], headers)
+				if _, err := proc.Process(ctx, record); err != nil {
+					t.Errorf("Process() error: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	header, out, err := proc.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+
+	wantHeader := []string{"merchant", "sum(amount)", "count()", "avg(amount)", "min(ts)", "max(ts)"}
+	if !reflect.DeepEqual(header, wantHeader) {
+		t.Errorf("header = %v, want %v", header, wantHeader)
+	}
+
+	want := [][]string{
+		{"a", "30", "3", "15", "2024-01-01", "2024-01-10"},
+		{"b", "4", "2", "2", "2023-12-31", "2024-01-05"},
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("rows = %v, want %v", out, want)
+	}
+}
+
+func TestProcessor_NonNumericSum(t *testing.T) {
+	aggs, _ := ParseAggregates("sum(amount)")
+	proc, _ := NewProcessor(Config{Aggregates: aggs})
+
+	record := models.NewRecord(2, "tx.csv", []string{"abc"}, []string{"amount"})
+	result, err := proc.Process(context.Background(), record)
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+
+	if !result.IsFailed() {
+		t.Errorf("expected failed result for non-numeric sum, got %s", result.Status)
+	}
+}
+
+func TestParseValue_Decimal(t *testing.T) {
+	tests := []struct {
+		raw  string
+		kind valueKind
+	}{
+		{"42", kindNumber},
+		{"-1.5", kindNumber},
+		{"+.5", kindNumber},
+		{"3.", kindNumber},
+		{"1e3", kindNumber},
+		{"2.5E-2", kindNumber},
+		{"NaN", kindString},
+		{"Inf", kindString},
+		{"-infinity", kindString},
+		{"0x1p4", kindString},
+		{"1_000", kindString},
+		{"1e", kindString},
+		{".", kindString},
+		{"1e400", kindString},
+	}
+
+	for _, tt := range tests {
+		v, err := parseValue(tt.raw, FuncMin)
+		if err != nil {
+			t.Fatalf("parseValue(%q) error: %v", tt.raw, err)
+		}
+		if v.kind != tt.kind {
+			t.Errorf("parseValue(%q) kind = %d, want %d", tt.raw, v.kind, tt.kind)
+		}
+
+		if _, err := parseValue(tt.raw, FuncSum); (err == nil) != (tt.kind == kindNumber) {
+			t.Errorf("parseValue(%q) for sum: error = %v", tt.raw, err)
+		}
+	}
+}
+
+func TestProcessor_MixedMinMax(t *testing.T) {
+	aggs, _ := ParseAggregates("min(v),max(v)")
+	headers := []string{"v"}
+	values := []string{"10", "abc", "9", "2024-01-01", "B", "100"}
+
+	// The result must not depend on the order values arrive in
+	for _, order := range [][]int{{0, 1, 2, 3, 4, 5}, {5, 4, 3, 2, 1, 0}, {1, 3, 5, 0, 2, 4}} {
+		proc, _ := NewProcessor(Config{Aggregates: aggs, Workers: 1})
+		for _, i := range order {
+			record := models.NewRecord(i+2, "tx.csv", []string{values[i]}, headers)
+			if _, err := proc.Process(context.Background(), record); err != nil {
+				t.Fatalf("Process() error: %v", err)
+			}
+		}
+
+		_, rows, err := proc.Flush(context.Background())
+		if err != nil {
+			t.Fatalf("Flush() error: %v", err)
+		}
+
+		want := []string{"9", "abc"}
+		if len(rows) != 1 || !reflect.DeepEqual(rows[0], want) {
+			t.Errorf("order %v: rows = %v, want [%v]", order, rows, want)
+		}
+	}
+}
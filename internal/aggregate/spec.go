@@ -0,0 +1,93 @@
+package aggregate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Func is an aggregate function
+type Func string
+
+const (
+	FuncSum   Func = "sum"
+	FuncCount Func = "count"
+	FuncAvg   Func = "avg"
+	FuncMin   Func = "min"
+	FuncMax   Func = "max"
+)
+
+// Aggregate is one aggregate column such as sum(amount)
+type Aggregate struct {
+	// Func is the aggregate function
+	Func Func
+
+	// Column is the input column (empty for count())
+	Column string
+}
+
+// String returns the aggregate in func(column) form, used as the output column name
+func (a Aggregate) String() string {
+	return fmt.Sprintf("%s(%s)", a.Func, a.Column)
+}
+
+// ParseAggregates parses a list such as "sum(amount),count(),avg(fee)"
+func ParseAggregates(spec string) ([]Aggregate, error) {
+	var aggs []Aggregate
+
+	for _, part := range splitTopLevel(spec) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		open := strings.Index(part, "(")
+		if open <= 0 || !strings.HasSuffix(part, ")") {
+			return nil, fmt.Errorf("invalid aggregate %q (want func(column))", part)
+		}
+
+		agg := Aggregate{
+			Func:   Func(strings.ToLower(strings.TrimSpace(part[:open]))),
+			Column: strings.TrimSpace(part[open+1 : len(part)-1]),
+		}
+
+		switch agg.Func {
+		case FuncCount:
+		case FuncSum, FuncAvg, FuncMin, FuncMax:
+			if agg.Column == "" {
+				return nil, fmt.Errorf("aggregate %q requires a column", part)
+			}
+		default:
+			return nil, fmt.Errorf("unknown aggregate function %q", agg.Func)
+		}
+
+		aggs = append(aggs, agg)
+	}
+
+	if len(aggs) == 0 {
+		return nil, fmt.Errorf("no aggregates specified")
+	}
+
+	return aggs, nil
+}
+
+// splitTopLevel splits on commas that are not inside parentheses
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
@@ -0,0 +1,229 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checkpoint records which input lines have been completed so an interrupted
+// run can resume. Each file keeps the highest contiguous completed line plus
+// the lines completed out of order beyond it, since workers finish records in
+// any order.
+type Checkpoint struct {
+	path string
+
+	// firstLine is the line number of the first data record
+	firstLine int
+
+	mu         sync.RWMutex
+	files      map[string]*fileProgress
+	outputSize int64
+}
+
+// fileProgress is the completion state of one input file
+type fileProgress struct {
+	through int
+	done    map[int]struct{}
+}
+
+// state is the on-disk form of a Checkpoint
+type state struct {
+	Files      map[string]fileState `json:"files"`
+	OutputSize int64                `json:"output_size"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// fileState is the on-disk form of fileProgress
+type fileState struct {
+	Through int   `json:"through"`
+	Done    []int `json:"done,omitempty"`
+}
+
+// Open creates a Checkpoint stored at path. With resume set, the existing
+// checkpoint is loaded; a missing file starts from the beginning.
+func Open(path string, hasHeader, resume bool) (*Checkpoint, error) {
+	c := &Checkpoint{
+		path:      path,
+		firstLine: 1,
+		files:     make(map[string]*fileProgress),
+	}
+
+	// The header is line 1, so data starts on line 2
+	if hasHeader {
+		c.firstLine = 2
+	}
+
+	if !resume {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+
+	c.outputSize = s.OutputSize
+	for file, fs := range s.Files {
+		progress := &fileProgress{through: fs.Through, done: make(map[int]struct{}, len(fs.Done))}
+		for _, line := range fs.Done {
+			progress.done[line] = struct{}{}
+		}
+		c.files[file] = progress
+	}
+
+	return c, nil
+}
+
+// Completed reports whether a line of file was completed by a previous run
+func (c *Checkpoint) Completed(file string, line int) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	progress, ok := c.files[file]
+	if !ok {
+		return false
+	}
+
+	if line <= progress.through {
+		return true
+	}
+
+	_, done := progress.done[line]
+	return done
+}
+
+// MarkDone records a completed line, advancing the contiguous watermark when possible
+func (c *Checkpoint) MarkDone(file string, line int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	progress, ok := c.files[file]
+	if !ok {
+		progress = &fileProgress{through: c.firstLine - 1, done: make(map[int]struct{})}
+		c.files[file] = progress
+	}
+
+	if line <= progress.through {
+		return
+	}
+
+	progress.done[line] = struct{}{}
+
+	for {
+		next := progress.through + 1
+		if _, ok := progress.done[next]; !ok {
+			break
+		}
+		delete(progress.done, next)
+		progress.through = next
+	}
+}
+
+// Through returns the highest contiguous completed line of file
+func (c *Checkpoint) Through(file string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if progress, ok := c.files[file]; ok {
+		return progress.through
+	}
+	return 0
+}
+
+// CompletedCount returns how many data records of file are completed
+func (c *Checkpoint) CompletedCount(file string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	progress, ok := c.files[file]
+	if !ok {
+		return 0
+	}
+
+	count := len(progress.done)
+	if progress.through >= c.firstLine {
+		count += progress.through - c.firstLine + 1
+	}
+	return count
+}
+
+// OutputSize returns the output size in bytes recorded by the last save
+func (c *Checkpoint) OutputSize() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.outputSize
+}
+
+// Save persists the checkpoint. outputSize is the number of output bytes that
+// were durably written together with the completed lines; the caller must
+// flush and sync the output before saving.
+func (c *Checkpoint) Save(outputSize int64) error {
+	c.mu.Lock()
+	c.outputSize = outputSize
+
+	s := state{
+		Files:      make(map[string]fileState, len(c.files)),
+		OutputSize: outputSize,
+		UpdatedAt:  time.Now(),
+	}
+
+	for file, progress := range c.files {
+		fs := fileState{Through: progress.through}
+		for line := range progress.done {
+			fs.Done = append(fs.Done, line)
+		}
+		sort.Ints(fs.Done)
+		s.Files[file] = fs
+	}
+	c.mu.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	return writeFileAtomic(c.path, data)
+}
+
+// writeFileAtomic replaces path with data so a crash never leaves a partial checkpoint
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace checkpoint: %w", err)
+	}
+
+	return nil
+}
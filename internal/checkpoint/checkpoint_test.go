@@ -0,0 +1,95 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint_MarkDone(t *testing.T) {
+	cp, err := Open(filepath.Join(t.TempDir(), "run.checkpoint"), true, false)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	// Data starts on line 2; line 3 finishing first must not advance the watermark
+	cp.MarkDone("a.csv", 3)
+	if got := cp.Through("a.csv"); got != 1 {
+		t.Errorf("expected through 1, got %d", got)
+	}
+
+	cp.MarkDone("a.csv", 2)
+	if got := cp.Through("a.csv"); got != 3 {
+		t.Errorf("expected through 3, got %d", got)
+	}
+
+	cp.MarkDone("a.csv", 5)
+
+	tests := []struct {
+		line int
+		want bool
+	}{
+		{2, true},
+		{3, true},
+		{4, false},
+		{5, true},
+		{6, false},
+	}
+
+	for _, tt := range tests {
+		if got := cp.Completed("a.csv", tt.line); got != tt.want {
+			t.Errorf("Completed(a.csv, %d) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	if cp.Completed("b.csv", 2) {
+		t.Error("expected no completed lines for unseen file")
+	}
+
+	// Lines 2, 3 and 5
+	if got := cp.CompletedCount("a.csv"); got != 3 {
+		t.Errorf("expected 3 completed records, got %d", got)
+	}
+	if got := cp.CompletedCount("b.csv"); got != 0 {
+		t.Errorf("expected 0 completed records for unseen file, got %d", got)
+	}
+}
+
+func TestCheckpoint_SaveAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.checkpoint")
+
+	cp, _ := Open(path, false, false)
+	cp.MarkDone("a.csv", 1)
+	cp.MarkDone("a.csv", 2)
+	cp.MarkDone("a.csv", 4)
+
+	if err := cp.Save(128); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	resumed, err := Open(path, false, true)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	if got := resumed.OutputSize(); got != 128 {
+		t.Errorf("expected output size 128, got %d", got)
+	}
+	if got := resumed.Through("a.csv"); got != 2 {
+		t.Errorf("expected through 2, got %d", got)
+	}
+	if !resumed.Completed("a.csv", 4) || resumed.Completed("a.csv", 3) {
+		t.Error("expected line 4 completed and line 3 pending")
+	}
+
+	// Without resume an existing checkpoint is ignored
+	fresh, _ := Open(path, false, false)
+	if fresh.Completed("a.csv", 1) || fresh.OutputSize() != 0 {
+		t.Error("expected fresh checkpoint to start empty")
+	}
+
+	// Resuming without a checkpoint starts from the beginning
+	missing, err := Open(filepath.Join(t.TempDir(), "missing"), false, true)
+	if err != nil || missing.Completed("a.csv", 1) {
+		t.Errorf("expected empty checkpoint for missing file, got err %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+	"github.com/zuhrulumam/csv_processor/internal/sorter"
+)
+
+// ChangeType classifies a difference between two datasets
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// ColumnChange is the before and after value of one column
+type ColumnChange struct {
+	Column string `json:"column"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Change is one added, removed or changed row
+type Change struct {
+	Type ChangeType
+
+	// Key holds the key column values
+	Key []string
+
+	// Columns lists the changed columns; for added and removed rows it
+	// lists every column with only After or Before set
+	Columns []ColumnChange
+}
+
+// Stats counts the differences found
+type Stats struct {
+	Added     int
+	Removed   int
+	Changed   int
+	Unchanged int
+}
+
+// Identical reports whether no differences were found
+func (s Stats) Identical() bool {
+	return s.Added == 0 && s.Removed == 0 && s.Changed == 0
+}
+
+// Config holds configuration for Differ
+type Config struct {
+	// KeyColumns identify rows across both files
+	KeyColumns []string
+
+	// Delimiter separates fields of both files (default: ',')
+	Delimiter rune
+
+	// MemoryBudget bounds the memory used to sort each side (0 = sorter default)
+	MemoryBudget int64
+
+	// Workers is the number of sorter pool workers (0 = NumCPU)
+	Workers int
+
+	// TempDir holds the sorted copies of both inputs (default: os.TempDir())
+	TempDir string
+}
+
+// Differ compares two CSV files by key. Both sides are sorted by key with
+// the external sorter and then merge-joined, so inputs may exceed memory.
+type Differ struct {
+	config Config
+}
+
+// NewDiffer creates a new Differ
+func NewDiffer(config Config) (*Differ, error) {
+	if len(config.KeyColumns) == 0 {
+		return nil, fmt.Errorf("no key columns specified")
+	}
+
+	if config.Delimiter == 0 {
+		config.Delimiter = ','
+	}
+
+	return &Differ{config: config}, nil
+}
+
+// Diff compares oldFile with newFile and calls emit for every difference in
+// key order. A key that appears more than once in either file is an error,
+// since rows could not be paired reliably.
+func (d *Differ) Diff(ctx context.Context, oldFile, newFile string, emit func(Change) error) (Stats, error) {
+	var stats Stats
+
+	tmpDir, err := os.MkdirTemp(d.config.TempDir, "csvdiff-")
+	if err != nil {
+		return stats, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	oldSorted := filepath.Join(tmpDir, "old.csv")
+	if err := d.sortFile(ctx, oldFile, oldSorted); err != nil {
+		return stats, fmt.Errorf("sort %s: %w", oldFile, err)
+	}
+
+	newSorted := filepath.Join(tmpDir, "new.csv")
+	if err := d.sortFile(ctx, newFile, newSorted); err != nil {
+		return stats, fmt.Errorf("sort %s: %w", newFile, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	oldSide := d.openSide(ctx, oldSorted, oldFile)
+	newSide := d.openSide(ctx, newSorted, newFile)
+	defer oldSide.drain()
+	defer newSide.drain()
+
+	o, err := oldSide.next()
+	if err != nil {
+		return stats, err
+	}
+	n, err := newSide.next()
+	if err != nil {
+		return stats, err
+	}
+
+	for o != nil || n != nil {
+		var change *Change
+
+		switch {
+		case n == nil || (o != nil && compareKeys(o.key, n.key) < 0):
+			change = rowChange(Removed, o)
+			stats.Removed++
+			o, err = oldSide.next()
+
+		case o == nil || compareKeys(o.key, n.key) > 0:
+			change = rowChange(Added, n)
+			stats.Added++
+			n, err = newSide.next()
+
+		default:
+			if columns := compareRows(o, n); len(columns) > 0 {
+				change = &Change{Type: Changed, Key: n.key, Columns: columns}
+				stats.Changed++
+			} else {
+				stats.Unchanged++
+			}
+
+			if o, err = oldSide.next(); err == nil {
+				n, err = newSide.next()
+			}
+		}
+
+		if change != nil {
+			if emitErr := emit(*change); emitErr != nil {
+				return stats, fmt.Errorf("write diff: %w", emitErr)
+			}
+		}
+
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// sortFile writes a copy of file sorted by the key columns
+func (d *Differ) sortFile(ctx context.Context, file, dest string) error {
+	keys := make([]sorter.SortKey, len(d.config.KeyColumns))
+	for i, column := range d.config.KeyColumns {
+		keys[i] = sorter.SortKey{Column: column, Type: sorter.KeyString}
+	}
+
+	s, err := sorter.NewSorter(sorter.Config{
+		Keys:         keys,
+		HasHeader:    true,
+		Delimiter:    d.config.Delimiter,
+		MemoryBudget: d.config.MemoryBudget,
+		Workers:      d.config.Workers,
+		TempDir:      filepath.Dir(dest),
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := s.Sort(ctx, []string{file}, out); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// keyedRow is a record with its extracted key
+type keyedRow struct {
+	record *models.Record
+	key    []string
+}
+
+// side streams one sorted input through CSVReader
+type side struct {
+	file       string
+	keyColumns []string
+	recordCh   <-chan *models.Record
+	errCh      <-chan error
+	keyIndexes []int
+	lastKey    []string
+}
+
+// openSide starts reading a sorted copy of file
+func (d *Differ) openSide(ctx context.Context, sorted, file string) *side {
+	recordCh, errCh := reader.NewCSVReader(reader.Config{
+		Files:     []string{sorted},
+		HasHeader: true,
+		Delimiter: d.config.Delimiter,
+	}).Read(ctx)
+
+	return &side{
+		file:       file,
+		keyColumns: d.config.KeyColumns,
+		recordCh:   recordCh,
+		errCh:      errCh,
+	}
+}
+
+// next returns the next row, or nil at the end of the input. Rows arrive
+// sorted by key, so a duplicate key follows the row it repeats.
+func (s *side) next() (*keyedRow, error) {
+	record, ok := <-s.recordCh
+	if !ok {
+		for err := range s.errCh {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if s.keyIndexes == nil {
+		s.keyIndexes = make([]int, len(s.keyColumns))
+		for i, column := range s.keyColumns {
+			s.keyIndexes[i] = indexOf(record.Headers, column)
+			if s.keyIndexes[i] < 0 {
+				return nil, fmt.Errorf("key column %q not found", column)
+			}
+		}
+	}
+
+	key := make([]string, len(s.keyIndexes))
+	for i, index := range s.keyIndexes {
+		key[i] = record.GetField(index)
+	}
+
+	if s.lastKey != nil && compareKeys(key, s.lastKey) == 0 {
+		return nil, fmt.Errorf("duplicate key %s in %s", strings.Join(key, ","), s.file)
+	}
+	s.lastKey = key
+
+	return &keyedRow{record: record, key: key}, nil
+}
+
+// drain consumes any remaining input so the reader goroutines exit
+func (s *side) drain() {
+	for range s.recordCh {
+	}
+	for range s.errCh {
+	}
+}
+
+// rowChange describes an added or removed row column by column
+func rowChange(changeType ChangeType, row *keyedRow) *Change {
+	change := &Change{Type: changeType, Key: row.key}
+
+	for i, column := range row.record.Headers {
+		value := row.record.GetField(i)
+		cc := ColumnChange{Column: column}
+		if changeType == Added {
+			cc.After = value
+		} else {
+			cc.Before = value
+		}
+		change.Columns = append(change.Columns, cc)
+	}
+
+	return change
+}
+
+// compareRows returns the columns whose values differ, matching columns by
+// name; a column present on only one side compares against an empty value
+func compareRows(o, n *keyedRow) []ColumnChange {
+	var changes []ColumnChange
+
+	for i, column := range o.record.Headers {
+		before := o.record.GetField(i)
+		after := ""
+		if j := indexOf(n.record.Headers, column); j >= 0 {
+			after = n.record.GetField(j)
+		}
+		if before != after {
+			changes = append(changes, ColumnChange{Column: column, Before: before, After: after})
+		}
+	}
+
+	for j, column := range n.record.Headers {
+		if indexOf(o.record.Headers, column) >= 0 {
+			continue
+		}
+		if after := n.record.GetField(j); after != "" {
+			changes = append(changes, ColumnChange{Column: column, After: after})
+		}
+	}
+
+	return changes
+}
+
+// compareKeys compares keys column by column, matching the sorter's string order
+func compareKeys(a, b []string) int {
+	for i := range a {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// indexOf returns the position of column in headers, or -1
+func indexOf(headers []string, column string) int {
+	for i, header := range headers {
+		if header == column {
+			return i
+		}
+	}
+	return -1
+}
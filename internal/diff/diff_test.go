@@ -0,0 +1,204 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	return path
+}
+
+func TestDiffer_Diff(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldFile := writeFile(t, tmpDir, "old.csv", "id,name,amount\n3,carol,30\n1,alice,10\n2,bob,20\n")
+	newFile := writeFile(t, tmpDir, "new.csv", "id,name,amount\n1,alice,10\n4,dave,40\n3,carol,35\n")
+
+	differ, err := NewDiffer(Config{KeyColumns: []string{"id"}, TempDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewDiffer() error: %v", err)
+	}
+
+	var changes []Change
+	stats, err := differ.Diff(context.Background(), oldFile, newFile, func(c Change) error {
+		changes = append(changes, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+
+	want := Stats{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}
+	if stats != want {
+		t.Errorf("expected stats %+v, got %+v", want, stats)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(changes))
+	}
+
+	// Changes are emitted in key order
+	tests := []struct {
+		changeType ChangeType
+		key        string
+	}{
+		{Removed, "2"},
+		{Changed, "3"},
+		{Added, "4"},
+	}
+
+	for i, tt := range tests {
+		if changes[i].Type != tt.changeType || changes[i].Key[0] != tt.key {
+			t.Errorf("change %d: expected %s %s, got %s %v", i, tt.changeType, tt.key, changes[i].Type, changes[i].Key)
+		}
+	}
+
+	changed := changes[1].Columns
+	if len(changed) != 1 || changed[0] != (ColumnChange{Column: "amount", Before: "30", After: "35"}) {
+		t.Errorf("unexpected column changes: %+v", changed)
+	}
+}
+
+func TestDiffer_Identical(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "id,name\n1,a\n2,b\n"
+	oldFile := writeFile(t, tmpDir, "old.csv", content)
+	newFile := writeFile(t, tmpDir, "new.csv", content)
+
+	differ, _ := NewDiffer(Config{KeyColumns: []string{"id"}, TempDir: tmpDir})
+
+	stats, err := differ.Diff(context.Background(), oldFile, newFile, func(c Change) error {
+		t.Errorf("unexpected change: %+v", c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+
+	if !stats.Identical() || stats.Unchanged != 2 {
+		t.Errorf("expected 2 unchanged rows, got %+v", stats)
+	}
+}
+
+func TestDiffer_Delimiter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldFile := writeFile(t, tmpDir, "old.csv", "id;amount\n1;1,5\n2;2,0\n")
+	newFile := writeFile(t, tmpDir, "new.csv", "id;amount\n2;2,0\n1;1,75\n")
+
+	differ, _ := NewDiffer(Config{KeyColumns: []string{"id"}, Delimiter: ';', TempDir: tmpDir})
+
+	var changes []Change
+	stats, err := differ.Diff(context.Background(), oldFile, newFile, func(c Change) error {
+		changes = append(changes, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+
+	if stats != (Stats{Changed: 1, Unchanged: 1}) {
+		t.Errorf("expected 1 changed and 1 unchanged row, got %+v", stats)
+	}
+	if len(changes) != 1 || changes[0].Columns[0] != (ColumnChange{Column: "amount", Before: "1,5", After: "1,75"}) {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestDiffer_MissingKeyColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldFile := writeFile(t, tmpDir, "old.csv", "id,name\n1,a\n")
+	newFile := writeFile(t, tmpDir, "new.csv", "id,name\n1,a\n")
+
+	differ, _ := NewDiffer(Config{KeyColumns: []string{"missing"}, TempDir: tmpDir})
+
+	_, err := differ.Diff(context.Background(), oldFile, newFile, func(Change) error { return nil })
+	if err == nil {
+		t.Error("expected error for unknown key column")
+	}
+}
+
+func TestDiffer_DuplicateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		file string
+	}{
+		{"old", "id,name\n1,a\n2,b\n1,c\n", "id,name\n1,a\n2,b\n", "old.csv"},
+		{"new", "id,name\n1,a\n2,b\n", "id,name\n2,b\n1,a\n2,b\n", "new.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			oldFile := writeFile(t, tmpDir, "old.csv", tt.old)
+			newFile := writeFile(t, tmpDir, "new.csv", tt.new)
+
+			differ, _ := NewDiffer(Config{KeyColumns: []string{"id"}, TempDir: tmpDir})
+
+			_, err := differ.Diff(context.Background(), oldFile, newFile, func(Change) error { return nil })
+			if err == nil {
+				t.Fatal("expected error for duplicate key")
+			}
+			if !strings.Contains(err.Error(), "duplicate key") || !strings.Contains(err.Error(), tt.file) {
+				t.Errorf("expected duplicate key error naming %s, got %v", tt.file, err)
+			}
+		})
+	}
+}
+
+func TestWriter_Formats(t *testing.T) {
+	change := Change{
+		Type:    Changed,
+		Key:     []string{"3"},
+		Columns: []ColumnChange{{Column: "amount", Before: "30", After: "35"}},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatCSV, "change,id,column,before,after\nchanged,3,amount,30,35\n"},
+		{FormatJSON, `{"change":"changed","key":{"id":"3"},"columns":[{"column":"amount","before":"30","after":"35"}]}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := NewWriter(&buf, tt.format, []string{"id"})
+			if err != nil {
+				t.Fatalf("NewWriter() error: %v", err)
+			}
+			if err := w.Write(change); err != nil {
+				t.Fatalf("Write() error: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error: %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, "xml", nil); err == nil || !strings.Contains(err.Error(), "xml") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
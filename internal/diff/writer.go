@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zuhrulumam/csv_processor/internal/output"
+)
+
+// Format is a diff output format
+type Format string
+
+const (
+	// FormatCSV writes one row per column change: change, key columns, column, before, after
+	FormatCSV Format = "csv"
+
+	// FormatJSON writes one JSON object per changed row (JSON Lines)
+	FormatJSON Format = "json"
+)
+
+// Writer writes changes in a given format
+type Writer interface {
+	Write(change Change) error
+	Flush() error
+}
+
+// NewWriter creates a Writer for the format
+func NewWriter(w io.Writer, format Format, keyColumns []string) (Writer, error) {
+	switch format {
+	case FormatCSV, "":
+		return &csvWriter{out: output.NewWriter(w), keyColumns: keyColumns}, nil
+	case FormatJSON:
+		return &jsonWriter{enc: json.NewEncoder(w), keyColumns: keyColumns}, nil
+	default:
+		return nil, fmt.Errorf("unknown diff format: %s", format)
+	}
+}
+
+// csvWriter writes changes in long CSV form
+type csvWriter struct {
+	out        *output.Writer
+	keyColumns []string
+}
+
+// Write implements the Writer interface
+func (w *csvWriter) Write(change Change) error {
+	header := append([]string{"change"}, w.keyColumns...)
+	if err := w.out.WriteHeader(append(header, "column", "before", "after")); err != nil {
+		return err
+	}
+
+	for _, column := range change.Columns {
+		row := append([]string{string(change.Type)}, change.Key...)
+		if err := w.out.Write(append(row, column.Column, column.Before, column.After)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush implements the Writer interface
+func (w *csvWriter) Flush() error {
+	return w.out.Flush()
+}
+
+// jsonChange is the JSON form of a Change
+type jsonChange struct {
+	Change  ChangeType        `json:"change"`
+	Key     map[string]string `json:"key"`
+	Row     map[string]string `json:"row,omitempty"`
+	Columns []ColumnChange    `json:"columns,omitempty"`
+}
+
+// jsonWriter writes changes as JSON Lines
+type jsonWriter struct {
+	enc        *json.Encoder
+	keyColumns []string
+}
+
+// Write implements the Writer interface
+func (w *jsonWriter) Write(change Change) error {
+	jc := jsonChange{
+		Change: change.Type,
+		Key:    make(map[string]string, len(w.keyColumns)),
+	}
+
+	for i, column := range w.keyColumns {
+		jc.Key[column] = change.Key[i]
+	}
+
+	switch change.Type {
+	case Changed:
+		jc.Columns = change.Columns
+	default:
+		// Added and removed rows carry the whole row rather than before/after pairs
+		jc.Row = make(map[string]string, len(change.Columns))
+		for _, column := range change.Columns {
+			if change.Type == Added {
+				jc.Row[column.Column] = column.After
+			} else {
+				jc.Row[column.Column] = column.Before
+			}
+		}
+	}
+
+	if err := w.enc.Encode(jc); err != nil {
+		return fmt.Errorf("write change: %w", err)
+	}
+
+	return nil
+}
+
+// Flush implements the Writer interface
+func (w *jsonWriter) Flush() error {
+	return nil
+}
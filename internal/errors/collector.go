@@ -3,6 +3,7 @@ package errors
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -14,7 +15,10 @@ type Collector struct {
 	// errors stores all collected errors
 	errors []ErrorEntry
 
-	// mu protects the errors slice
+	// byCategory counts the collected errors in each category
+	byCategory map[ErrorCategory]int
+
+	// mu protects the errors slice and byCategory
 	mu sync.RWMutex
 
 	// maxErrors is the maximum number of errors to collect (0 = unlimited)
@@ -29,6 +33,10 @@ type Collector struct {
 	// abortOnThreshold indicates whether to abort when threshold is exceeded
 	abortOnThreshold bool
 
+	// logger receives each collected error at debug level, and the error
+	// limit and threshold when they are reached
+	logger *slog.Logger
+
 	// ctx for cancellation
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -76,17 +84,27 @@ type CollectorConfig struct {
 
 	// AbortOnThreshold indicates whether to abort when threshold exceeded
 	AbortOnThreshold bool
+
+	// Logger receives collected errors with their file and line (default:
+	// discard)
+	Logger *slog.Logger
 }
 
 // NewCollector creates a new error collector
 func NewCollector(config CollectorConfig) *Collector {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	if config.Logger == nil {
+		config.Logger = discardLogger
+	}
+
 	return &Collector{
 		errors:           make([]ErrorEntry, 0),
+		byCategory:       make(map[ErrorCategory]int),
 		maxErrors:        config.MaxErrors,
 		errorThreshold:   config.ErrorThreshold,
 		abortOnThreshold: config.AbortOnThreshold,
+		logger:           config.Logger,
 		ctx:              ctx,
 		cancel:           cancel,
 	}
@@ -116,12 +134,16 @@ func (c *Collector) Add(err error, record *models.Record) error {
 		Retryable: isRetryable(err),
 	}
 
-	c.errors = append(c.errors, entry)
+	c.append(entry)
 
 	// Check error threshold
 	if c.abortOnThreshold && c.errorThreshold > 0 {
 		errorRate := c.calculateErrorRate()
 		if errorRate > c.errorThreshold {
+			if c.ctx.Err() == nil {
+				c.logger.Error("error threshold exceeded, aborting",
+					"error_rate", errorRate, "threshold", c.errorThreshold)
+			}
 			c.cancel() // Signal abort
 			return fmt.Errorf("error threshold exceeded: %.1f%% > %.1f%%",
 				errorRate*100, c.errorThreshold*100)
@@ -131,6 +153,11 @@ func (c *Collector) Add(err error, record *models.Record) error {
 	return nil
 }
 
+// Categorize returns the category the collector assigns to err
+func Categorize(err error) ErrorCategory {
+	return categorizeError(err)
+}
+
 // AddWithCategory adds an error with explicit category
 func (c *Collector) AddWithCategory(err error, record *models.Record, category ErrorCategory) error {
 	if err == nil {
@@ -153,11 +180,27 @@ func (c *Collector) AddWithCategory(err error, record *models.Record, category E
 		Retryable: isRetryable(err),
 	}
 
-	c.errors = append(c.errors, entry)
+	c.append(entry)
 
 	return nil
 }
 
+// append stores entry and logs it; the caller holds mu
+func (c *Collector) append(entry ErrorEntry) {
+	c.errors = append(c.errors, entry)
+	c.byCategory[entry.Category]++
+
+	if c.logger.Enabled(context.Background(), slog.LevelDebug) {
+		attrs := append(Attrs(entry.Error, entry.Record),
+			"category", string(entry.Category), "severity", string(entry.Severity))
+		c.logger.Debug("error collected", attrs...)
+	}
+
+	if c.maxErrors > 0 && len(c.errors) == c.maxErrors {
+		c.logger.Warn("error limit reached, further errors are not collected", "max_errors", c.maxErrors)
+	}
+}
+
 // IncrementProcessed increments the total processed count
 func (c *Collector) IncrementProcessed() {
 	c.mu.Lock()
@@ -200,6 +243,32 @@ func (c *Collector) ErrorsByCategory() map[ErrorCategory][]ErrorEntry {
 	return grouped
 }
 
+// CountByCategory returns the number of collected errors in each category,
+// without walking the errors
+func (c *Collector) CountByCategory() map[ErrorCategory]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[ErrorCategory]int, len(c.byCategory))
+	for category, n := range c.byCategory {
+		counts[category] = n
+	}
+
+	return counts
+}
+
+// Recent returns up to n of the most recently collected errors, oldest first
+func (c *Collector) Recent(n int) []ErrorEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	start := max(len(c.errors)-n, 0)
+	recent := make([]ErrorEntry, len(c.errors)-start)
+	copy(recent, c.errors[start:])
+
+	return recent
+}
+
 // ErrorsBySeverity returns errors grouped by severity
 func (c *Collector) ErrorsBySeverity() map[ErrorSeverity][]ErrorEntry {
 	c.mu.RLock()
@@ -261,6 +330,7 @@ func (c *Collector) Clear() {
 	defer c.mu.Unlock()
 
 	c.errors = make([]ErrorEntry, 0)
+	c.byCategory = make(map[ErrorCategory]int)
 	c.totalProcessed = 0
 }
 
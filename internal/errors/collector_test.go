@@ -117,6 +117,39 @@ func TestCollector_ErrorsByCategory(t *testing.T) {
 	}
 }
 
+func TestCollector_CountByCategoryAndRecent(t *testing.T) {
+	collector := NewCollector(CollectorConfig{})
+
+	record := models.NewRecord(1, "test.csv", []string{"data"}, nil)
+
+	_ = collector.AddWithCategory(ErrInvalidRecord, record, CategoryValidation)
+	for i := 0; i < 4; i++ {
+		_ = collector.AddWithCategory(fmt.Errorf("error %d", i), record, CategoryProcessing)
+	}
+
+	counts := collector.CountByCategory()
+	if counts[CategoryValidation] != 1 || counts[CategoryProcessing] != 4 {
+		t.Errorf("expected 1 validation and 4 processing errors, got %v", counts)
+	}
+
+	recent := collector.Recent(2)
+	if len(recent) != 2 {
+		t.Fatalf("expected 2 recent errors, got %d", len(recent))
+	}
+	if recent[0].Error.Error() != "error 2" || recent[1].Error.Error() != "error 3" {
+		t.Errorf("expected error 2 and error 3, got %v and %v", recent[0].Error, recent[1].Error)
+	}
+
+	if got := len(collector.Recent(10)); got != 5 {
+		t.Errorf("expected all 5 errors, got %d", got)
+	}
+
+	collector.Clear()
+	if len(collector.CountByCategory()) != 0 || len(collector.Recent(2)) != 0 {
+		t.Error("expected no errors after clear")
+	}
+}
+
 func TestCollector_ErrorsBySeverity(t *testing.T) {
 	collector := NewCollector(CollectorConfig{})
 
@@ -33,6 +33,9 @@ var (
 
 	// ErrMaxErrorsExceeded indicates too many errors occurred
 	ErrMaxErrorsExceeded = errors.New("maximum error threshold exceeded")
+
+	// ErrPipelineAlreadyRun indicates Run was called on a pipeline that already ran
+	ErrPipelineAlreadyRun = errors.New("pipeline already run; create a new pipeline for each run")
 )
 
 // ProcessingError wraps errors with additional context
@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+// discardLogger is used when no logger is configured
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// Attrs returns log attributes for err with the file and line it occurred
+// at, taken from record or else from a ProcessingError
+func Attrs(err error, record *models.Record) []any {
+	attrs := []any{"error", err.Error()}
+
+	var perr *ProcessingError
+	switch {
+	case record != nil:
+		attrs = append(attrs, "file", record.FileName, "line", record.LineNumber)
+	case errors.As(err, &perr) && perr.FileName != "":
+		attrs = append(attrs, "file", perr.FileName)
+		if perr.LineNumber > 0 {
+			attrs = append(attrs, "line", perr.LineNumber)
+		}
+	}
+
+	return attrs
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+// decodeLogs parses JSON log lines written by a slog JSON handler
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestAttrs(t *testing.T) {
+	record := models.NewRecord(7, "orders.csv", []string{"data"}, nil)
+
+	tests := []struct {
+		name   string
+		err    error
+		record *models.Record
+		want   string
+	}{
+		{"record", errors.New("bad value"), record, "[error bad value file orders.csv line 7]"},
+		{"processing error", NewProcessingError("read_record", "items.csv", 12, ErrInvalidCSV), nil,
+			"[error read_record: items.csv:12: invalid CSV format file items.csv line 12]"},
+		{"file-level error", fmt.Errorf("read: %w", NewProcessingError("read", "empty.csv", 0, ErrEmptyFile)), nil,
+			"[error read: read: empty.csv: empty file file empty.csv]"},
+		{"no context", errors.New("boom"), nil, "[error boom]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(Attrs(tt.err, tt.record)); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCollector_Logger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	collector := NewCollector(CollectorConfig{MaxErrors: 2, Logger: logger})
+
+	record := models.NewRecord(3, "test.csv", []string{"data"}, nil)
+	for i := 0; i < 3; i++ {
+		_ = collector.AddWithCategory(fmt.Errorf("error %d", i), record, CategoryValidation)
+	}
+
+	lines := decodeLogs(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 collected errors and the limit, got %d lines:\n%s", len(lines), buf.String())
+	}
+
+	first := lines[0]
+	if first["msg"] != "error collected" || first["file"] != "test.csv" || first["line"] != 3.0 ||
+		first["category"] != "VALIDATION" || first["error"] != "error 0" {
+		t.Errorf("unexpected collected error line: %v", first)
+	}
+
+	if lines[2]["level"] != "WARN" || lines[2]["max_errors"] != 2.0 {
+		t.Errorf("expected error limit warning, got %v", lines[2])
+	}
+}
+
+func TestReporter_Log(t *testing.T) {
+	collector := NewCollector(CollectorConfig{})
+
+	for i := 0; i < 3; i++ {
+		record := models.NewRecord(i+2, "test.csv", []string{"data"}, nil)
+		_ = collector.AddWithCategory(errors.New("common"), record, CategoryValidation)
+	}
+	_ = collector.Add(NewProcessingError("read", "other.csv", 0, ErrEmptyFile), nil)
+
+	buf := &bytes.Buffer{}
+	NewReporter(collector, nil).Log(slog.New(slog.NewJSONHandler(buf, nil)), 5)
+
+	lines := decodeLogs(t, buf)
+	if len(lines) != 3 {
+		t.Fatalf("expected summary and 2 common errors, got %d lines:\n%s", len(lines), buf.String())
+	}
+
+	summary := lines[0]
+	byCategory, _ := summary["by_category"].(map[string]any)
+	if summary["msg"] != "error summary" || summary["errors"] != 4.0 || byCategory["VALIDATION"] != 3.0 {
+		t.Errorf("unexpected summary line: %v", summary)
+	}
+
+	// Most common first, with the file and line of its first occurrence
+	if lines[1]["error"] != "common" || lines[1]["count"] != 3.0 || lines[1]["line"] != 2.0 {
+		t.Errorf("unexpected most common error line: %v", lines[1])
+	}
+	if lines[2]["file"] != "other.csv" || lines[2]["count"] != 1.0 {
+		t.Errorf("unexpected second error line: %v", lines[2])
+	}
+}
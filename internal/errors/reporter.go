@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"sort"
 	"strings"
 	"time"
@@ -103,59 +104,86 @@ func (r *Reporter) PrintDetailed(maxErrors int) {
 
 // PrintTopErrors prints the most common errors
 func (r *Reporter) PrintTopErrors(topN int) {
-	errors := r.collector.Errors()
+	top := r.topErrors(topN)
 
-	if len(errors) == 0 {
+	if len(top) == 0 {
 		return
 	}
 
-	// Group errors by message
-	errorCounts := make(map[string]int)
-	errorExamples := make(map[string]ErrorEntry)
+	fmt.Fprintf(r.writer, "\n")
+	fmt.Fprintf(r.writer, "========================================\n")
+	fmt.Fprintf(r.writer, "Top %d Most Common Errors\n", len(top))
+	fmt.Fprintf(r.writer, "========================================\n")
 
-	for _, entry := range errors {
-		msg := entry.Error.Error()
-		errorCounts[msg]++
-		if _, exists := errorExamples[msg]; !exists {
-			errorExamples[msg] = entry
-		}
+	for i, item := range top {
+		fmt.Fprintf(r.writer, "\n%d. (%d occurrences)\n", i+1, item.count)
+		fmt.Fprintf(r.writer, "   Category: %s\n", item.example.Category)
+		fmt.Fprintf(r.writer, "   Message:  %s\n", truncateString(item.message, 100))
 	}
 
-	// Sort by count
-	type errorCount struct {
-		message string
-		count   int
-	}
+	fmt.Fprintf(r.writer, "\n========================================\n")
+}
 
-	var sorted []errorCount
-	for msg, count := range errorCounts {
-		sorted = append(sorted, errorCount{msg, count})
-	}
+// Log writes the error summary and the topN most common errors to logger as
+// records, for machine-readable logs in place of the printed report
+func (r *Reporter) Log(logger *slog.Logger, topN int) {
+	summary := r.collector.Summary()
 
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].count > sorted[j].count
-	})
+	byCategory := make([]any, 0, len(summary.ByCategory))
+	for category, count := range summary.ByCategory {
+		byCategory = append(byCategory, slog.Int(string(category), count))
+	}
 
-	// Print top N
-	if topN > len(sorted) {
-		topN = len(sorted)
+	logger.Warn("error summary",
+		"errors", summary.TotalErrors,
+		"processed", summary.TotalProcessed,
+		"error_rate", summary.ErrorRate,
+		"retryable", summary.RetryableErrors,
+		slog.Group("by_category", byCategory...),
+	)
+
+	for _, item := range r.topErrors(topN) {
+		attrs := append(Attrs(item.example.Error, item.example.Record),
+			"category", string(item.example.Category), "count", item.count)
+		logger.Warn("common error", attrs...)
 	}
+}
 
-	fmt.Fprintf(r.writer, "\n")
-	fmt.Fprintf(r.writer, "========================================\n")
-	fmt.Fprintf(r.writer, "Top %d Most Common Errors\n", topN)
-	fmt.Fprintf(r.writer, "========================================\n")
+// errorCount is a distinct error message, how often it occurred and its
+// first occurrence
+type errorCount struct {
+	message string
+	count   int
+	example ErrorEntry
+}
 
-	for i := 0; i < topN; i++ {
-		item := sorted[i]
-		example := errorExamples[item.message]
+// topErrors groups errors by message and returns the topN most common
+func (r *Reporter) topErrors(topN int) []errorCount {
+	counts := make(map[string]*errorCount)
+	var sorted []*errorCount
 
-		fmt.Fprintf(r.writer, "\n%d. (%d occurrences)\n", i+1, item.count)
-		fmt.Fprintf(r.writer, "   Category: %s\n", example.Category)
-		fmt.Fprintf(r.writer, "   Message:  %s\n", truncateString(item.message, 100))
+	for _, entry := range r.collector.Errors() {
+		msg := entry.Error.Error()
+		if item, exists := counts[msg]; exists {
+			item.count++
+			continue
+		}
+		item := &errorCount{message: msg, count: 1, example: entry}
+		counts[msg] = item
+		sorted = append(sorted, item)
 	}
 
-	fmt.Fprintf(r.writer, "\n========================================\n")
+	// Sort by count, first seen first among equals
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].count > sorted[j].count
+	})
+
+	n := max(min(topN, len(sorted)), 0)
+	top := make([]errorCount, 0, n)
+	for _, item := range sorted[:n] {
+		top = append(top, *item)
+	}
+	return top
 }
 
 // ExportToFile exports errors to a file
@@ -0,0 +1,434 @@
+package jobconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values maps option names to their values. Scalar options have one value;
+// repeatable options have one value per occurrence.
+type Values map[string][]string
+
+// Keys returns the option names in sorted order
+func (v Values) Keys() []string {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NormalizeKey maps a key to the option name it sets, so error_threshold,
+// Error-Threshold and error-threshold are the same option
+func NormalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "_", "-")
+}
+
+// Load reads a config file, choosing the format from its extension:
+// .json, .yaml/.yml or .toml
+func Load(path string) (Values, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var values Values
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err = ParseJSON(data)
+	case ".yaml", ".yml":
+		values, err = ParseYAML(data)
+	case ".toml":
+		values, err = ParseTOML(data)
+	default:
+		return nil, fmt.Errorf("config %s: unsupported extension %q (want .json, .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// ParseJSON parses a JSON object whose members are scalars or arrays of scalars
+func ParseJSON(data []byte) (Values, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+
+	values := make(Values, len(raw))
+	for key, value := range raw {
+		name := NormalizeKey(key)
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("duplicate option %q", key)
+		}
+
+		// null leaves the option at its default
+		if value == nil {
+			continue
+		}
+
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				s, err := jsonScalar(key, item)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, s)
+			}
+			values[name] = items
+			continue
+		}
+
+		s, err := jsonScalar(key, value)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = []string{s}
+	}
+
+	return values, nil
+}
+
+// jsonScalar formats a decoded JSON scalar as an option value
+func jsonScalar(key string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("option %q: nested objects and arrays are not supported", key)
+	}
+}
+
+// ParseYAML parses the subset of YAML used by flat job files: top-level
+// "key: value" pairs, block lists of "- item" lines, flow lists like [a, b],
+// quoted scalars and # comments. Nested mappings are rejected.
+func ParseYAML(data []byte) (Values, error) {
+	values := make(Values)
+
+	// listKey is the key whose block list items follow, if any
+	var listKey string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNum := i + 1
+
+		line = strings.TrimRight(stripComment(line), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listKey == "" {
+				return nil, fmt.Errorf("line %d: list item without a key", lineNum)
+			}
+			item, err := parseScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			values[listKey] = append(values[listKey], item)
+			continue
+		}
+
+		if indented {
+			return nil, fmt.Errorf("line %d: nested mappings are not supported", lineNum)
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNum)
+		}
+
+		name := NormalizeKey(key)
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate option %q", lineNum, strings.TrimSpace(key))
+		}
+
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		if value == "" {
+			// A block list may follow; otherwise the option keeps its default
+			values[name] = []string{}
+			listKey = name
+			continue
+		}
+
+		items, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		values[name] = items
+	}
+
+	return values, nil
+}
+
+// ParseTOML parses the subset of TOML used by flat job files: top-level
+// "key = value" pairs with strings, numbers, booleans and arrays, which may
+// span several lines. Tables are rejected.
+func ParseTOML(data []byte) (Values, error) {
+	values := make(Values)
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+
+		trimmed := strings.TrimSpace(stripComment(lines[i]))
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", lineNum)
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", lineNum)
+		}
+
+		name := NormalizeKey(strings.Trim(strings.TrimSpace(key), `"'`))
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate option %q", lineNum, strings.TrimSpace(key))
+		}
+
+		value = strings.TrimSpace(value)
+
+		// Arrays may continue until the closing bracket
+		if strings.HasPrefix(value, "[") {
+			for !closedList(value) {
+				i++
+				if i >= len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated array", lineNum)
+				}
+				value += " " + strings.TrimSpace(stripComment(lines[i]))
+			}
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("line %d: missing value for %q", lineNum, strings.TrimSpace(key))
+		}
+
+		items, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		values[name] = items
+	}
+
+	return values, nil
+}
+
+// FromEnv collects options from environment variables named prefix followed
+// by the upper-cased option name with dashes as underscores, e.g.
+// CSVPROC_ERROR_THRESHOLD. Repeatable options separate their values with ";".
+// Variables listed in skip are ignored.
+func FromEnv(prefix string, environ []string, skip ...string) Values {
+	values := make(Values)
+
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if contains(skip, key) {
+			continue
+		}
+
+		name := NormalizeKey(strings.TrimPrefix(key, prefix))
+		if name == "" {
+			continue
+		}
+
+		values[name] = []string{value}
+	}
+
+	return values
+}
+
+// EnvName returns the environment variable that sets an option
+func EnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// SplitList splits a repeatable option's environment value on ";"
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseValue parses a scalar or a flow list
+func parseValue(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") {
+		item, err := parseScalar(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{item}, nil
+	}
+
+	if !closedList(value) || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated list %s", value)
+	}
+
+	items := []string{}
+	for _, raw := range splitList(value[1 : len(value)-1]) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			// Trailing comma
+			continue
+		}
+		if strings.HasPrefix(raw, "[") {
+			return nil, fmt.Errorf("nested lists are not supported")
+		}
+		item, err := parseScalar(raw)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// parseScalar unquotes a quoted scalar; anything else is taken as written
+func parseScalar(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch value[0] {
+	case '"':
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		return s, nil
+	case '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		// A doubled quote is an escaped quote in YAML single-quoted strings
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	case '{':
+		return "", fmt.Errorf("nested mappings are not supported")
+	}
+
+	return value, nil
+}
+
+// splitList splits the inside of a flow list on commas outside quotes
+func splitList(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case opensQuote(s, i):
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, s[start:])
+}
+
+// closedList reports whether a list's closing bracket, outside quotes, has been seen
+func closedList(s string) bool {
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case opensQuote(s, i):
+			quote = c
+		case c == ']':
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripComment removes a # comment that starts the line or follows
+// whitespace, outside quotes
+func stripComment(line string) string {
+	var quote byte
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case opensQuote(line, i):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// opensQuote reports whether s[i] starts a quoted scalar. A quote inside a
+// plain scalar, as in O'Brien, is taken as written.
+func opensQuote(s string, i int) bool {
+	if s[i] != '"' && s[i] != '\'' {
+		return false
+	}
+	return i == 0 || strings.IndexByte(" \t:=[,", s[i-1]) >= 0
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
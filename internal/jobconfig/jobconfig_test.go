@@ -0,0 +1,190 @@
+package jobconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	want := Values{
+		"workers":         {"8"},
+		"error-threshold": {"0.05"},
+		"header":          {"false"},
+		"output":          {"out #1.csv"},
+		"enrich":          {"merchants.csv:merchant_id=id:name,city", "fx.csv:currency"},
+		"inputs":          {"a.csv", "b.csv"},
+	}
+
+	tests := []struct {
+		name  string
+		parse func([]byte) (Values, error)
+		input string
+	}{
+		{
+			name:  "json",
+			parse: ParseJSON,
+			input: `{
+  "workers": 8,
+  "error_threshold": 0.05,
+  "header": false,
+  "output": "out #1.csv",
+  "enrich": ["merchants.csv:merchant_id=id:name,city", "fx.csv:currency"],
+  "inputs": ["a.csv", "b.csv"],
+  "resume": null
+}`,
+		},
+		{
+			name:  "yaml",
+			parse: ParseYAML,
+			input: `# nightly job
+---
+workers: 8
+error_threshold: 0.05   # five percent
+header: false
+output: "out #1.csv"
+enrich:
+  - merchants.csv:merchant_id=id:name,city
+  - 'fx.csv:currency'
+inputs: [a.csv, "b.csv"]
+`,
+		},
+		{
+			name:  "toml",
+			parse: ParseTOML,
+			input: `# nightly job
+workers = 8
+error_threshold = 0.05 # five percent
+header = false
+output = "out #1.csv"
+enrich = [
+  "merchants.csv:merchant_id=id:name,city", # names
+  'fx.csv:currency',
+]
+inputs = ["a.csv", "b.csv"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestParse_Apostrophe(t *testing.T) {
+	// An apostrophe inside a plain scalar does not start a quoted string
+	input := `output: O'Brien's.csv # note
+inputs: [O'Brien.csv, 'it''s.csv'] # files
+`
+
+	got, err := ParseYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	want := Values{
+		"output": {"O'Brien's.csv"},
+		"inputs": {"O'Brien.csv", "it's.csv"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) (Values, error)
+		input string
+	}{
+		{"json nested object", ParseJSON, `{"schema": {"id": "int"}}`},
+		{"json nested array", ParseJSON, `{"inputs": [["a.csv"]]}`},
+		{"json duplicate", ParseJSON, `{"error_threshold": 0.1, "error-threshold": 0.2}`},
+		{"json invalid", ParseJSON, `{"workers": }`},
+		{"yaml nested mapping", ParseYAML, "dialect:\n  delimiter: ';'\n"},
+		{"yaml item without key", ParseYAML, "- a.csv\n"},
+		{"yaml missing colon", ParseYAML, "workers 8\n"},
+		{"yaml duplicate", ParseYAML, "workers: 8\nworkers: 4\n"},
+		{"yaml unterminated list", ParseYAML, "inputs: [a.csv, b.csv\n"},
+		{"toml table", ParseTOML, "[dialect]\ndelimiter = ';'\n"},
+		{"toml missing value", ParseTOML, "workers =\n"},
+		{"toml unterminated array", ParseTOML, "inputs = [\"a.csv\",\n"},
+		{"toml bad string", ParseTOML, "output = \"out.csv\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.parse([]byte(tt.input)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path := filepath.Join(tmpDir, "job.yml")
+	if err := os.WriteFile(path, []byte("workers: 4\n"), 0644); err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+
+	values, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := values["workers"]; !reflect.DeepEqual(got, []string{"4"}) {
+		t.Errorf("expected workers [4], got %v", got)
+	}
+
+	ini := filepath.Join(tmpDir, "job.ini")
+	if err := os.WriteFile(ini, []byte("workers=4\n"), 0644); err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+	if _, err := Load(ini); err == nil {
+		t.Error("expected error for unsupported extension")
+	}
+
+	if _, err := Load(filepath.Join(tmpDir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/root",
+		"CSVPROC_WORKERS=16",
+		"CSVPROC_ERROR_THRESHOLD=0.1",
+		"CSVPROC_ENRICH_DEFAULT=",
+		"CSVPROC_CONFIG=job.yaml",
+	}
+
+	got := FromEnv("CSVPROC_", environ, "CSVPROC_CONFIG")
+	want := Values{
+		"workers":         {"16"},
+		"error-threshold": {"0.1"},
+		"enrich-default":  {""},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if name := EnvName("CSVPROC_", "checkpoint-interval"); name != "CSVPROC_CHECKPOINT_INTERVAL" {
+		t.Errorf("expected CSVPROC_CHECKPOINT_INTERVAL, got %s", name)
+	}
+
+	if items := SplitList("a.csv; b.csv;;"); !reflect.DeepEqual(items, []string{"a.csv", "b.csv"}) {
+		t.Errorf("expected [a.csv b.csv], got %v", items)
+	}
+}
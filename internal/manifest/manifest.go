@@ -0,0 +1,210 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of processing a file
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+)
+
+// Fingerprint identifies a version of a file
+type Fingerprint struct {
+	// Path is the absolute file path
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	// Hash is the hex SHA-256 of the content; empty until computed
+	Hash string `json:"hash,omitempty"`
+}
+
+// Entry records the outcome of processing one file
+type Entry struct {
+	Fingerprint
+
+	Status      Status        `json:"status"`
+	Records     int           `json:"records"`
+	Succeeded   int           `json:"succeeded"`
+	Failed      int           `json:"failed"`
+	Errors      int           `json:"errors"`
+	Error       string        `json:"error,omitempty"`
+	ProcessedAt time.Time     `json:"processed_at"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// Manifest is a JSON store of processed files, keyed by absolute path
+type Manifest struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// Load reads the manifest at path; a missing file yields an empty manifest
+func Load(path string) (*Manifest, error) {
+	m := &Manifest{
+		path:    path,
+		entries: make(map[string]*Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+
+	for _, entry := range entries {
+		m.entries[entry.Path] = entry
+	}
+
+	return m, nil
+}
+
+// Stat returns the fingerprint of a file without hashing its content
+func Stat(file string) (Fingerprint, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("resolve path: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	return Fingerprint{Path: path, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// HashFile returns the hex SHA-256 of a file's content
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Processed reports whether this version of the file was processed successfully.
+// A matching size and mtime is trusted without hashing; when only the mtime
+// differs, the content hash decides, so touched but unchanged files are skipped.
+func (m *Manifest) Processed(fp *Fingerprint) (bool, error) {
+	m.mu.Lock()
+	entry, ok := m.entries[fp.Path]
+	m.mu.Unlock()
+
+	if !ok || entry.Status != StatusSuccess || entry.Size != fp.Size {
+		return false, nil
+	}
+
+	if entry.ModTime.Equal(fp.ModTime) {
+		fp.Hash = entry.Hash
+		return true, nil
+	}
+
+	if fp.Hash == "" {
+		hash, err := HashFile(fp.Path)
+		if err != nil {
+			return false, err
+		}
+		fp.Hash = hash
+	}
+
+	if fp.Hash != entry.Hash {
+		return false, nil
+	}
+
+	// Remember the new mtime so the next check does not hash again
+	m.mu.Lock()
+	entry.ModTime = fp.ModTime
+	m.mu.Unlock()
+
+	return true, nil
+}
+
+// Record stores the outcome for a file, replacing any previous entry
+func (m *Manifest) Record(entry Entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[entry.Path] = &entry
+}
+
+// Entry returns the recorded entry for a path
+func (m *Manifest) Entry(path string) (Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[path]
+	if !ok {
+		return Entry{}, false
+	}
+	return *entry, true
+}
+
+// Save writes the manifest, replacing the previous file atomically
+func (m *Manifest) Save() error {
+	m.mu.Lock()
+	entries := make([]*Entry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close manifest: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("replace manifest: %w", err)
+	}
+
+	return nil
+}
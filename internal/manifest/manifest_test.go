@@ -0,0 +1,117 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifest_Processed(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	manifestPath := filepath.Join(tmpDir, "manifest.json")
+	m, err := Load(manifestPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	fp, err := Stat(file)
+	if err != nil {
+		t.Fatalf("Stat() error: %v", err)
+	}
+
+	if done, _ := m.Processed(&fp); done {
+		t.Fatal("expected unknown file to be pending")
+	}
+
+	fp.Hash, _ = HashFile(fp.Path)
+	m.Record(Entry{Fingerprint: fp, Status: StatusSuccess, Records: 1, Succeeded: 1})
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	m, err = Load(manifestPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func()
+		want   bool
+	}{
+		{
+			name:   "unchanged",
+			modify: func() {},
+			want:   true,
+		},
+		{
+			name: "touched with same content",
+			modify: func() {
+				later := time.Now().Add(time.Hour)
+				os.Chtimes(file, later, later)
+			},
+			want: true,
+		},
+		{
+			name: "same size different content",
+			modify: func() {
+				os.WriteFile(file, []byte("id\n2\n"), 0644)
+			},
+			want: false,
+		},
+		{
+			name: "appended",
+			modify: func() {
+				os.WriteFile(file, []byte("id\n1\n2\n"), 0644)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.modify()
+
+			fp, err := Stat(file)
+			if err != nil {
+				t.Fatalf("Stat() error: %v", err)
+			}
+
+			done, err := m.Processed(&fp)
+			if err != nil {
+				t.Fatalf("Processed() error: %v", err)
+			}
+			if done != tt.want {
+				t.Errorf("expected processed = %v, got %v", tt.want, done)
+			}
+		})
+	}
+}
+
+func TestManifest_FailedFilesAreRetried(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	os.WriteFile(file, []byte("id\n1\n"), 0644)
+
+	m, _ := Load(filepath.Join(tmpDir, "manifest.json"))
+
+	fp, _ := Stat(file)
+	m.Record(Entry{Fingerprint: fp, Status: StatusFailed, Errors: 1})
+
+	if done, _ := m.Processed(&fp); done {
+		t.Error("expected failed file to be pending")
+	}
+
+	entry, ok := m.Entry(fp.Path)
+	if !ok || entry.Errors != 1 {
+		t.Errorf("expected recorded entry with 1 error, got %+v", entry)
+	}
+}
@@ -0,0 +1,367 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// metricType is the Prometheus metric type
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// DefaultLatencyBuckets are histogram buckets in seconds for per-record latency
+var DefaultLatencyBuckets = []float64{
+	0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5,
+}
+
+// Registry holds metrics and writes them in the Prometheus text format.
+// Registering a name again returns the existing metric, so pipelines run one
+// after another can share a registry and keep accumulating.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*family
+}
+
+// family is one metric name with its children, one per label value set
+type family struct {
+	name    string
+	help    string
+	typ     metricType
+	labels  []string
+	buckets []float64
+
+	mu       sync.Mutex
+	children map[string]*child
+
+	// fn computes a gauge's value at scrape time
+	fn func() float64
+}
+
+// child is the value of a family for one set of label values
+type child struct {
+	values []string
+
+	// bits holds the float64 value of counters and gauges
+	bits uint64
+
+	// Histograms count observations per bucket, plus a sum and total
+	counts []uint64
+	sum    uint64
+	count  uint64
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*family)}
+}
+
+// register returns the family for name, creating it if needed
+func (r *Registry) register(name, help string, typ metricType, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.metrics[name]; ok {
+		if f.typ != typ || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("metrics: %s registered again with a different type or labels", name))
+		}
+		return f
+	}
+
+	f := &family{
+		name:     name,
+		help:     help,
+		typ:      typ,
+		labels:   labels,
+		buckets:  buckets,
+		children: make(map[string]*child),
+	}
+	r.metrics[name] = f
+	return f
+}
+
+// with returns the child for the label values, creating it if needed
+func (f *family) with(values []string) *child {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.children[key]
+	if !ok {
+		c = &child{values: append([]string(nil), values...)}
+		if f.typ == typeHistogram {
+			c.counts = make([]uint64, len(f.buckets))
+		}
+		f.children[key] = c
+	}
+	return c
+}
+
+// add adds delta to a float64 stored as bits
+func add(bits *uint64, delta float64) {
+	for {
+		old := atomic.LoadUint64(bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(bits, old, next) {
+			return
+		}
+	}
+}
+
+// CounterVec is a counter with labels
+type CounterVec struct {
+	family *family
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	child *child
+}
+
+// Counter registers a counter; names should end in _total
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{family: r.register(name, help, typeCounter, nil, labels)}
+}
+
+// With returns the counter for the label values
+func (v *CounterVec) With(values ...string) *Counter {
+	return &Counter{child: v.family.with(values)}
+}
+
+// Inc adds 1
+func (c *Counter) Inc() {
+	add(&c.child.bits, 1)
+}
+
+// Add adds delta, which must not be negative
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	add(&c.child.bits, delta)
+}
+
+// Value returns the current value
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&c.child.bits))
+}
+
+// GaugeVec is a gauge with labels
+type GaugeVec struct {
+	family *family
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	child *child
+}
+
+// Gauge registers a gauge
+func (r *Registry) Gauge(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{family: r.register(name, help, typeGauge, nil, labels)}
+}
+
+// With returns the gauge for the label values
+func (v *GaugeVec) With(values ...string) *Gauge {
+	return &Gauge{child: v.family.with(values)}
+}
+
+// Set sets the value
+func (g *Gauge) Set(value float64) {
+	atomic.StoreUint64(&g.child.bits, math.Float64bits(value))
+}
+
+// Add adds delta, which may be negative
+func (g *Gauge) Add(delta float64) {
+	add(&g.child.bits, delta)
+}
+
+// Value returns the current value
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.child.bits))
+}
+
+// GaugeFunc registers a gauge without labels whose value is computed by fn at
+// scrape time. Registering the name again replaces fn.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	f := r.register(name, help, typeGauge, nil, nil)
+
+	f.mu.Lock()
+	f.fn = fn
+	f.mu.Unlock()
+}
+
+// HistogramVec is a histogram with labels
+type HistogramVec struct {
+	family *family
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	family *family
+	child  *child
+}
+
+// Histogram registers a histogram with the given upper bounds, which must be sorted
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: %s buckets are not sorted", name))
+	}
+	return &HistogramVec{family: r.register(name, help, typeHistogram, buckets, labels)}
+}
+
+// With returns the histogram for the label values
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return &Histogram{family: v.family, child: v.family.with(values)}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(value float64) {
+	// Buckets are cumulative when written; count only the first that fits here
+	i := sort.SearchFloat64s(h.family.buckets, value)
+	if i < len(h.child.counts) {
+		atomic.AddUint64(&h.child.counts[i], 1)
+	}
+	add(&h.child.sum, value)
+	atomic.AddUint64(&h.child.count, 1)
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	return atomic.LoadUint64(&h.child.count)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]*family, 0, len(r.metrics))
+	for _, f := range r.metrics {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// write writes one family
+func (f *family) write(w *bufio.Writer) {
+	f.mu.Lock()
+	fn := f.fn
+	children := make([]*child, 0, len(f.children))
+	for _, c := range f.children {
+		children = append(children, c)
+	}
+	f.mu.Unlock()
+
+	if fn == nil && len(children) == 0 {
+		return
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return strings.Join(children[i].values, "\xff") < strings.Join(children[j].values, "\xff")
+	})
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	if fn != nil {
+		fmt.Fprintf(w, "%s %s\n", f.name, formatValue(fn()))
+		return
+	}
+
+	for _, c := range children {
+		labels := labelPairs(f.labels, c.values)
+
+		if f.typ != typeHistogram {
+			value := math.Float64frombits(atomic.LoadUint64(&c.bits))
+			fmt.Fprintf(w, "%s%s %s\n", f.name, braces(labels), formatValue(value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += atomic.LoadUint64(&c.counts[i])
+			le := append(labels[:len(labels):len(labels)], `le="`+formatValue(upper)+`"`)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, braces(le), cumulative)
+		}
+
+		count := atomic.LoadUint64(&c.count)
+		sum := math.Float64frombits(atomic.LoadUint64(&c.sum))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, braces(append(labels[:len(labels):len(labels)], `le="+Inf"`)), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, braces(labels), formatValue(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, braces(labels), count)
+	}
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// labelPairs formats label names and values as name="value" pairs
+func labelPairs(names, values []string) []string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return pairs
+}
+
+// braces wraps label pairs in {}, or returns "" when there are none
+func braces(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue formats a sample value
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeHelp escapes backslashes and newlines in help text
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+// escapeLabel escapes backslashes, quotes and newlines in label values
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	records := r.Counter("records_total", "Records by file.", "file", "status")
+	records.With("a.csv", "success").Add(3)
+	records.With("a.csv", "failed").Inc()
+	records.With(`we"ird\.csv`, "success").Inc()
+
+	r.Gauge("queue_depth", "Queued items.", "queue").With("records").Set(7)
+	r.GaugeFunc("active_workers", "Busy workers.", func() float64 { return 2 })
+
+	latency := r.Histogram("latency_seconds", "Latency.\nIn seconds.", []float64{0.1, 1})
+	for _, v := range []float64{0.05, 0.1, 0.5, 2} {
+		latency.With().Observe(v)
+	}
+
+	// Registered but unused families are left out
+	r.Counter("unused_total", "Never incremented.", "file")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+
+	want := `# HELP active_workers Busy workers.
+# TYPE active_workers gauge
+active_workers 2
+# HELP latency_seconds Latency.\nIn seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 2
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 2.65
+latency_seconds_count 4
+# HELP queue_depth Queued items.
+# TYPE queue_depth gauge
+queue_depth{queue="records"} 7
+# HELP records_total Records by file.
+# TYPE records_total counter
+records_total{file="a.csv",status="failed"} 1
+records_total{file="a.csv",status="success"} 3
+records_total{file="we\"ird\\.csv",status="success"} 1
+`
+
+	if got := b.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRegistry_Reregister(t *testing.T) {
+	r := NewRegistry()
+
+	r.Counter("runs_total", "Runs.").With().Inc()
+	r.Counter("runs_total", "Runs.").With().Inc()
+
+	if got := r.Counter("runs_total", "Runs.").With().Value(); got != 2 {
+		t.Errorf("expected 2, got %v", got)
+	}
+
+	r.GaugeFunc("depth", "Depth.", func() float64 { return 1 })
+	r.GaugeFunc("depth", "Depth.", func() float64 { return 5 })
+
+	var b strings.Builder
+	r.WriteText(&b)
+	if !strings.Contains(b.String(), "depth 5\n") {
+		t.Errorf("expected replaced gauge func, got:\n%s", b.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for type mismatch")
+		}
+	}()
+	r.Gauge("runs_total", "Runs.")
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := NewRegistry()
+	counter := r.Counter("events_total", "Events.", "kind")
+	latency := r.Histogram("latency_seconds", "Latency.", DefaultLatencyBuckets)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.With("a").Inc()
+				latency.With().Observe(0.001)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counter.With("a").Value(); got != 8000 {
+		t.Errorf("expected 8000, got %v", got)
+	}
+	if got := latency.With().Count(); got != 8000 {
+		t.Errorf("expected 8000 observations, got %d", got)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("hits_total", "Hits.").With().Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("expected Prometheus content type, got %s", ct)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), "hits_total 1\n") {
+		t.Errorf("expected hits_total 1, got:\n%s", body)
+	}
+}
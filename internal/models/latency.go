@@ -0,0 +1,278 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Latencies are bucketed like an HDR histogram: each power of two is split
+// into latencySubBuckets linear buckets, so any recorded value is known to
+// within 1/latencySubBuckets (6.25%) in a fixed amount of memory.
+const (
+	latencySubBits    = 4
+	latencySubBuckets = 1 << latencySubBits
+
+	// A time.Duration is below 2^63ns, which needs 63-latencySubBits+1 ranges
+	latencyBuckets = (63 - latencySubBits + 1) * latencySubBuckets
+)
+
+// LatencyHistogram counts durations in log-linear buckets (thread-safe)
+type LatencyHistogram struct {
+	counts [latencyBuckets]uint64
+	count  uint64
+	sum    uint64
+	max    int64
+}
+
+// NewLatencyHistogram creates an empty LatencyHistogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{}
+}
+
+// latencyBucket returns the bucket index for a duration in nanoseconds
+func latencyBucket(ns uint64) int {
+	if ns < latencySubBuckets {
+		return int(ns)
+	}
+
+	shift := bits.Len64(ns) - 1 - latencySubBits
+	return (shift+1)*latencySubBuckets + int(ns>>shift) - latencySubBuckets
+}
+
+// latencyBucketMax returns the largest duration in nanoseconds in bucket i
+func latencyBucketMax(i int) uint64 {
+	if i < latencySubBuckets {
+		return uint64(i)
+	}
+
+	shift := i/latencySubBuckets - 1
+	sub := uint64(i % latencySubBuckets)
+	lower := (latencySubBuckets + sub) << shift
+	return lower + (1 << shift) - 1
+}
+
+// Record adds one duration; negative durations count as zero
+func (h *LatencyHistogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	atomic.AddUint64(&h.counts[latencyBucket(uint64(d))], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddUint64(&h.sum, uint64(d))
+
+	for {
+		max := atomic.LoadInt64(&h.max)
+		if int64(d) <= max || atomic.CompareAndSwapInt64(&h.max, max, int64(d)) {
+			return
+		}
+	}
+}
+
+// Count returns the number of recorded durations
+func (h *LatencyHistogram) Count() uint64 {
+	return atomic.LoadUint64(&h.count)
+}
+
+// Max returns the largest recorded duration
+func (h *LatencyHistogram) Max() time.Duration {
+	return time.Duration(atomic.LoadInt64(&h.max))
+}
+
+// Mean returns the average recorded duration
+func (h *LatencyHistogram) Mean() time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadUint64(&h.sum) / count)
+}
+
+// Quantile returns the duration at or below which the fraction q of recorded
+// durations fall, such as 0.99 for p99. The result is the upper edge of the
+// bucket holding that rank, capped at Max.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	max := h.Max()
+
+	var seen uint64
+	for i := range h.counts {
+		seen += atomic.LoadUint64(&h.counts[i])
+		if seen >= rank {
+			if d := time.Duration(latencyBucketMax(i)); d < max {
+				return d
+			}
+			return max
+		}
+	}
+
+	return max
+}
+
+// String formats the p50, p90, p99 and max latencies
+func (h *LatencyHistogram) String() string {
+	return fmt.Sprintf("p50 %s  p90 %s  p99 %s  max %s",
+		RoundLatency(h.Quantile(0.50)),
+		RoundLatency(h.Quantile(0.90)),
+		RoundLatency(h.Quantile(0.99)),
+		RoundLatency(h.Max()))
+}
+
+// RoundLatency rounds a duration to three or four significant digits for display
+func RoundLatency(d time.Duration) time.Duration {
+	for unit := time.Duration(1); unit < time.Second; unit *= 10 {
+		if d < unit*1000 {
+			return d.Round(unit)
+		}
+	}
+	return d.Round(time.Millisecond)
+}
+
+// LatencySet keeps one LatencyHistogram per processor name (thread-safe)
+type LatencySet struct {
+	mu         sync.RWMutex
+	histograms map[string]*LatencyHistogram
+	names      []string
+}
+
+// NewLatencySet creates an empty LatencySet
+func NewLatencySet() *LatencySet {
+	return &LatencySet{histograms: make(map[string]*LatencyHistogram)}
+}
+
+// Record adds a duration to the named processor's histogram
+func (s *LatencySet) Record(name string, d time.Duration) {
+	s.mu.RLock()
+	h, ok := s.histograms[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		s.mu.Lock()
+		if h, ok = s.histograms[name]; !ok {
+			h = NewLatencyHistogram()
+			s.histograms[name] = h
+			s.names = append(s.names, name)
+		}
+		s.mu.Unlock()
+	}
+
+	h.Record(d)
+}
+
+// Names returns the processor names in the order they were first seen
+func (s *LatencySet) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.names...)
+}
+
+// Get returns the named processor's histogram, or nil if nothing was recorded
+func (s *LatencySet) Get(name string) *LatencyHistogram {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.histograms[name]
+}
+
+// SlowRecord is one of the slowest records of a run
+type SlowRecord struct {
+	FileName   string
+	LineNumber int
+	Processor  string
+	Status     ProcessingStatus
+	Duration   time.Duration
+}
+
+// SlowRecords keeps the n slowest results seen (thread-safe)
+type SlowRecords struct {
+	mu      sync.Mutex
+	limit   int
+	records []SlowRecord
+
+	// floor is the fastest kept duration once the list is full, so faster
+	// results are rejected without taking the lock
+	floor int64
+}
+
+// NewSlowRecords creates a SlowRecords that keeps up to limit records
+func NewSlowRecords(limit int) *SlowRecords {
+	return &SlowRecords{limit: limit, floor: -1}
+}
+
+// Add considers a result for the list
+func (s *SlowRecords) Add(result *Result) {
+	if result == nil || result.Record == nil || s.limit <= 0 {
+		return
+	}
+
+	d := result.Duration
+	if int64(d) <= atomic.LoadInt64(&s.floor) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Kept slowest first
+	i := sort.Search(len(s.records), func(i int) bool {
+		return s.records[i].Duration < d
+	})
+	if i >= s.limit {
+		return
+	}
+
+	entry := SlowRecord{
+		FileName:   result.Record.FileName,
+		LineNumber: result.Record.LineNumber,
+		Processor:  result.Processor,
+		Status:     result.Status,
+		Duration:   d,
+	}
+
+	if len(s.records) < s.limit {
+		s.records = append(s.records, SlowRecord{})
+	}
+	copy(s.records[i+1:], s.records[i:])
+	s.records[i] = entry
+
+	if len(s.records) == s.limit {
+		atomic.StoreInt64(&s.floor, int64(s.records[len(s.records)-1].Duration))
+	}
+}
+
+// SetLimit changes how many records are kept, dropping the fastest if needed
+func (s *SlowRecords) SetLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = limit
+	if len(s.records) > limit {
+		s.records = s.records[:max(limit, 0)]
+	}
+
+	floor := int64(-1)
+	if limit > 0 && len(s.records) == limit {
+		floor = int64(s.records[len(s.records)-1].Duration)
+	}
+	atomic.StoreInt64(&s.floor, floor)
+}
+
+// List returns the kept records, slowest first
+func (s *SlowRecords) List() []SlowRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SlowRecord(nil), s.records...)
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	// Every value must fall within its bucket, and buckets must be contiguous
+	prev := -1
+	for _, ns := range []uint64{0, 1, 15, 16, 17, 31, 32, 33, 1000, 1 << 20, 1<<40 + 12345, 1<<63 - 1} {
+		i := latencyBucket(ns)
+		if i >= latencyBuckets {
+			t.Fatalf("value %d: bucket %d out of range", ns, i)
+		}
+		if i < prev {
+			t.Errorf("value %d: bucket %d before previous bucket %d", ns, i, prev)
+		}
+		prev = i
+
+		if latencyBucketMax(i) < ns {
+			t.Errorf("value %d: bucket %d max %d is below the value", ns, i, latencyBucketMax(i))
+		}
+		if i > 0 && latencyBucketMax(i-1) >= ns {
+			t.Errorf("value %d: previous bucket max %d is not below the value", ns, latencyBucketMax(i-1))
+		}
+	}
+}
+
+func TestLatencyHistogram_Quantile(t *testing.T) {
+	h := NewLatencyHistogram()
+
+	if h.Quantile(0.5) != 0 || h.Max() != 0 || h.Mean() != 0 {
+		t.Error("expected zero values for an empty histogram")
+	}
+
+	// 1ms..1000ms in a random order
+	values := rand.New(rand.NewSource(1)).Perm(1000)
+	for _, v := range values {
+		h.Record(time.Duration(v+1) * time.Millisecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.50, 500 * time.Millisecond},
+		{0.90, 900 * time.Millisecond},
+		{0.99, 990 * time.Millisecond},
+		{1.00, 1000 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("p%g", tt.q*100), func(t *testing.T) {
+			got := h.Quantile(tt.q)
+
+			// Buckets are at most 1/16 wide relative to their values
+			if got < tt.want || got > tt.want+tt.want/16 {
+				t.Errorf("expected %s within 6.25%%, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if h.Count() != 1000 {
+		t.Errorf("expected count 1000, got %d", h.Count())
+	}
+	if h.Max() != time.Second {
+		t.Errorf("expected max 1s, got %s", h.Max())
+	}
+	if h.Mean() != 500500*time.Microsecond {
+		t.Errorf("expected mean 500.5ms, got %s", h.Mean())
+	}
+}
+
+func TestLatencyHistogram_Concurrent(t *testing.T) {
+	h := NewLatencyHistogram()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				h.Record(time.Duration(i*1000+j) * time.Microsecond)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if h.Count() != 8000 {
+		t.Errorf("expected count 8000, got %d", h.Count())
+	}
+	if h.Max() != 7999*time.Microsecond {
+		t.Errorf("expected max 7.999ms, got %s", h.Max())
+	}
+}
+
+func TestLatencySet(t *testing.T) {
+	s := NewLatencySet()
+	s.Record("integrity", time.Millisecond)
+	s.Record("enrich", 2*time.Millisecond)
+	s.Record("integrity", 3*time.Millisecond)
+
+	names := s.Names()
+	if len(names) != 2 || names[0] != "integrity" || names[1] != "enrich" {
+		t.Errorf("expected [integrity enrich], got %v", names)
+	}
+	if s.Get("integrity").Count() != 2 {
+		t.Errorf("expected 2 integrity latencies, got %d", s.Get("integrity").Count())
+	}
+	if s.Get("missing") != nil {
+		t.Error("expected nil for an unknown processor")
+	}
+}
+
+func TestSlowRecords(t *testing.T) {
+	s := NewSlowRecords(3)
+
+	for i, ms := range []int{5, 1, 9, 3, 7, 2} {
+		record := NewRecord(i+2, "data.csv", []string{"x"}, nil)
+		s.Add(NewSuccessResult(record, nil, time.Duration(ms)*time.Millisecond))
+	}
+	s.Add(nil)
+
+	list := s.List()
+	if len(list) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(list))
+	}
+
+	wantLines := []int{4, 6, 2}
+	for i, want := range wantLines {
+		if list[i].LineNumber != want {
+			t.Errorf("record %d: expected line %d, got %d", i, want, list[i].LineNumber)
+		}
+	}
+	if list[0].Duration != 9*time.Millisecond || list[0].FileName != "data.csv" {
+		t.Errorf("expected data.csv at 9ms first, got %+v", list[0])
+	}
+
+	s.SetLimit(1)
+	if list := s.List(); len(list) != 1 || list[0].LineNumber != 4 {
+		t.Errorf("expected only line 4 after SetLimit(1), got %+v", list)
+	}
+}
+
+func TestSummary_Latency(t *testing.T) {
+	summary := NewSummary()
+
+	for i := 1; i <= 20; i++ {
+		result := NewSuccessResult(NewRecord(i+1, "data.csv", []string{"x"}, nil), nil, time.Duration(i)*time.Microsecond)
+		result.Processor = "default"
+		summary.AddResult(result)
+	}
+
+	if got := summary.Latency().Get("default").Count(); got != 20 {
+		t.Errorf("expected 20 latencies, got %d", got)
+	}
+
+	slow := summary.SlowRecords()
+	if len(slow) != DefaultSlowRecords {
+		t.Fatalf("expected %d slow records, got %d", DefaultSlowRecords, len(slow))
+	}
+	if slow[0].LineNumber != 21 || slow[0].Processor != "default" {
+		t.Errorf("expected line 21 from default first, got %+v", slow[0])
+	}
+}
+
+func TestRoundLatency(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want time.Duration
+	}{
+		{287 * time.Nanosecond, 287 * time.Nanosecond},
+		{1794 * time.Nanosecond, 1790 * time.Nanosecond},
+		{65312 * time.Nanosecond, 65300 * time.Nanosecond},
+		{1234567 * time.Microsecond, 1230 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := RoundLatency(tt.in); got != tt.want {
+			t.Errorf("RoundLatency(%s): expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -12,6 +13,10 @@ type Record struct {
 	// FileName is the source CSV file name
 	FileName string
 
+	// Path is the source file path as given to the reader; unlike FileName
+	// it tells apart files of the same name in different directories
+	Path string
+
 	// Data contains the parsed CSV fields
 	Data []string
 
@@ -72,3 +77,19 @@ func (r *Record) IsValid() bool {
 
 	return true
 }
+
+// Ref returns the source position of this record
+func (r *Record) Ref() RecordRef {
+	return RecordRef{FileName: r.FileName, LineNumber: r.LineNumber}
+}
+
+// RecordRef identifies a record by its source file and line
+type RecordRef struct {
+	FileName   string
+	LineNumber int
+}
+
+// String returns the reference in file:line form
+func (r RecordRef) String() string {
+	return fmt.Sprintf("%s:%d", r.FileName, r.LineNumber)
+}
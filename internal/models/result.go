@@ -34,6 +34,10 @@ type Result struct {
 
 	// Duration is how long processing took
 	Duration time.Duration
+
+	// Processor names the processor that produced the result, for latency
+	// statistics; the worker pool fills it in when the processor does not
+	Processor string
 }
 
 // NewResult creates a new Result instance
@@ -68,6 +72,17 @@ func NewFailedResult(record *Record, err error, duration time.Duration) *Result
 	}
 }
 
+// NewSkippedResult creates a skipped result
+func NewSkippedResult(record *Record, processedData interface{}, duration time.Duration) *Result {
+	return &Result{
+		Record:        record,
+		Status:        StatusSkipped,
+		ProcessedData: processedData,
+		ProcessedAt:   time.Now(),
+		Duration:      duration,
+	}
+}
+
 // IsSuccess returns true if the result is successful
 func (r *Result) IsSuccess() bool {
 	return r.Status == StatusSuccess
@@ -78,6 +93,11 @@ func (r *Result) IsFailed() bool {
 	return r.Status == StatusFailed
 }
 
+// IsSkipped returns true if the result is skipped
+func (r *Result) IsSkipped() bool {
+	return r.Status == StatusSkipped
+}
+
 // Summary represents aggregated processing results
 type Summary struct {
 	// Atomic counters
@@ -92,12 +112,21 @@ type Summary struct {
 	endTime    time.Time
 	duration   time.Duration
 	throughput float64
+
+	// Processing latency per processor, and the slowest records
+	latency *LatencySet
+	slowest *SlowRecords
 }
 
+// DefaultSlowRecords is how many of the slowest records a Summary keeps
+const DefaultSlowRecords = 10
+
 // NewSummary creates a new Summary instance
 func NewSummary() *Summary {
 	return &Summary{
 		startTime: time.Now(),
+		latency:   NewLatencySet(),
+		slowest:   NewSlowRecords(DefaultSlowRecords),
 	}
 }
 
@@ -113,6 +142,9 @@ func (s *Summary) AddResult(result *Result) {
 	case StatusSkipped:
 		atomic.AddUint64(&s.skippedCount, 1)
 	}
+
+	s.latency.Record(result.Processor, result.Duration)
+	s.slowest.Add(result)
 }
 
 // Finalize completes the summary calculation
@@ -177,6 +209,21 @@ func (s *Summary) Throughput() float64 {
 	return s.throughput
 }
 
+// Latency returns the processing latency histograms per processor
+func (s *Summary) Latency() *LatencySet {
+	return s.latency
+}
+
+// SlowRecords returns the slowest records processed, slowest first
+func (s *Summary) SlowRecords() []SlowRecord {
+	return s.slowest.List()
+}
+
+// KeepSlowRecords sets how many of the slowest records are kept
+func (s *Summary) KeepSlowRecords(n int) {
+	s.slowest.SetLimit(n)
+}
+
 // SuccessRate returns the percentage of successful records
 func (s *Summary) SuccessRate() float64 {
 	total := atomic.LoadUint64(&s.totalRecords)
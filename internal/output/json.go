@@ -0,0 +1,110 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+)
+
+// RowWriter writes output rows; Writer and JSONWriter implement it
+type RowWriter interface {
+	WriteHeader(header []string) error
+	Write(fields []string) error
+	Flush() error
+	Rows() uint64
+}
+
+// JSONWriter writes rows as JSON Lines: one object per row keyed by the
+// header in column order, or one array per row when there is no header
+type JSONWriter struct {
+	w *bufio.Writer
+
+	// keys are the JSON-encoded header names
+	keys [][]byte
+
+	rows uint64
+}
+
+// NewJSONWriter creates a new JSONWriter
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// WriteHeader sets the object keys; subsequent calls are ignored
+func (w *JSONWriter) WriteHeader(header []string) error {
+	if w.keys != nil {
+		return nil
+	}
+
+	w.keys = make([][]byte, len(header))
+	for i, name := range header {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+		w.keys[i] = key
+	}
+
+	return nil
+}
+
+// Write writes a single data row. Fields beyond the header are keyed by
+// their 1-based column number.
+func (w *JSONWriter) Write(fields []string) error {
+	if err := w.writeRow(fields); err != nil {
+		return fmt.Errorf("write row: %w", err)
+	}
+
+	atomic.AddUint64(&w.rows, 1)
+	return nil
+}
+
+// writeRow encodes one row followed by a newline
+func (w *JSONWriter) writeRow(fields []string) error {
+	if w.keys == nil {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		w.w.Write(data)
+		return w.w.WriteByte('\n')
+	}
+
+	w.w.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+
+		if i < len(w.keys) {
+			w.w.Write(w.keys[i])
+		} else {
+			w.w.WriteString(strconv.Quote(strconv.Itoa(i + 1)))
+		}
+		w.w.WriteByte(':')
+
+		value, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		w.w.Write(value)
+	}
+	w.w.WriteString("}\n")
+
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *JSONWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Rows returns the number of data rows written
+func (w *JSONWriter) Rows() uint64 {
+	return atomic.LoadUint64(&w.rows)
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Writer writes output rows as CSV and counts them
+type Writer struct {
+	csv *csv.Writer
+
+	// rows counts data rows written (excluding the header)
+	rows uint64
+
+	// headerWritten ensures the header is written at most once
+	headerWritten bool
+}
+
+// Config holds the output dialect for Writer
+type Config struct {
+	// Delimiter separates fields (default: ',')
+	Delimiter rune
+
+	// CRLF ends lines with \r\n instead of \n
+	CRLF bool
+}
+
+// NewWriter creates a new output Writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		csv: csv.NewWriter(w),
+	}
+}
+
+// NewWriterWithConfig creates an output Writer with the given dialect
+func NewWriterWithConfig(w io.Writer, config Config) *Writer {
+	writer := NewWriter(w)
+	if config.Delimiter != 0 {
+		writer.csv.Comma = config.Delimiter
+	}
+	writer.csv.UseCRLF = config.CRLF
+
+	return writer
+}
+
+// WriteHeader writes the header row; subsequent calls are ignored
+func (w *Writer) WriteHeader(header []string) error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+
+	if err := w.csv.Write(header); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	return nil
+}
+
+// Write writes a single data row
+func (w *Writer) Write(fields []string) error {
+	if err := w.csv.Write(fields); err != nil {
+		return fmt.Errorf("write row: %w", err)
+	}
+
+	atomic.AddUint64(&w.rows, 1)
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Rows returns the number of data rows written
+func (w *Writer) Rows() uint64 {
+	return atomic.LoadUint64(&w.rows)
+}
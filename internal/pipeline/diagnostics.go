@@ -0,0 +1,86 @@
+package pipeline
+
+import "github.com/zuhrulumam/csv_processor/internal/worker"
+
+// Diagnostics is a snapshot of a run's queues, workers and errors, for
+// diagnosing stalls and tuning the worker count and buffer size
+type Diagnostics struct {
+	RunID string `json:"run_id"`
+
+	// State is "pending" before Run, then "running" and "finished"
+	State string `json:"state"`
+
+	// Records are read and waiting for a worker; Results are processed and
+	// waiting to be collected. A full Records queue with idle workers
+	// points at slow collection or output, an empty one at slow reading.
+	Records QueueStats `json:"records"`
+	Results QueueStats `json:"results"`
+
+	// Workers is the configured worker count; ActiveWorkers are processing
+	// a record right now
+	Workers       int                  `json:"workers"`
+	ActiveWorkers int                  `json:"active_workers"`
+	WorkerStats   []worker.WorkerStats `json:"worker_stats"`
+
+	Errors ErrorDiagnostics `json:"errors"`
+}
+
+// QueueStats is the occupancy of a channel between two stages
+type QueueStats struct {
+	Len int `json:"len"`
+	Cap int `json:"cap"`
+}
+
+// ErrorDiagnostics are the error collector's counts
+type ErrorDiagnostics struct {
+	Collected         int            `json:"collected"`
+	Rate              float64        `json:"rate"`
+	ThresholdExceeded bool           `json:"threshold_exceeded"`
+	ByCategory        map[string]int `json:"by_category"`
+}
+
+// Diagnostics returns a snapshot of the run. It is safe to call from any
+// goroutine, before, during and after Run.
+func (p *Pipeline) Diagnostics() Diagnostics {
+	p.runMu.Lock()
+	state := "pending"
+	if p.finished {
+		state = "finished"
+	} else if p.ran {
+		state = "running"
+	}
+	p.runMu.Unlock()
+
+	d := Diagnostics{
+		RunID:       p.runID,
+		State:       state,
+		Workers:     p.config.Workers,
+		WorkerStats: []worker.WorkerStats{},
+	}
+
+	p.poolMu.RLock()
+	pool, records := p.workerPool, p.records
+	p.poolMu.RUnlock()
+
+	if pool != nil {
+		d.Records = QueueStats{Len: len(records), Cap: cap(records)}
+		d.Results = QueueStats{Len: len(pool.Results()), Cap: cap(pool.Results())}
+		d.Workers = pool.WorkerCount()
+		d.ActiveWorkers = pool.Active()
+		d.WorkerStats = pool.WorkerStats()
+	}
+
+	byCategory := make(map[string]int)
+	for category, n := range p.errorCol.CountByCategory() {
+		byCategory[string(category)] = n
+	}
+
+	d.Errors = ErrorDiagnostics{
+		Collected:         p.errorCol.Count(),
+		Rate:              p.errorCol.ErrorRate(),
+		ThresholdExceeded: p.errorCol.ThresholdExceeded(),
+		ByCategory:        byCategory,
+	}
+
+	return d
+}
@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/metrics"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/worker"
+)
+
+// pipelineMetrics are the Prometheus metrics updated by a run
+type pipelineMetrics struct {
+	registry *metrics.Registry
+
+	read      *metrics.CounterVec
+	processed *metrics.CounterVec
+	errors    *metrics.CounterVec
+	output    *metrics.Counter
+	latency   *metrics.Histogram
+
+	// processedByKey caches counters by file and status; only handleResults uses it
+	processedByKey map[[2]string]*metrics.Counter
+}
+
+// newPipelineMetrics registers the pipeline metrics, or returns nil without a registry
+func newPipelineMetrics(registry *metrics.Registry) *pipelineMetrics {
+	if registry == nil {
+		return nil
+	}
+
+	return &pipelineMetrics{
+		registry: registry,
+		read: registry.Counter("csvproc_records_read_total",
+			"Records read from input files.", "file"),
+		processed: registry.Counter("csvproc_records_processed_total",
+			"Records processed, by file and status (success, failed or skipped).", "file", "status"),
+		errors: registry.Counter("csvproc_errors_total",
+			"Failed records and read errors, by error category.", "category"),
+		output: registry.Counter("csvproc_output_rows_total",
+			"Rows written to the output.").With(),
+		latency: registry.Histogram("csvproc_record_duration_seconds",
+			"Time the processor took per record.", metrics.DefaultLatencyBuckets).With(),
+		processedByKey: make(map[[2]string]*metrics.Counter),
+	}
+}
+
+// watch registers gauges that sample the channels and workers of this run
+func (m *pipelineMetrics) watch(workers int, records <-chan *models.Record, pool *worker.Pool) {
+	m.registry.GaugeFunc("csvproc_workers", "Configured worker goroutines.", func() float64 {
+		return float64(workers)
+	})
+	m.registry.GaugeFunc("csvproc_active_workers", "Workers currently processing a record.", func() float64 {
+		return float64(pool.Active())
+	})
+	m.registry.GaugeFunc("csvproc_records_queued", "Records read and waiting for a worker.", func() float64 {
+		return float64(len(records))
+	})
+	m.registry.GaugeFunc("csvproc_results_queued", "Results waiting to be collected.", func() float64 {
+		return float64(len(pool.Results()))
+	})
+}
+
+// countRead forwards records, counting them per file, until in is closed or
+// done is closed
+func (m *pipelineMetrics) countRead(in <-chan *models.Record, done <-chan struct{}) <-chan *models.Record {
+	out := make(chan *models.Record, cap(in))
+
+	go func() {
+		defer close(out)
+
+		counters := make(map[string]*metrics.Counter)
+		for record := range in {
+			counter, ok := counters[record.FileName]
+			if !ok {
+				counter = m.read.With(record.FileName)
+				counters[record.FileName] = counter
+			}
+			counter.Inc()
+
+			select {
+			case out <- record:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// observe records a result
+func (m *pipelineMetrics) observe(result *models.Result) {
+	var file string
+	if result.Record != nil {
+		file = result.Record.FileName
+	}
+
+	status := strings.ToLower(string(result.Status))
+	key := [2]string{file, status}
+	counter, ok := m.processedByKey[key]
+	if !ok {
+		counter = m.processed.With(file, status)
+		m.processedByKey[key] = counter
+	}
+	counter.Inc()
+
+	m.latency.Observe(result.Duration.Seconds())
+
+	if result.IsFailed() && result.Error != nil {
+		m.errors.With(string(errors.Categorize(result.Error))).Inc()
+	}
+}
+
+// readError records an error from the reader
+func (m *pipelineMetrics) readError(err error) {
+	m.errors.With(string(errors.Categorize(err))).Inc()
+}
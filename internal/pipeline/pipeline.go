@@ -2,17 +2,24 @@ package pipeline
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"os/signal"
+	"path/filepath"
 	"sync"
-	"syscall"
 	"time"
 
+	"github.com/zuhrulumam/csv_processor/internal/checkpoint"
 	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/metrics"
 	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/output"
 	"github.com/zuhrulumam/csv_processor/internal/processor"
 	"github.com/zuhrulumam/csv_processor/internal/reader"
+	"github.com/zuhrulumam/csv_processor/internal/tracing"
 	"github.com/zuhrulumam/csv_processor/internal/tracker"
 	"github.com/zuhrulumam/csv_processor/internal/worker"
 )
@@ -24,16 +31,46 @@ type Pipeline struct {
 	// Components
 	reader *reader.CSVReader
 
-	// Mutex protects workerPool
+	// Mutex protects workerPool and records, the channel its workers read
 	poolMu     sync.RWMutex
 	workerPool *worker.Pool
+	records    <-chan *models.Record
 
-	progress *tracker.ProgressTracker
+	progress *tracker.MultiTracker
 	errorCol *errors.Collector
 
-	// Context and cancellation
-	ctx    context.Context
-	cancel context.CancelFunc
+	// writer encodes output rows to OutputWriter; headerChecked is set once
+	// the header of a HeaderProvider processor is written or found not needed
+	writer        *output.Writer
+	headerChecked bool
+
+	// outputErr is set when buffered output could not be written at the end
+	outputErr error
+
+	// metrics are updated during the run when Config.Metrics is set
+	metrics *pipelineMetrics
+
+	// logger carries the run ID on every line
+	logger *slog.Logger
+	runID  string
+
+	// tracer records spans when Config.Tracer is set; lane is the
+	// pipeline's own, for setup and finalization
+	tracer *tracing.Tracer
+	lane   int
+
+	// checkpoint records completed lines when CheckpointFile is set
+	checkpoint     *checkpoint.Checkpoint
+	lastCheckpoint time.Time
+
+	// Context and cancellation, set by Run; runMu protects them and the run state
+	runMu       sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	ran         bool
+	stopped     bool
+	finished    bool
+	interrupted bool
 
 	// Summary
 	summary *models.Summary
@@ -44,26 +81,93 @@ type Config struct {
 	// Input files
 	Files []string
 
-	// CSV options
+	// CSV options. Delimiter separates the fields of the input files and
+	// the output (default: ',').
 	HasHeader      bool
 	ValidateHeader bool
+	Delimiter      rune
+
+	// Follow keeps reading input files as rows are appended, like tail -F,
+	// until the run is stopped. FollowPoll is how often to check for new data.
+	Follow     bool
+	FollowPoll time.Duration
 
 	// Processing
 	Workers    int
 	Processor  processor.Processor
 	BufferSize int
 
+	// Referential integrity checks, resolved before the main pass
+	ForeignKeys []processor.ForeignKey
+
 	// Error handling
 	MaxErrors      int
 	ErrorThreshold float64
 	AbortOnError   bool
 
-	// Progress tracking
-	ShowProgress  bool
-	VerboseOutput bool
+	// Progress tracking. ProgressFormat is "text" (default), "json" for a
+	// stream of JSON events or "dashboard" for a live display on a terminal,
+	// written to ProgressWriter (default: os.Stdout).
+	ShowProgress   bool
+	VerboseOutput  bool
+	ProgressFormat string
+	ProgressWriter io.Writer
+
+	// Prescan counts the records of every input before processing, in
+	// parallel, so progress shows exact percent complete and ETA. Without
+	// it they are estimated from the bytes read.
+	Prescan bool
+
+	// SlowRecords is how many of the slowest records the summary keeps
+	// (default: models.DefaultSlowRecords)
+	SlowRecords int
+
+	// OutputWriter receives the output rows. Checkpointing needs it to be an
+	// *os.File, and the run report has its size and checksum only then.
+	OutputWriter io.Writer
+
+	// Checkpointing: completed lines are saved to CheckpointFile every
+	// CheckpointInterval (default 5s) and at the end of the run. With Resume,
+	// completed records are skipped and OutputWriter is truncated to the size
+	// recorded in the checkpoint, then appended to.
+	CheckpointFile     string
+	CheckpointInterval time.Duration
+	Resume             bool
+
+	// Metrics, if set, receives record, error, queue and latency metrics for
+	// serving in the Prometheus text format. Pipelines run one after another
+	// may share a registry.
+	Metrics *metrics.Registry
+
+	// Logger receives diagnostics from the pipeline, reader, workers and
+	// error collector, and the error report at the end of the run, each
+	// line tagged with RunID (default: discard). RunID is generated when
+	// empty; pipelines run for one job may share it.
+	Logger *slog.Logger
+	RunID  string
+
+	// RunReport, if set, is where a JSON report of the run is written at
+	// the end: inputs and outputs with checksums, the summary, errors and
+	// the outcome. ReportConfig is recorded in it as the effective
+	// configuration; the pipeline's own settings are used when it is nil.
+	RunReport    string
+	ReportConfig any
+
+	// ExitCode, if set, maps the error Run returns to the exit status of the
+	// calling program, for the run report
+	ExitCode func(runErr error) int
+
+	// Tracer, if set, records spans of file reads, queue waits, processor
+	// calls and output writes, for writing as a Chrome trace. Pipelines run
+	// one after another may share a tracer.
+	Tracer *tracing.Tracer
+}
 
-	// Output
-	OutputWriter *os.File
+// NewRunID returns a random identifier for tagging a run's log lines
+func NewRunID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
 }
 
 // NewPipeline creates a new processing pipeline
@@ -73,75 +177,189 @@ func NewPipeline(config Config) (*Pipeline, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	if config.CheckpointInterval == 0 {
+		config.CheckpointInterval = 5 * time.Second
+	}
+
+	if config.RunID == "" {
+		config.RunID = NewRunID()
+	}
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.DiscardHandler)
+	}
+	logger := config.Logger.With("run_id", config.RunID)
 
 	// Create error collector
 	errorCollector := errors.NewCollector(errors.CollectorConfig{
 		MaxErrors:        config.MaxErrors,
 		ErrorThreshold:   config.ErrorThreshold,
 		AbortOnThreshold: config.AbortOnError,
+		Logger:           logger,
 	})
 
 	// Create progress tracker
-	progressWriter := os.Stdout
-	if !config.ShowProgress {
-		progressWriter = nil
+	var progressWriter io.Writer
+	if config.ShowProgress {
+		progressWriter = config.ProgressWriter
+		if progressWriter == nil {
+			progressWriter = os.Stdout
+		}
 	}
 
-	progressTracker := tracker.NewProgressTracker(tracker.Config{
+	progressConfig := tracker.Config{
 		Writer:         progressWriter,
 		UpdateInterval: 1 * time.Second,
 		Verbose:        config.VerboseOutput,
-	})
+		Format:         config.ProgressFormat,
+	}
+
+	// A followed file has no end, so show the recent rate rather than an average
+	if config.Follow {
+		progressConfig.RateWindow = 10 * time.Second
+	}
+
+	// Each input file gets its own tracker, keyed by base name as records are
+	progressTracker := tracker.NewMultiTrackerWithConfig(progressConfig)
+	for _, file := range config.Files {
+		progressTracker.AddFile(filepath.Base(file), 0)
+	}
 
 	pipeline := &Pipeline{
 		config:   config,
 		errorCol: errorCollector,
 		progress: progressTracker,
-		ctx:      ctx,
-		cancel:   cancel,
 		summary:  models.NewSummary(),
+		metrics:  newPipelineMetrics(config.Metrics),
+		logger:   logger,
+		runID:    config.RunID,
+		tracer:   config.Tracer,
+	}
+
+	if config.SlowRecords > 0 {
+		pipeline.summary.KeepSlowRecords(config.SlowRecords)
+	}
+
+	progressTracker.Global().SetErrorStats(pipeline.errorStats)
+
+	if config.OutputWriter != nil {
+		pipeline.writer = output.NewWriterWithConfig(config.OutputWriter, output.Config{Delimiter: config.Delimiter})
 	}
 
 	return pipeline, nil
 }
 
-// Run executes the pipeline
-func (p *Pipeline) Run() error {
-	// Setup signal handling for graceful shutdown
-	p.setupSignalHandling()
+// Run executes the pipeline. Canceling ctx stops reading, lets in-flight
+// records finish and returns ctx.Err() after the usual finalization; Stop
+// ends the run the same way but Run returns nil. A Pipeline is single-use:
+// calling Run again returns errors.ErrPipelineAlreadyRun.
+func (p *Pipeline) Run(ctx context.Context) error {
+	p.runMu.Lock()
+	if p.ran {
+		p.runMu.Unlock()
+		return errors.ErrPipelineAlreadyRun
+	}
+	p.ran = true
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	if p.stopped {
+		p.cancel()
+	}
+	p.runMu.Unlock()
+
+	// Release the context, remembering whether the run was cut short
+	defer func() {
+		p.runMu.Lock()
+		p.finished = true
+		p.interrupted = p.ctx.Err() != nil
+		p.runMu.Unlock()
+		p.cancel()
+	}()
+
+	p.lane = p.tracer.Lane("pipeline " + p.runID)
+	span := p.tracer.Start(p.lane, "run", "pipeline")
+	span.SetArg("run_id", p.runID)
+	span.SetArg("files", len(p.config.Files))
+	span.SetArg("workers", p.config.Workers)
+	defer span.End()
+
+	// Build referenced key sets before reading any input
+	proc := p.config.Processor
+	if len(p.config.ForeignKeys) > 0 {
+		// Reference files are read with the input's delimiter unless one is set
+		foreignKeys := append([]processor.ForeignKey(nil), p.config.ForeignKeys...)
+		for i := range foreignKeys {
+			if foreignKeys[i].Delimiter == 0 {
+				foreignKeys[i].Delimiter = p.config.Delimiter
+			}
+		}
 
-	// Start progress tracker
-	if p.config.ShowProgress {
-		if err := p.progress.Start(); err != nil {
-			return fmt.Errorf("failed to start progress tracker: %w", err)
+		refSpan := p.tracer.Start(p.lane, "load references", "pipeline")
+		integrity, err := processor.NewIntegrityProcessor(p.ctx, proc, foreignKeys)
+		refSpan.End()
+		if err != nil {
+			return fmt.Errorf("failed to load referenced keys: %w", err)
 		}
+		proc = integrity
+	}
+
+	// Load the checkpoint and rewind output to its last durable size
+	if err := p.openCheckpoint(); err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
 	}
 
 	// Create CSV reader
-	p.reader = reader.NewCSVReader(reader.Config{
+	readerConfig := reader.Config{
 		Files:          p.config.Files,
 		HasHeader:      p.config.HasHeader,
 		ValidateHeader: p.config.ValidateHeader,
 		BufferSize:     p.config.BufferSize,
-	})
+		Delimiter:      p.config.Delimiter,
+		Follow:         p.config.Follow,
+		PollInterval:   p.config.FollowPoll,
+		Logger:         p.logger,
+		Tracer:         p.tracer,
+	}
+	if p.checkpoint != nil && p.config.Resume {
+		readerConfig.Skip = p.checkpoint.Completed
+	}
+	p.reader = reader.NewCSVReader(readerConfig)
+
+	p.logger.Info("run started",
+		"files", len(p.config.Files),
+		"workers", p.config.Workers,
+		"processor", processor.Name(proc),
+		"resume", p.config.Resume,
+	)
+
+	// Estimate percent complete and ETA, then start progress tracker
+	p.setupProgress()
+
+	if p.config.ShowProgress {
+		if err := p.progress.Start(); err != nil {
+			return fmt.Errorf("failed to start progress tracker: %w", err)
+		}
+	}
 
 	// Start reading files
 	recordCh, readerErrCh := p.reader.Read(p.ctx)
+	if p.metrics != nil {
+		recordCh = p.metrics.countRead(recordCh, p.ctx.Done())
+	}
 
 	// Create worker pool
 	pool := worker.NewPool(worker.Config{
 		Workers:          p.config.Workers,
-		Processor:        p.config.Processor,
+		Processor:        proc,
 		InputChannel:     recordCh,
 		OutputBufferSize: p.config.BufferSize,
 		ErrorBufferSize:  10,
+		Logger:           p.logger,
+		Tracer:           p.tracer,
 	})
 
 	// Store pool with mutex protection
 	p.poolMu.Lock()
 	p.workerPool = pool
+	p.records = recordCh
 	p.poolMu.Unlock()
 
 	// Start worker pool
@@ -149,6 +367,10 @@ func (p *Pipeline) Run() error {
 		return fmt.Errorf("failed to start worker pool: %w", err)
 	}
 
+	if p.metrics != nil {
+		p.metrics.watch(p.config.Workers, recordCh, pool)
+	}
+
 	// Process results and errors concurrently
 	var wg sync.WaitGroup
 	wg.Add(3)
@@ -174,17 +396,73 @@ func (p *Pipeline) Run() error {
 	// Wait for all handlers to complete
 	wg.Wait()
 
-	// Finalize
-	p.finalize()
+	// Write the output of processors that aggregate across records
+	p.flushProcessor()
+
+	// Finalize; output or a run report that cannot be written fails the run
+	return p.finalize(p.runError(ctx))
+}
+
+// setupProgress gives the trackers a way to estimate percent complete and
+// ETA: bytes read from each input, or exact record counts with Prescan. A
+// followed file has no end, so neither applies.
+func (p *Pipeline) setupProgress() {
+	if p.config.Follow {
+		return
+	}
+
+	p.progress.Global().SetByteProgress(p.reader.Progress)
+
+	byFile := make(map[string][]string)
+	for _, file := range p.config.Files {
+		base := filepath.Base(file)
+		byFile[base] = append(byFile[base], file)
+	}
+
+	for base, files := range byFile {
+		files := files
+		p.progress.GetFileTracker(base).SetByteProgress(func() (read, size int64) {
+			for _, file := range files {
+				r, s := p.reader.FileProgress(file)
+				read += r
+				size += s
+			}
+			return read, size
+		})
+	}
+
+	if !p.config.Prescan {
+		return
+	}
 
-	// Check if we should return error
-	if p.errorCol.HasErrors() && p.config.AbortOnError {
-		if p.errorCol.ThresholdExceeded() {
-			return fmt.Errorf("processing aborted: error threshold exceeded")
+	span := p.tracer.Start(p.lane, "prescan", "pipeline")
+	counts, errs := reader.CountFiles(p.ctx, p.config.Files, p.config.HasHeader)
+	span.End()
+
+	totals := make(map[string]uint64)
+	for i, file := range p.config.Files {
+		if errs[i] != nil {
+			// Progress falls back to bytes read; the reader reports the error
+			if p.ctx.Err() == nil {
+				p.logger.Warn("prescan failed, estimating progress from bytes read", "file", file, "error", errs[i].Error())
+			}
+			return
 		}
+		totals[filepath.Base(file)] += uint64(counts[i])
 	}
 
-	return nil
+	var total uint64
+	for base, count := range totals {
+		// Records completed by a resumed run are skipped, not processed
+		if p.checkpoint != nil && p.config.Resume {
+			completed := uint64(p.checkpoint.CompletedCount(base))
+			count -= min(completed, count)
+		}
+
+		p.progress.GetFileTracker(base).SetTotal(count)
+		total += count
+	}
+	p.progress.Global().SetTotal(total)
 }
 
 // handleResults processes results from workers
@@ -197,15 +475,32 @@ func (p *Pipeline) handleResults() {
 		return
 	}
 
-	for result := range pool.Results() {
-		// Update progress
-		if p.config.ShowProgress {
-			p.progress.RecordProcessed(result)
+	lane := p.tracer.Lane("results " + p.runID)
+	batch := p.tracer.Batch(lane, "results batch", "output", tracing.DefaultBatchSize)
+	defer batch.Flush()
+
+	for {
+		result, ok := <-pool.Results()
+		if !ok {
+			return
+		}
+		batch.Wait("results wait")
+
+		// Update progress; per-file counts are kept even without progress
+		// output, for the summary
+		if result.Record != nil {
+			p.progress.RecordProcessed(result.Record.FileName, result)
+		} else {
+			p.progress.Global().RecordProcessed(result)
 		}
 
 		// Update summary (thread-safe with atomics + mutex)
 		p.summary.AddResult(result)
 
+		if p.metrics != nil {
+			p.metrics.observe(result)
+		}
+
 		// Collect errors
 		if result.IsFailed() && result.Error != nil {
 			p.errorCol.Add(result.Error, result.Record)
@@ -222,10 +517,32 @@ func (p *Pipeline) handleResults() {
 		default:
 		}
 
+		batch.Work("collect")
+
 		// Write output if configured
-		if p.config.OutputWriter != nil && result.IsSuccess() {
+		if p.writer != nil && result.IsSuccess() && !p.isFlusher() {
 			p.writeOutput(result)
+
+			// Rows arrive indefinitely when following; flush whenever caught up
+			if p.config.Follow && len(pool.Results()) == 0 {
+				p.writer.Flush()
+			}
+			batch.Work("write")
+		}
+
+		// Failed and skipped records are complete too; they are not retried on resume
+		if p.checkpoint != nil && result.Record != nil {
+			p.checkpoint.MarkDone(result.Record.FileName, result.Record.LineNumber)
+
+			if time.Since(p.lastCheckpoint) >= p.config.CheckpointInterval {
+				span := p.tracer.Start(lane, "save checkpoint", "output")
+				p.saveCheckpoint()
+				span.End()
+			}
+			batch.Work("checkpoint")
 		}
+
+		batch.Done()
 	}
 }
 
@@ -234,10 +551,18 @@ func (p *Pipeline) handleReaderErrors(errCh <-chan error) {
 	for err := range errCh {
 		p.errorCol.Add(err, nil)
 
-		// For critical reader errors, we might want to abort
-		if errors.IsIOError(err) {
-			fmt.Fprintf(os.Stderr, "Reader error: %v\n", err)
+		if p.metrics != nil {
+			p.metrics.readError(err)
+		}
+
+		// A reader error ends the file it occurred in; once the run is
+		// canceled, reads stopping is expected
+		level := slog.LevelError
+		if p.ctx.Err() != nil {
+			level = slog.LevelDebug
 		}
+		p.logger.Log(context.Background(), level, "read failed",
+			append(errors.Attrs(err, nil), "category", string(errors.Categorize(err)))...)
 	}
 }
 
@@ -258,48 +583,212 @@ func (p *Pipeline) handleWorkerErrors() {
 
 // writeOutput writes successful result to output file
 func (p *Pipeline) writeOutput(result *models.Result) {
-	// This is a simple implementation
-	// In a real scenario, you'd format the output appropriately
-	if result.Record != nil {
-		fmt.Fprintf(p.config.OutputWriter, "%s\n", result.Record.Data)
+	if !p.headerChecked {
+		p.headerChecked = true
+		p.writeHeader(result.Record)
+	}
+
+	// Processors that transform records return the new fields as ProcessedData
+	fields, ok := result.ProcessedData.([]string)
+	if !ok {
+		if result.Record == nil {
+			return
+		}
+		fields = result.Record.Data
+	}
+
+	p.writer.Write(fields)
+
+	if p.metrics != nil {
+		p.metrics.output.Inc()
 	}
 }
 
-// setupSignalHandling sets up signal handlers for graceful shutdown
-func (p *Pipeline) setupSignalHandling() {
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+// writeHeader writes the header of a HeaderProvider processor for the input
+// headers of the first output record. Output that already has content, as on
+// resume or when incremental runs share a file, keeps its header.
+func (p *Pipeline) writeHeader(record *models.Record) {
+	provider, ok := p.config.Processor.(processor.HeaderProvider)
+	if !ok || record == nil || record.Headers == nil {
+		return
+	}
 
-	go func() {
-		sig := <-sigCh
-		fmt.Fprintf(os.Stderr, "\nReceived signal: %v\n", sig)
-		fmt.Fprintf(os.Stderr, "Initiating graceful shutdown...\n")
-		p.cancel()
-	}()
+	if seeker, ok := p.config.OutputWriter.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil && offset > 0 {
+			return
+		}
+	}
+
+	p.writer.WriteHeader(provider.Headers(record.Headers))
+}
+
+// isFlusher reports whether the processor replaces per-record output with flushed rows
+func (p *Pipeline) isFlusher() bool {
+	_, ok := p.config.Processor.(processor.Flusher)
+	return ok
+}
+
+// flushProcessor writes the rows of a Flusher processor once all records are processed.
+// Nothing is written if the run was canceled, since the rows would be incomplete.
+func (p *Pipeline) flushProcessor() {
+	flusher, ok := p.config.Processor.(processor.Flusher)
+	if !ok || p.writer == nil || p.ctx.Err() != nil {
+		return
+	}
+
+	span := p.tracer.Start(p.lane, "flush processor", "output")
+	defer span.End()
+
+	header, rows, err := flusher.Flush(p.ctx)
+	if err != nil {
+		p.errorCol.Add(errors.NewProcessingError("flush", "", 0, err), nil)
+		return
+	}
+
+	if header != nil {
+		p.writer.WriteHeader(header)
+	}
+
+	for _, row := range rows {
+		p.writer.Write(row)
+	}
+	span.SetArg("rows", len(rows))
+
+	if p.metrics != nil {
+		p.metrics.output.Add(float64(len(rows)))
+	}
+}
+
+// openCheckpoint opens the checkpoint file and, when resuming, truncates the
+// output to the size that was durable at the last save
+func (p *Pipeline) openCheckpoint() error {
+	if p.config.CheckpointFile == "" {
+		return nil
+	}
+
+	cp, err := checkpoint.Open(p.config.CheckpointFile, p.config.HasHeader, p.config.Resume)
+	if err != nil {
+		return err
+	}
+
+	if file, ok := p.outputFile(); ok && p.config.Resume {
+		if err := file.Truncate(cp.OutputSize()); err != nil {
+			return fmt.Errorf("truncate output: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			return fmt.Errorf("seek output: %w", err)
+		}
+	}
+
+	p.checkpoint = cp
+	p.lastCheckpoint = time.Now()
+
+	return nil
+}
+
+// outputFile returns OutputWriter when it is a file, which checkpointing
+// truncates and syncs and the run report checksums
+func (p *Pipeline) outputFile() (*os.File, bool) {
+	file, ok := p.config.OutputWriter.(*os.File)
+	return file, ok && file != nil
+}
+
+// saveCheckpoint makes written output durable and then saves the completed
+// lines with the output size, so the two always agree on resume
+func (p *Pipeline) saveCheckpoint() {
+	p.lastCheckpoint = time.Now()
+
+	var size int64
+	if file, ok := p.outputFile(); ok {
+		if err := p.writer.Flush(); err != nil {
+			p.logger.Error("output write failed", "error", err.Error())
+			return
+		}
+		if err := file.Sync(); err != nil {
+			p.logger.Error("checkpoint failed: sync output", "error", err.Error())
+			return
+		}
+
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			p.logger.Error("checkpoint failed: output offset", "error", err.Error())
+			return
+		}
+		size = offset
+	}
+
+	if err := p.checkpoint.Save(size); err != nil {
+		p.logger.Error("checkpoint failed", "file", p.config.CheckpointFile, "error", err.Error())
+	}
 }
 
-// finalize completes the pipeline execution
-func (p *Pipeline) finalize() {
+// finalize completes the pipeline execution, writing the run report when
+// Config.RunReport is set. It returns runErr, the outcome of processing, or
+// the error that writing output or the report failed with.
+func (p *Pipeline) finalize(runErr error) error {
+	span := p.tracer.Start(p.lane, "finalize", "pipeline")
+	defer span.End()
+
 	// Stop progress tracker
 	if p.config.ShowProgress {
-		p.progress.StopAndPrintFinal()
+		p.progress.Stop()
+	}
+
+	// Flush buffered output
+	if p.writer != nil {
+		if err := p.writer.Flush(); err != nil {
+			p.logger.Error("output write failed", "error", err.Error())
+
+			// Output that is incomplete fails even an interrupted run
+			p.outputErr = fmt.Errorf("write output: %w", err)
+			runErr = p.outputErr
+		}
+	}
+
+	// Save the final checkpoint, including progress made before a cancellation
+	if p.checkpoint != nil {
+		p.saveCheckpoint()
 	}
 
 	// Finalize summary
 	p.summary.Finalize()
 
-	// Print error summary if there are errors
+	p.logger.Info("run finished",
+		"processed", p.summary.TotalRecords(),
+		"success", p.summary.SuccessCount(),
+		"failed", p.summary.FailedCount(),
+		"skipped", p.summary.SkippedCount(),
+		"duration_seconds", p.summary.Duration().Seconds(),
+		"interrupted", p.ctx.Err() != nil,
+	)
+
+	// Output is flushed, so its checksum is final
+	if p.config.RunReport != "" {
+		if err := p.writeReport(runErr); err != nil && runErr == nil {
+			runErr = err
+		}
+	}
+
+	// Log the error summary and the most common errors
 	if p.errorCol.HasErrors() {
-		reporter := errors.NewReporter(p.errorCol, os.Stderr)
-		reporter.PrintSummary()
+		errors.NewReporter(p.errorCol, nil).Log(p.logger, 5)
+	}
 
-		// Print top errors
-		if p.errorCol.Count() > 5 {
-			reporter.PrintTopErrors(5)
-		} else {
-			reporter.PrintDetailed(10)
-		}
+	return runErr
+}
+
+// errorStats reports collected errors for the progress dashboard
+func (p *Pipeline) errorStats() tracker.ErrorStats {
+	stats := tracker.ErrorStats{ByCategory: make(map[string]int)}
+
+	for category, n := range p.errorCol.CountByCategory() {
+		stats.ByCategory[string(category)] = n
+	}
+	for _, entry := range p.errorCol.Recent(3) {
+		stats.Recent = append(stats.Recent, entry.Error.Error())
 	}
+
+	return stats
 }
 
 // Summary returns the processing summary
@@ -307,14 +796,41 @@ func (p *Pipeline) Summary() *models.Summary {
 	return p.summary
 }
 
+// Files returns the progress of each input file, in input order
+func (p *Pipeline) Files() []tracker.FileStats {
+	return p.progress.Files()
+}
+
+// RunID returns the identifier on the run's log lines
+func (p *Pipeline) RunID() string {
+	return p.runID
+}
+
 // Errors returns the error collector
 func (p *Pipeline) Errors() *errors.Collector {
 	return p.errorCol
 }
 
-// Stop gracefully stops the pipeline
+// Interrupted reports whether the run was canceled before all records were processed
+func (p *Pipeline) Interrupted() bool {
+	p.runMu.Lock()
+	defer p.runMu.Unlock()
+
+	if p.finished {
+		return p.interrupted
+	}
+	return p.ctx != nil && p.ctx.Err() != nil
+}
+
+// Stop gracefully stops the pipeline; if Run has not started yet, it will
+// return without processing any records
 func (p *Pipeline) Stop() {
-	p.cancel()
+	p.runMu.Lock()
+	p.stopped = true
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.runMu.Unlock()
 
 	p.poolMu.RLock()
 	pool := p.workerPool
@@ -338,6 +854,56 @@ func validateConfig(config Config) error {
 		}
 	}
 
+	for _, fk := range config.ForeignKeys {
+		if _, err := os.Stat(fk.RefFile); os.IsNotExist(err) {
+			return fmt.Errorf("reference file does not exist: %s", fk.RefFile)
+		}
+	}
+
+	if config.Resume && config.CheckpointFile == "" {
+		return fmt.Errorf("resume requires a checkpoint file")
+	}
+
+	switch config.ProgressFormat {
+	case "", tracker.FormatText, tracker.FormatJSON, tracker.FormatDashboard:
+	default:
+		return fmt.Errorf("unknown progress format %q (want text, json or dashboard)", config.ProgressFormat)
+	}
+
+	if config.Follow {
+		if config.Prescan {
+			return fmt.Errorf("prescan cannot be combined with follow")
+		}
+		if _, ok := config.Processor.(processor.Flusher); ok {
+			return fmt.Errorf("follow is not supported with aggregating processors")
+		}
+		if config.CheckpointFile != "" {
+			return fmt.Errorf("follow cannot be combined with checkpointing")
+		}
+	}
+
+	if config.CheckpointFile != "" {
+		// Aggregated output is only written at the end, so there is nothing to resume
+		if _, ok := config.Processor.(processor.Flusher); ok {
+			return fmt.Errorf("checkpointing is not supported with aggregating processors")
+		}
+
+		// Output is truncated to the checkpointed size on resume
+		if _, ok := config.OutputWriter.(*os.File); config.OutputWriter != nil && !ok {
+			return fmt.Errorf("checkpointing requires output to a file")
+		}
+
+		// Checkpoints are keyed by file base name, as records are
+		seen := make(map[string]string, len(config.Files))
+		for _, file := range config.Files {
+			base := filepath.Base(file)
+			if other, ok := seen[base]; ok {
+				return fmt.Errorf("checkpointing requires distinct file names: %s and %s", other, file)
+			}
+			seen[base] = file
+		}
+	}
+
 	if config.Workers < 0 {
 		return fmt.Errorf("workers must be non-negative")
 	}
@@ -1,13 +1,29 @@
 package pipeline
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/zuhrulumam/csv_processor/internal/checkpoint"
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/metrics"
+	"github.com/zuhrulumam/csv_processor/internal/models"
 	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/internal/tracing"
+	"github.com/zuhrulumam/csv_processor/internal/tracker"
 )
 
 func TestPipeline_BasicExecution(t *testing.T) {
@@ -35,7 +51,7 @@ func TestPipeline_BasicExecution(t *testing.T) {
 	}
 
 	// Run pipeline
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -80,7 +96,7 @@ func TestPipeline_MultipleFiles(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -90,160 +106,1144 @@ func TestPipeline_MultipleFiles(t *testing.T) {
 	if summary.TotalRecords() != expectedRecords {
 		t.Errorf("expected %d records, got %d", expectedRecords, summary.TotalRecords())
 	}
+
+	// Per-file stats are kept in input order even without progress output
+	stats := pipe.Files()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(stats))
+	}
+	for i, file := range stats {
+		if file.File != fmt.Sprintf("test%d.csv", i) {
+			t.Errorf("expected test%d.csv, got %s", i, file.File)
+		}
+		if file.Processed != 3 || file.Success != 3 {
+			t.Errorf("%s: expected 3 successful records, got %+v", file.File, file)
+		}
+	}
+}
+
+func TestPipeline_Prescan(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	plain := filepath.Join(tmpDir, "plain.csv")
+	if err := os.WriteFile(plain, []byte("id,value\n1,100\n2,200\n3,300\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("id,value\n4,400\n5,500\n"))
+	gz.Close()
+
+	compressed := filepath.Join(tmpDir, "packed.csv.gz")
+	if err := os.WriteFile(compressed, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	pipe, err := NewPipeline(Config{
+		Files:     []string{plain, compressed},
+		HasHeader: true,
+		Workers:   2,
+		Processor: processor.NewDefaultProcessor(),
+		Prescan:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	global := pipe.progress.Global()
+	if global.Total() != 5 || global.Processed() != 5 {
+		t.Errorf("expected 5 of 5 records, got %d of %d", global.Processed(), global.Total())
+	}
+
+	for _, file := range pipe.Files() {
+		if file.Percent != 100 {
+			t.Errorf("%s: expected 100%% complete, got %.1f%%", file.File, file.Percent)
+		}
+	}
+
+	read, size := global.Bytes()
+	if size == 0 || read != size {
+		t.Errorf("expected all input bytes read, got %d of %d", read, size)
+	}
+}
+
+func TestPipeline_Logger(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(testFile, []byte("id,value\n1,100\n2,\n3,300\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	empty := filepath.Join(tmpDir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	pipe, err := NewPipeline(Config{
+		Files:     []string{testFile, empty},
+		HasHeader: true,
+		Workers:   2,
+		Processor: processor.NewDefaultProcessor(),
+		Logger:    slog.New(slog.NewJSONHandler(buf, nil)),
+		RunID:     "run-1",
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if pipe.RunID() != "run-1" {
+		t.Errorf("expected run ID run-1, got %s", pipe.RunID())
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	messages := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if entry["run_id"] != "run-1" {
+			t.Errorf("expected run_id on every line, got %v", entry)
+		}
+		messages[entry["msg"].(string)] = entry
+	}
+
+	for _, msg := range []string{"run started", "read failed", "run finished", "error summary"} {
+		if messages[msg] == nil {
+			t.Errorf("expected %q to be logged, got:\n%s", msg, buf.String())
+		}
+	}
+
+	if read := messages["read failed"]; read != nil && read["file"] != empty {
+		t.Errorf("expected read failure for empty.csv, got %v", read)
+	}
+	if finished := messages["run finished"]; finished != nil && finished["processed"] != 3.0 {
+		t.Errorf("expected 3 processed records, got %v", finished)
+	}
+
+	// Pipelines get their own run ID unless one is given
+	other, err := NewPipeline(Config{Files: []string{testFile}, Workers: 1, Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+	if other.RunID() == "" || other.RunID() == "run-1" {
+		t.Errorf("expected a generated run ID, got %q", other.RunID())
+	}
+}
+
+func TestPipeline_Tracer(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(testFile, []byte("id,value\n1,100\n2,200\n3,300\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	outputFile, err := os.Create(filepath.Join(tmpDir, "output.csv"))
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer outputFile.Close()
+
+	tracer := tracing.New()
+	pipe, err := NewPipeline(Config{
+		Files:        []string{testFile},
+		HasHeader:    true,
+		Workers:      2,
+		Processor:    processor.NewDefaultProcessor(),
+		OutputWriter: outputFile,
+		Logger:       slog.New(slog.DiscardHandler),
+		Tracer:       tracer,
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tracer.Write(&buf); err != nil {
+		t.Fatalf("failed to write trace: %v", err)
+	}
+
+	var trace struct {
+		TraceEvents []tracing.Event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid trace JSON: %v", err)
+	}
+
+	// Records are counted once per stage
+	records := make(map[string]float64)
+	spans := make(map[string]int)
+	lanes := make(map[string]bool)
+	for _, ev := range trace.TraceEvents {
+		if ev.Phase == "M" {
+			lanes[ev.Args["name"].(string)] = true
+			continue
+		}
+		if n, ok := ev.Args["records"].(float64); ok {
+			records[ev.Name] += n
+		}
+		spans[ev.Name]++
+	}
+
+	for _, name := range []string{"parse batch", "process batch", "results batch"} {
+		if records[name] != 3 {
+			t.Errorf("expected %s spans to cover 3 records, got %v", name, records[name])
+		}
+	}
+	for _, name := range []string{"run", "read file", "finalize"} {
+		if spans[name] != 1 {
+			t.Errorf("expected one %s span, got %d", name, spans[name])
+		}
+	}
+
+	for _, lane := range []string{"read test.csv", "worker 0", "worker 1", "pipeline " + pipe.RunID(), "results " + pipe.RunID()} {
+		if !lanes[lane] {
+			t.Errorf("expected lane %q, got %v", lane, lanes)
+		}
+	}
+}
+
+func TestPipeline_Diagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "test.csv")
+	content := "id,value\n"
+	for i := 0; i < 20; i++ {
+		content += fmt.Sprintf("%d,%d\n", i, i*10)
+	}
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Workers hold their records until released, and line 2 fails
+	release := make(chan struct{})
+	proc := processor.ProcessorFunc(func(ctx context.Context, record *models.Record) (*models.Result, error) {
+		<-release
+		if record.LineNumber == 2 {
+			return models.NewFailedResult(record, fmt.Errorf("bad record"), 0), nil
+		}
+		return models.NewSuccessResult(record, record.Data, 0), nil
+	})
+
+	pipe, err := NewPipeline(Config{
+		Files:      []string{testFile},
+		HasHeader:  true,
+		Workers:    2,
+		BufferSize: 5,
+		Processor:  proc,
+		Logger:     slog.New(slog.DiscardHandler),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if d := pipe.Diagnostics(); d.State != "pending" || d.Workers != 2 || len(d.WorkerStats) != 0 {
+		t.Errorf("unexpected diagnostics before run: %+v", d)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- pipe.Run(context.Background())
+	}()
+
+	// Both workers busy and the record queue full behind them
+	deadline := time.After(2 * time.Second)
+	for {
+		d := pipe.Diagnostics()
+		if d.ActiveWorkers == 2 && d.Records.Len == d.Records.Cap {
+			if d.State != "running" {
+				t.Errorf("expected state running, got %s", d.State)
+			}
+			if d.Records.Cap != 5 {
+				t.Errorf("expected record queue capacity 5, got %d", d.Records.Cap)
+			}
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("workers never stalled: %+v", d)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	d := pipe.Diagnostics()
+	if d.State != "finished" {
+		t.Errorf("expected state finished, got %s", d.State)
+	}
+	if d.ActiveWorkers != 0 || d.Records.Len != 0 || d.Results.Len != 0 {
+		t.Errorf("expected idle workers and empty queues, got %+v", d)
+	}
+
+	var processed, failed uint64
+	for _, s := range d.WorkerStats {
+		processed += s.Processed
+		failed += s.Failed
+	}
+	if len(d.WorkerStats) != 2 || processed != 19 || failed != 1 {
+		t.Errorf("expected 19 processed and 1 failed over 2 workers, got %+v", d.WorkerStats)
+	}
+
+	if d.Errors.Collected != 1 || d.Errors.Rate != 0.05 {
+		t.Errorf("expected 1 collected error at rate 0.05, got %+v", d.Errors)
+	}
+
+	// The snapshot is served as JSON
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("failed to encode diagnostics: %v", err)
+	}
+	if !strings.Contains(string(data), `"worker_stats":[{"id":0,`) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+// failEmpty fails records with an empty value column
+var failEmpty = processor.ProcessorFunc(func(ctx context.Context, record *models.Record) (*models.Result, error) {
+	if record.GetFieldByName("value") == "" {
+		return models.NewFailedResult(record, fmt.Errorf("missing value"), 0), nil
+	}
+	return models.NewSuccessResult(record, record.Data, 0), nil
+})
+
+func TestPipeline_RunReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "test.csv")
+	content := []byte("id,value\n1,100\n2,\n3,300\n")
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "output.csv")
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer outputFile.Close()
+
+	reportPath := filepath.Join(tmpDir, "report.json")
+	pipe, err := NewPipeline(Config{
+		Files:        []string{testFile},
+		HasHeader:    true,
+		Workers:      2,
+		Processor:    failEmpty,
+		OutputWriter: outputFile,
+		Logger:       slog.New(slog.DiscardHandler),
+		RunReport:    reportPath,
+		ReportConfig: map[string]any{"workers": 2},
+		ExitCode:     exitCode,
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read run report: %v", err)
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid run report: %v", err)
+	}
+
+	if report.RunID != pipe.RunID() || report.Status != StatusCompleted || report.ExitCode == nil || *report.ExitCode != 0 {
+		t.Errorf("unexpected outcome: run %s, status %s, exit code %v", report.RunID, report.Status, report.ExitCode)
+	}
+	if report.StartedAt.IsZero() || report.FinishedAt.Before(report.StartedAt) {
+		t.Errorf("unexpected times: %s to %s", report.StartedAt, report.FinishedAt)
+	}
+	if config, _ := report.Config.(map[string]any); config["workers"] != 2.0 {
+		t.Errorf("expected the given config, got %v", report.Config)
+	}
+
+	sum := sha256.Sum256(content)
+	if len(report.Inputs) != 1 {
+		t.Fatalf("expected 1 input, got %d", len(report.Inputs))
+	}
+	input := report.Inputs[0]
+	if input.Path != testFile || input.SizeBytes != int64(len(content)) || input.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected input: %+v", input)
+	}
+	if input.Records != 3 || input.Success != 2 || input.Failed != 1 {
+		t.Errorf("expected 3 records, 2 succeeded and 1 failed, got %+v", input)
+	}
+
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	sum = sha256.Sum256(output)
+	if len(report.Outputs) != 1 {
+		t.Fatalf("expected 1 output, got %d", len(report.Outputs))
+	}
+	if out := report.Outputs[0]; out.Path != outputPath || out.Rows != 2 || out.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	if report.Summary.Total != 3 || report.Summary.Failed != 1 || report.Summary.Latency["custom"].Count != 3 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+	if report.Errors.Total != 1 || report.Errors.ByCategory["UNKNOWN"] != 1 {
+		t.Errorf("unexpected errors: %+v", report.Errors)
+	}
+
+	// With no ReportConfig, the pipeline's settings are recorded
+	other, err := NewPipeline(Config{Files: []string{testFile}, Workers: 1, Processor: processor.NewDefaultProcessor()})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+	if settings := other.Report(nil).Config.(map[string]any); settings["processor"] != "default" || settings["workers"] != 1 {
+		t.Errorf("expected pipeline settings, got %v", settings)
+	}
+
+	// With no ExitCode, the exit code is left out
+	if code := other.Report(nil).ExitCode; code != nil {
+		t.Errorf("expected no exit code, got %d", *code)
+	}
+}
+
+// exitCode maps run errors to exit statuses as a command would
+func exitCode(err error) int {
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+func TestPipeline_RunReportOutcome(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "invalid.csv")
+	if err := os.WriteFile(file, []byte("id,value\n1,\n2,\n3,300\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Writes to a file opened read-only fail when the output is flushed
+	readOnly, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open output file: %v", err)
+	}
+	defer readOnly.Close()
+
+	// An aborted run fails, and so does one whose output or report cannot be written
+	tests := []struct {
+		name       string
+		abort      bool
+		output     io.Writer
+		report     string
+		wantStatus string
+	}{
+		{name: "aborted", abort: true, report: filepath.Join(tmpDir, "aborted.json"), wantStatus: StatusAborted},
+		{name: "unwritable output", output: readOnly, report: filepath.Join(tmpDir, "output.json"), wantStatus: StatusFailed},
+		{name: "unwritable report", report: filepath.Join(tmpDir, "missing", "report.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipe, err := NewPipeline(Config{
+				Files:          []string{file},
+				HasHeader:      true,
+				Workers:        1,
+				Processor:      failEmpty,
+				ErrorThreshold: 0.1,
+				AbortOnError:   tt.abort,
+				OutputWriter:   tt.output,
+				Logger:         slog.New(slog.DiscardHandler),
+				RunReport:      tt.report,
+				ExitCode:       exitCode,
+			})
+			if err != nil {
+				t.Fatalf("failed to create pipeline: %v", err)
+			}
+
+			if err := pipe.Run(context.Background()); err == nil {
+				t.Fatal("expected run to fail")
+			}
+
+			if tt.wantStatus == "" {
+				return
+			}
+
+			data, err := os.ReadFile(tt.report)
+			if err != nil {
+				t.Fatalf("failed to read run report: %v", err)
+			}
+
+			var report RunReport
+			if err := json.Unmarshal(data, &report); err != nil {
+				t.Fatalf("invalid run report: %v", err)
+			}
+			if report.Status != tt.wantStatus || report.ExitCode == nil || *report.ExitCode != 1 || report.Error == "" {
+				t.Errorf("expected status %s, exit code 1 and an error, got %s, %v, %q",
+					tt.wantStatus, report.Status, report.ExitCode, report.Error)
+			}
+
+			// Output that was not written in full gets no checksum
+			for _, out := range report.Outputs {
+				if out.SHA256 != "" {
+					t.Errorf("expected no checksum for %s, got %s", out.Path, out.SHA256)
+				}
+			}
+		})
+	}
+}
+
+func TestPipeline_ErrorThreshold(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create a file that will cause errors
+	file := filepath.Join(tmpDir, "invalid.csv")
+	content := `name,age\nAlice,30\nBob,invalid\nCharlie,35\nDavid,40\nEve,invalid\nFrank,28\nGrace,33\nHannah,29\nIvan,31\nJack,27`
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Create pipeline with low error threshold
+	pipe, err := NewPipeline(Config{
+		Files:          []string{file},
+		HasHeader:      true,
+		Workers:        2,
+		Processor:      processor.NewDefaultProcessor(),
+		ErrorThreshold: 0.1, // 10%
+		AbortOnError:   true,
+		ShowProgress:   false,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	// Run pipeline - should abort if threshold exceeded
+	err = pipe.Run(context.Background())
+
+	// Check if errors were collected
+	if !pipe.Errors().HasErrors() {
+		t.Error("expected errors to be collected")
+	}
+}
+
+func TestPipeline_GracefulShutdown(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Create a large file
+	file := filepath.Join(tmpDir, "large.csv")
+	content := "id,value\n"
+	for i := 0; i < 10000; i++ {
+		content += fmt.Sprintf("%d,%d\n", i, i*10)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Create pipeline
+	pipe, err := NewPipeline(Config{
+		Files:        []string{file},
+		HasHeader:    true,
+		Workers:      2,
+		Processor:    processor.NewDefaultProcessor(),
+		ShowProgress: false,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	// Run pipeline in background
+	done := make(chan error)
+	go func() {
+		done <- pipe.Run(context.Background())
+	}()
+
+	// Wait until some work has started
+	deadline := time.After(2 * time.Second)
+	for {
+		summary := pipe.Summary()
+		if summary.TotalRecords() > 0 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("pipeline never started processing")
+		default:
+			time.Sleep(1 * time.Millisecond)
+		}
+	}
+	pipe.Stop()
+
+	// Wait for completion
+	select {
+	case <-done:
+		// Pipeline stopped
+	case <-time.After(5 * time.Second):
+		t.Error("pipeline did not stop within timeout")
+	}
+
+	summary := pipe.Summary()
+
+	// Should have processed some records but not all
+	if summary.TotalRecords() == 0 {
+		t.Error("expected some records to be processed")
+	}
+
+	if summary.TotalRecords() >= 10000 {
+		t.Error("pipeline did not stop gracefully")
+	}
+
+	t.Logf("Processed %d records before shutdown", summary.TotalRecords())
+}
+
+func TestPipeline_ContextCancel(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte("id,value\n1,a\n2,b\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	pipe, err := NewPipeline(Config{
+		Files:     []string{file},
+		HasHeader: true,
+		Workers:   2,
+		Processor: processor.NewDefaultProcessor(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := pipe.Run(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if !pipe.Interrupted() {
+		t.Error("expected run to be reported as interrupted")
+	}
+}
+
+func TestPipeline_SingleUse(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte("id,value\n1,a\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	pipe, err := NewPipeline(Config{
+		Files:     []string{file},
+		HasHeader: true,
+		Workers:   1,
+		Processor: processor.NewDefaultProcessor(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	if pipe.Interrupted() {
+		t.Error("expected completed run not to be interrupted")
+	}
+
+	if err := pipe.Run(context.Background()); err != errors.ErrPipelineAlreadyRun {
+		t.Errorf("expected ErrPipelineAlreadyRun, got %v", err)
+	}
+}
+
+func TestPipeline_OutputFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	outputFile := filepath.Join(tmpDir, "output.csv")
+
+	content := "name,value\ntest1,100\ntest2,200\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create input file: %v", err)
+	}
+
+	// Open output file
+	outFile, err := os.Create(outputFile)
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer outFile.Close()
+
+	// Create and run pipeline
+	pipe, err := NewPipeline(Config{
+		Files:        []string{inputFile},
+		HasHeader:    true,
+		Workers:      2,
+		Processor:    processor.NewDefaultProcessor(),
+		OutputWriter: outFile,
+		ShowProgress: false,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	// Verify output file was written
+	outFile.Close()
+
+	stat, err := os.Stat(outputFile)
+	if err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+
+	if stat.Size() == 0 {
+		t.Error("output file is empty")
+	}
 }
 
-func TestPipeline_ErrorThreshold(t *testing.T) {
+func TestPipeline_OutputWriter(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Create a file that will cause errors
-	file := filepath.Join(tmpDir, "invalid.csv")
-	content := `name,age\nAlice,30\nBob,invalid\nCharlie,35\nDavid,40\nEve,invalid\nFrank,28\nGrace,33\nHannah,29\nIvan,31\nJack,27`
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("name,value\ntest1,100\ntest2,200\n"), 0644); err != nil {
+		t.Fatalf("failed to create input file: %v", err)
+	}
 
-	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
-		t.Fatalf("failed to create test file: %v", err)
+	var out bytes.Buffer
+	pipe, err := NewPipeline(Config{
+		Files:        []string{inputFile},
+		HasHeader:    true,
+		Workers:      1,
+		Processor:    processor.NewDefaultProcessor(),
+		OutputWriter: &out,
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	// Create pipeline with low error threshold
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	if out.String() != "test1,100\ntest2,200\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	// Only the rows are known of output that is not a file
+	if outputs := pipe.Report(nil).Outputs; len(outputs) != 1 || outputs[0] != (OutputReport{Rows: 2}) {
+		t.Errorf("unexpected output report: %+v", outputs)
+	}
+
+	// Checkpointing truncates the output on resume, so it needs a file
+	_, err = NewPipeline(Config{
+		Files:          []string{inputFile},
+		OutputWriter:   &out,
+		CheckpointFile: filepath.Join(tmpDir, "checkpoint.json"),
+	})
+	if err == nil {
+		t.Error("expected checkpointing to a buffer to fail")
+	}
+}
+
+func TestPipeline_Delimiter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	if err := os.WriteFile(inputFile, []byte("name;value\ntest1;1,5\ntest2;2,5\n"), 0644); err != nil {
+		t.Fatalf("failed to create input file: %v", err)
+	}
+
+	var out bytes.Buffer
 	pipe, err := NewPipeline(Config{
-		Files:          []string{file},
+		Files:          []string{inputFile},
 		HasHeader:      true,
-		Workers:        2,
+		ValidateHeader: true,
+		Delimiter:      ';',
+		Workers:        1,
 		Processor:      processor.NewDefaultProcessor(),
-		ErrorThreshold: 0.1, // 10%
-		AbortOnError:   true,
-		ShowProgress:   false,
+		OutputWriter:   &out,
 	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	// Commas are data, not separators
+	if out.String() != "test1;1,5\ntest2;2,5\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestPipeline_OutputHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputFile := filepath.Join(tmpDir, "input.csv")
+	refFile := filepath.Join(tmpDir, "merchants.csv")
+
+	if err := os.WriteFile(inputFile, []byte("id,merchant\n1,m1\n2,m2\n"), 0644); err != nil {
+		t.Fatalf("failed to create input file: %v", err)
+	}
+	if err := os.WriteFile(refFile, []byte("merchant,country\nm1,ID\nm2,SG\n"), 0644); err != nil {
+		t.Fatalf("failed to create reference file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{name: "new output", want: "id,merchant,country"},
+		{name: "output with content", existing: "id,merchant,country\n", want: "id,merchant,country"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(t.TempDir(), "output.csv")
+			outFile, err := os.Create(outputFile)
+			if err != nil {
+				t.Fatalf("failed to create output file: %v", err)
+			}
+			defer outFile.Close()
+
+			if _, err := outFile.WriteString(tt.existing); err != nil {
+				t.Fatalf("failed to write output file: %v", err)
+			}
+
+			enrich, err := processor.NewEnrichProcessor(context.Background(), processor.EnrichConfig{
+				References: []processor.Reference{{File: refFile, KeyColumn: "merchant"}},
+			})
+			if err != nil {
+				t.Fatalf("failed to create enrich processor: %v", err)
+			}
+			defer enrich.Close()
+
+			pipe, err := NewPipeline(Config{
+				Files:        []string{inputFile},
+				HasHeader:    true,
+				Workers:      2,
+				Processor:    enrich,
+				OutputWriter: outFile,
+			})
+			if err != nil {
+				t.Fatalf("failed to create pipeline: %v", err)
+			}
+
+			if err := pipe.Run(context.Background()); err != nil {
+				t.Fatalf("pipeline execution failed: %v", err)
+			}
+
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if lines[0] != tt.want {
+				t.Errorf("expected first line %q, got %q", tt.want, lines[0])
+			}
+
+			// One header, then one row per record
+			if len(lines) != 3 {
+				t.Errorf("expected 3 lines, got %d: %q", len(lines), lines)
+			}
+		})
+	}
+}
+
+func TestPipeline_ForeignKeys(t *testing.T) {
+	tmpDir := t.TempDir()
 
+	customers := filepath.Join(tmpDir, "customers.csv")
+	if err := os.WriteFile(customers, []byte("id,name\nc1,Alice\nc2,Bob\n"), 0644); err != nil {
+		t.Fatalf("failed to create customers file: %v", err)
+	}
+
+	orders := filepath.Join(tmpDir, "orders.csv")
+	content := "order_id,customer_id\no1,c1\no2,c9\no3,c2\no4,c7\n"
+	if err := os.WriteFile(orders, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create orders file: %v", err)
+	}
+
+	pipe, err := NewPipeline(Config{
+		Files:     []string{orders},
+		HasHeader: true,
+		Workers:   2,
+		Processor: processor.NewDefaultProcessor(),
+		ForeignKeys: []processor.ForeignKey{
+			{Column: "customer_id", RefFile: customers, RefColumn: "id"},
+		},
+	})
 	if err != nil {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	// Run pipeline - should abort if threshold exceeded
-	err = pipe.Run()
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
 
-	// Check if errors were collected
-	if !pipe.Errors().HasErrors() {
-		t.Error("expected errors to be collected")
+	if got := pipe.Summary().FailedCount(); got != 2 {
+		t.Errorf("expected 2 orphan rows, got %d", got)
+	}
+
+	orphanLines := make(map[int]bool)
+	for _, entry := range pipe.Errors().Errors() {
+		if entry.Category != errors.CategoryValidation {
+			t.Errorf("expected validation error, got %s", entry.Category)
+		}
+		if entry.Record == nil || entry.Record.FileName != "orders.csv" {
+			t.Fatalf("expected error to reference orders.csv, got %+v", entry.Record)
+		}
+		orphanLines[entry.Record.LineNumber] = true
+	}
+
+	if !orphanLines[3] || !orphanLines[5] {
+		t.Errorf("expected orphans at lines 3 and 5, got %v", orphanLines)
+	}
+
+	// Orphans are timed as the integrity check, the rest as the wrapped processor
+	latency := pipe.Summary().Latency()
+	for name, want := range map[string]uint64{"integrity": 2, "default": 2} {
+		if h := latency.Get(name); h == nil || h.Count() != want {
+			t.Errorf("expected %d %s latencies, got %v", want, name, h)
+		}
+	}
+	if got := len(pipe.Summary().SlowRecords()); got != 4 {
+		t.Errorf("expected 4 slow records, got %d", got)
 	}
 }
 
-func TestPipeline_GracefulShutdown(t *testing.T) {
+func TestPipeline_Resume(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Create a large file
-	file := filepath.Join(tmpDir, "large.csv")
-	content := "id,value\n"
-	for i := 0; i < 10000; i++ {
-		content += fmt.Sprintf("%d,%d\n", i, i*10)
+	input := filepath.Join(tmpDir, "data.csv")
+	content := "id,value\n1,a\n2,b\n3,c\n4,d\n5,e\n"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+	// Simulate an interrupted run: lines 2, 3 and 5 completed and durable,
+	// followed by a row that was written after the last checkpoint
+	checkpointFile := filepath.Join(tmpDir, "run.checkpoint")
+	cp, err := checkpoint.Open(checkpointFile, true, false)
+	if err != nil {
+		t.Fatalf("failed to open checkpoint: %v", err)
+	}
+	for _, line := range []int{2, 3, 5} {
+		cp.MarkDone("data.csv", line)
+	}
+
+	durable := "1,a\n2,b\n4,d\n"
+	if err := cp.Save(int64(len(durable))); err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "output.csv")
+	if err := os.WriteFile(outPath, []byte(durable+"3,c\n"), 0644); err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+
+	outFile, err := os.OpenFile(outPath, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("failed to open output file: %v", err)
+	}
+	defer outFile.Close()
+
+	pipe, err := NewPipeline(Config{
+		Files:          []string{input},
+		HasHeader:      true,
+		Workers:        2,
+		Processor:      processor.NewDefaultProcessor(),
+		OutputWriter:   outFile,
+		CheckpointFile: checkpointFile,
+		Resume:         true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create pipeline: %v", err)
+	}
+
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline execution failed: %v", err)
+	}
+
+	if got := pipe.Summary().TotalRecords(); got != 2 {
+		t.Errorf("expected 2 records processed on resume, got %d", got)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+
+	rows := strings.Split(strings.TrimSpace(string(data)), "\n")
+	sort.Strings(rows)
+	want := []string{"1,a", "2,b", "3,c", "4,d", "5,e"}
+	if strings.Join(rows, " ") != strings.Join(want, " ") {
+		t.Errorf("expected rows %v, got %v", want, rows)
+	}
+
+	resumed, _ := checkpoint.Open(checkpointFile, true, true)
+	if got := resumed.Through("data.csv"); got != 6 {
+		t.Errorf("expected checkpoint through line 6, got %d", got)
+	}
+}
+
+func TestPipeline_Follow(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	input := filepath.Join(tmpDir, "live.csv")
+	if err := os.WriteFile(input, []byte("id,value\n1,a\n"), 0644); err != nil {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	// Create pipeline
+	outPath := filepath.Join(tmpDir, "output.csv")
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer outFile.Close()
+
 	pipe, err := NewPipeline(Config{
-		Files:        []string{file},
+		Files:        []string{input},
 		HasHeader:    true,
+		Follow:       true,
+		FollowPoll:   10 * time.Millisecond,
 		Workers:      2,
 		Processor:    processor.NewDefaultProcessor(),
-		ShowProgress: false,
+		OutputWriter: outFile,
 	})
-
 	if err != nil {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	// Run pipeline in background
-	done := make(chan error)
+	runErr := make(chan error, 1)
 	go func() {
-		done <- pipe.Run()
+		runErr <- pipe.Run(context.Background())
 	}()
 
-	// Wait until some work has started
-	deadline := time.After(2 * time.Second)
+	f, err := os.OpenFile(input, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open input for append: %v", err)
+	}
+	f.WriteString("2,b\n3,c\n")
+	f.Close()
+
+	// Output is flushed as the pipeline catches up
+	deadline := time.Now().Add(5 * time.Second)
 	for {
-		summary := pipe.Summary()
-		if summary.TotalRecords() > 0 {
+		data, _ := os.ReadFile(outPath)
+		if strings.Count(string(data), "\n") == 3 {
 			break
 		}
-		select {
-		case <-deadline:
-			t.Fatal("pipeline never started processing")
-		default:
-			time.Sleep(1 * time.Millisecond)
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for appended rows, output: %q", data)
 		}
+		time.Sleep(20 * time.Millisecond)
 	}
+
 	pipe.Stop()
 
-	// Wait for completion
 	select {
-	case <-done:
-		// Pipeline stopped
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("pipeline execution failed: %v", err)
+		}
 	case <-time.After(5 * time.Second):
-		t.Error("pipeline did not stop within timeout")
+		t.Fatal("pipeline did not stop")
 	}
 
-	summary := pipe.Summary()
-
-	// Should have processed some records but not all
-	if summary.TotalRecords() == 0 {
-		t.Error("expected some records to be processed")
+	if got := pipe.Summary().TotalRecords(); got != 3 {
+		t.Errorf("expected 3 records, got %d", got)
 	}
 
-	if summary.TotalRecords() >= 10000 {
-		t.Error("pipeline did not stop gracefully")
+	if pipe.Errors().HasErrors() {
+		t.Errorf("expected no errors, got %v", pipe.Errors().Errors())
 	}
-
-	t.Logf("Processed %d records before shutdown", summary.TotalRecords())
 }
 
-func TestPipeline_OutputFile(t *testing.T) {
+func TestPipeline_Metrics(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	inputFile := filepath.Join(tmpDir, "input.csv")
-	outputFile := filepath.Join(tmpDir, "output.csv")
-
-	content := "name,value\ntest1,100\ntest2,200\n"
-	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
-		t.Fatalf("failed to create input file: %v", err)
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte("id,value\n1,a\n2,b\n3\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	// Open output file
-	outFile, err := os.Create(outputFile)
+	outFile, err := os.Create(filepath.Join(tmpDir, "out.csv"))
 	if err != nil {
 		t.Fatalf("failed to create output file: %v", err)
 	}
 	defer outFile.Close()
 
-	// Create and run pipeline
+	registry := metrics.NewRegistry()
+
 	pipe, err := NewPipeline(Config{
-		Files:        []string{inputFile},
+		Files:        []string{file},
 		HasHeader:    true,
 		Workers:      2,
 		Processor:    processor.NewDefaultProcessor(),
 		OutputWriter: outFile,
-		ShowProgress: false,
+		Metrics:      registry,
 	})
-
 	if err != nil {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
-		t.Fatalf("pipeline execution failed: %v", err)
+	if err := pipe.Run(context.Background()); err != nil {
+		t.Fatalf("pipeline run failed: %v", err)
 	}
 
-	// Verify output file was written
-	outFile.Close()
-
-	stat, err := os.Stat(outputFile)
-	if err != nil {
-		t.Fatalf("output file not found: %v", err)
+	var b strings.Builder
+	if err := registry.WriteText(&b); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
 	}
+	text := b.String()
 
-	if stat.Size() == 0 {
-		t.Error("output file is empty")
+	// The ragged third row stops the file with a read error
+	for _, want := range []string{
+		`csvproc_records_read_total{file="data.csv"} 2`,
+		`csvproc_records_processed_total{file="data.csv",status="success"} 2`,
+		`csvproc_output_rows_total 2`,
+		`csvproc_record_duration_seconds_count 2`,
+		`csvproc_errors_total{category=`,
+		`csvproc_workers 2`,
+		`csvproc_active_workers 0`,
+		`csvproc_records_queued 0`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, text)
+		}
 	}
 }
 
@@ -292,6 +1292,42 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "resume without checkpoint file",
+			config: Config{
+				Files:   []string{validFile},
+				Workers: 2,
+				Resume:  true,
+			},
+			expectError: true,
+		},
+		{
+			name: "dashboard progress format",
+			config: Config{
+				Files:          []string{validFile},
+				Workers:        2,
+				ProgressFormat: tracker.FormatDashboard,
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown progress format",
+			config: Config{
+				Files:          []string{validFile},
+				Workers:        2,
+				ProgressFormat: "xml",
+			},
+			expectError: true,
+		},
+		{
+			name: "checkpoint with duplicate file names",
+			config: Config{
+				Files:          []string{validFile, validFile},
+				Workers:        2,
+				CheckpointFile: filepath.Join(tmpDir, "run.checkpoint"),
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -331,6 +1367,6 @@ func BenchmarkPipeline(b *testing.B) {
 			ShowProgress: false,
 		})
 
-		pipe.Run()
+		pipe.Run(context.Background())
 	}
 }
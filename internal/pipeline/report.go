@@ -0,0 +1,296 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/manifest"
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/internal/tracker"
+)
+
+// Run outcomes recorded in RunReport.Status
+const (
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusAborted     = "aborted"
+	StatusInterrupted = "interrupted"
+)
+
+// RunReport is the record of one run written to Config.RunReport, for
+// auditing what was processed and produced
+type RunReport struct {
+	RunID      string    `json:"run_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	// Status is completed, failed (output could not be written), aborted
+	// (error threshold exceeded) or interrupted. ExitCode is Config.ExitCode
+	// of Run's error, and left out when that is not set.
+	Status   string `json:"status"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Config is Config.ReportConfig, or the pipeline's settings when unset
+	Config any `json:"config"`
+
+	Inputs  []InputReport  `json:"inputs"`
+	Outputs []OutputReport `json:"outputs"`
+	Summary SummaryReport  `json:"summary"`
+	Errors  ErrorReport    `json:"errors"`
+}
+
+// InputReport describes one input file. Record counts are by base name, so
+// inputs that share one also share counts.
+type InputReport struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	Records uint64 `json:"records"`
+	Success uint64 `json:"success"`
+	Failed  uint64 `json:"failed"`
+	Skipped uint64 `json:"skipped"`
+}
+
+// OutputReport describes one output file. Rows counts the data rows written
+// by this run; a resumed run's output also holds those of earlier runs. Path
+// is left out when the output is not a file, and size and checksum when it is
+// not a regular file or could not be written in full.
+type OutputReport struct {
+	Path      string `json:"path,omitempty"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+	Rows      uint64 `json:"rows"`
+}
+
+// SummaryReport is the run's Summary
+type SummaryReport struct {
+	Total       int                             `json:"total"`
+	Success     int                             `json:"success"`
+	Failed      int                             `json:"failed"`
+	Skipped     int                             `json:"skipped"`
+	SuccessRate float64                         `json:"success_rate"`
+	Duration    float64                         `json:"duration_seconds"`
+	Throughput  float64                         `json:"throughput"`
+	Latency     map[string]tracker.LatencyStats `json:"latency"`
+}
+
+// ErrorReport is the error collector's ErrorSummary
+type ErrorReport struct {
+	Total      int            `json:"total"`
+	Processed  uint64         `json:"processed"`
+	Rate       float64        `json:"rate"`
+	Retryable  int            `json:"retryable"`
+	ByCategory map[string]int `json:"by_category"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// runError is what Run returns once processing is over: an error when the
+// error threshold aborted the run, or ctx.Err() when it was canceled
+func (p *Pipeline) runError(ctx context.Context) error {
+	if p.aborted() {
+		return fmt.Errorf("processing aborted: error threshold exceeded")
+	}
+
+	return ctx.Err()
+}
+
+// aborted reports whether the error threshold ended the run
+func (p *Pipeline) aborted() bool {
+	return p.config.AbortOnError && p.errorCol.ThresholdExceeded()
+}
+
+// Report builds the run report. runErr is what Run returns.
+func (p *Pipeline) Report(runErr error) RunReport {
+	report := RunReport{
+		RunID:      p.runID,
+		StartedAt:  p.summary.StartTime().UTC(),
+		FinishedAt: p.summary.EndTime().UTC(),
+		Status:     StatusCompleted,
+		Config:     p.config.ReportConfig,
+		Inputs:     p.inputReports(),
+		Outputs:    []OutputReport{},
+	}
+
+	switch {
+	case p.outputErr != nil:
+		report.Status = StatusFailed
+	case p.aborted():
+		report.Status = StatusAborted
+	case p.ctx != nil && p.ctx.Err() != nil:
+		report.Status = StatusInterrupted
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	if p.config.ExitCode != nil {
+		code := p.config.ExitCode(runErr)
+		report.ExitCode = &code
+	}
+
+	if report.Config == nil {
+		report.Config = p.settings()
+	}
+
+	if p.writer != nil {
+		report.Outputs = append(report.Outputs, p.outputReport())
+	}
+
+	report.Summary = SummaryReport{
+		Total:       p.summary.TotalRecords(),
+		Success:     p.summary.SuccessCount(),
+		Failed:      p.summary.FailedCount(),
+		Skipped:     p.summary.SkippedCount(),
+		SuccessRate: p.summary.SuccessRate(),
+		Duration:    p.summary.Duration().Seconds(),
+		Throughput:  p.summary.Throughput(),
+		Latency:     make(map[string]tracker.LatencyStats),
+	}
+
+	latency := p.summary.Latency()
+	for _, name := range latency.Names() {
+		h := latency.Get(name)
+		report.Summary.Latency[name] = tracker.LatencyStats{
+			Count: h.Count(),
+			P50:   h.Quantile(0.50).Seconds(),
+			P90:   h.Quantile(0.90).Seconds(),
+			P99:   h.Quantile(0.99).Seconds(),
+			Max:   h.Max().Seconds(),
+		}
+	}
+
+	errs := p.errorCol.Summary()
+	report.Errors = ErrorReport{
+		Total:      errs.TotalErrors,
+		Processed:  errs.TotalProcessed,
+		Rate:       errs.ErrorRate,
+		Retryable:  errs.RetryableErrors,
+		ByCategory: make(map[string]int),
+		BySeverity: make(map[string]int),
+	}
+	for category, n := range errs.ByCategory {
+		report.Errors.ByCategory[string(category)] = n
+	}
+	for severity, n := range errs.BySeverity {
+		report.Errors.BySeverity[string(severity)] = n
+	}
+
+	return report
+}
+
+// inputReports describes each input with its size, checksum and the counts
+// of its records
+func (p *Pipeline) inputReports() []InputReport {
+	counts := make(map[string]tracker.FileStats)
+	for _, stats := range p.progress.Files() {
+		counts[stats.File] = stats
+	}
+
+	inputs := make([]InputReport, 0, len(p.config.Files))
+	for _, file := range p.config.Files {
+		stats := counts[filepath.Base(file)]
+		input := InputReport{
+			Path:    file,
+			Records: stats.Processed,
+			Success: stats.Success,
+			Failed:  stats.Failed,
+			Skipped: stats.Skipped,
+		}
+
+		if info, err := os.Stat(file); err != nil {
+			input.Error = err.Error()
+		} else if input.SHA256, err = manifest.HashFile(file); err != nil {
+			input.Error = err.Error()
+		} else {
+			input.SizeBytes = info.Size()
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	return inputs
+}
+
+// outputReport describes the output file once it has been flushed
+func (p *Pipeline) outputReport() OutputReport {
+	output := OutputReport{Rows: p.writer.Rows()}
+
+	file, ok := p.outputFile()
+	if !ok {
+		return output
+	}
+	output.Path = file.Name()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() || p.outputErr != nil {
+		return output
+	}
+
+	if hash, err := manifest.HashFile(file.Name()); err == nil {
+		output.SizeBytes = info.Size()
+		output.SHA256 = hash
+	}
+
+	return output
+}
+
+// settings are the pipeline's own options, recorded when the caller gives
+// no ReportConfig
+func (p *Pipeline) settings() map[string]any {
+	c := p.config
+	return map[string]any{
+		"files":               c.Files,
+		"has_header":          c.HasHeader,
+		"validate_header":     c.ValidateHeader,
+		"follow":              c.Follow,
+		"workers":             c.Workers,
+		"processor":           processor.Name(c.Processor),
+		"buffer_size":         c.BufferSize,
+		"foreign_keys":        len(c.ForeignKeys),
+		"max_errors":          c.MaxErrors,
+		"error_threshold":     c.ErrorThreshold,
+		"abort_on_error":      c.AbortOnError,
+		"prescan":             c.Prescan,
+		"checkpoint_file":     c.CheckpointFile,
+		"checkpoint_interval": c.CheckpointInterval.String(),
+		"resume":              c.Resume,
+	}
+}
+
+// writeReport writes the run report to Config.RunReport, replacing any
+// previous report atomically
+func (p *Pipeline) writeReport(runErr error) error {
+	path := p.config.RunReport
+
+	data, err := json.MarshalIndent(p.Report(runErr), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run report: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create run report: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write run report: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close run report: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace run report: %w", err)
+	}
+
+	return nil
+}
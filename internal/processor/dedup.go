@@ -0,0 +1,250 @@
+package processor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// KeepPolicy selects which of a set of duplicate rows survives
+type KeepPolicy string
+
+const (
+	// KeepFirst keeps the earliest row in file and line order
+	KeepFirst KeepPolicy = "first"
+
+	// KeepLast keeps the latest row in file and line order
+	KeepLast KeepPolicy = "last"
+)
+
+// DedupConfig holds configuration for DedupProcessor
+type DedupConfig struct {
+	// Files are scanned to pick survivors; their order defines "first" and "last"
+	Files []string
+
+	// HasHeader indicates the files have a header row
+	HasHeader bool
+
+	// Delimiter separates fields of the files (default: ',')
+	Delimiter rune
+
+	// KeyColumns identify duplicates (default: the full row)
+	KeyColumns []string
+
+	// Keep selects the surviving row (default: first)
+	Keep KeepPolicy
+}
+
+// Duplicate is the ProcessedData of a record removed as a duplicate
+type Duplicate struct {
+	// Survivor is the row kept in place of this one
+	Survivor models.RecordRef
+}
+
+// rowHash identifies a row or key for duplicate detection
+type rowHash [16]byte
+
+// position orders rows by file then line
+type position struct {
+	file int
+	line int
+}
+
+// before reports whether p comes before other in input order
+func (p position) before(other position) bool {
+	if p.file != other.file {
+		return p.file < other.file
+	}
+	return p.line < other.line
+}
+
+// DedupProcessor skips duplicate records, keeping the first or last occurrence.
+// Survivors are chosen by a scan of all files when the processor is created,
+// so the outcome does not depend on the order workers see records in.
+type DedupProcessor struct {
+	config     DedupConfig
+	fileOrder  map[string]int // by path, so files of the same name stay apart
+	survivors  map[rowHash]position
+	duplicates uint64
+}
+
+// NewDedupProcessor scans the files and creates a DedupProcessor
+func NewDedupProcessor(ctx context.Context, config DedupConfig) (*DedupProcessor, error) {
+	if len(config.Files) == 0 {
+		return nil, fmt.Errorf("no input files specified")
+	}
+
+	if len(config.KeyColumns) > 0 && !config.HasHeader {
+		return nil, fmt.Errorf("key columns require files with a header row")
+	}
+
+	switch config.Keep {
+	case "":
+		config.Keep = KeepFirst
+	case KeepFirst, KeepLast:
+	default:
+		return nil, fmt.Errorf("unknown keep policy: %s", config.Keep)
+	}
+
+	p := &DedupProcessor{
+		config:    config,
+		fileOrder: make(map[string]int, len(config.Files)),
+		survivors: make(map[rowHash]position),
+	}
+
+	for i, file := range config.Files {
+		p.fileOrder[file] = i
+	}
+
+	if err := p.scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// scan records the surviving position of every distinct key
+func (p *DedupProcessor) scan(ctx context.Context) error {
+	csvReader := reader.NewCSVReader(reader.Config{
+		Files:     p.config.Files,
+		HasHeader: p.config.HasHeader,
+		Delimiter: p.config.Delimiter,
+	})
+
+	recordCh, errCh := csvReader.Read(ctx)
+
+	var scanErr error
+
+	for record := range recordCh {
+		if scanErr != nil {
+			continue
+		}
+
+		hash, err := p.hash(record)
+		if err != nil {
+			scanErr = err
+			continue
+		}
+
+		pos := p.position(record)
+		current, exists := p.survivors[hash]
+
+		switch {
+		case !exists:
+			p.survivors[hash] = pos
+		case p.config.Keep == KeepFirst && pos.before(current):
+			p.survivors[hash] = pos
+		case p.config.Keep == KeepLast && current.before(pos):
+			p.survivors[hash] = pos
+		}
+	}
+
+	for err := range errCh {
+		if scanErr == nil {
+			scanErr = err
+		}
+	}
+
+	return scanErr
+}
+
+// hash computes the duplicate key of a record
+func (p *DedupProcessor) hash(record *models.Record) (rowHash, error) {
+	h := sha256.New()
+	var length [8]byte
+
+	write := func(field string) {
+		// Length-prefix fields so ("a,b", "c") and ("a", "b,c") differ
+		binary.LittleEndian.PutUint64(length[:], uint64(len(field)))
+		h.Write(length[:])
+		h.Write([]byte(field))
+	}
+
+	if len(p.config.KeyColumns) == 0 {
+		for _, field := range record.Data {
+			write(field)
+		}
+	} else {
+		for _, column := range p.config.KeyColumns {
+			index := columnIndex(record.Headers, column)
+			if index < 0 {
+				return rowHash{}, errors.NewValidationError(column, "", "key column not present in record")
+			}
+			write(record.GetField(index))
+		}
+	}
+
+	var hash rowHash
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
+
+// position returns the input order position of a record
+func (p *DedupProcessor) position(record *models.Record) position {
+	file, ok := p.fileOrder[record.Path]
+	if !ok {
+		// Records not made by the reader have no path, only a file name
+		for i, path := range p.config.Files {
+			if filepath.Base(path) == record.FileName {
+				file = i
+				break
+			}
+		}
+	}
+
+	return position{file: file, line: record.LineNumber}
+}
+
+// Name implements the Named interface
+func (p *DedupProcessor) Name() string {
+	return "dedup"
+}
+
+// Process implements the Processor interface
+func (p *DedupProcessor) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !record.IsValid() {
+		return models.NewFailedResult(record, errors.ErrInvalidRecord, 0), nil
+	}
+
+	hash, err := p.hash(record)
+	if err != nil {
+		return models.NewFailedResult(record, err, 0), nil
+	}
+
+	survivor, ok := p.survivors[hash]
+	if !ok || survivor == p.position(record) {
+		return models.NewSuccessResult(record, record.Data, 0), nil
+	}
+
+	atomic.AddUint64(&p.duplicates, 1)
+
+	return models.NewSkippedResult(record, Duplicate{
+		Survivor: models.RecordRef{
+			FileName:   filepath.Base(p.config.Files[survivor.file]),
+			LineNumber: survivor.line,
+		},
+	}, 0), nil
+}
+
+// Duplicates returns the number of records skipped as duplicates
+func (p *DedupProcessor) Duplicates() uint64 {
+	return atomic.LoadUint64(&p.duplicates)
+}
+
+// Distinct returns the number of distinct keys found by the scan
+func (p *DedupProcessor) Distinct() int {
+	return len(p.survivors)
+}
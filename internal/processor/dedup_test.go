@@ -0,0 +1,201 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+func TestDedupProcessor(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "day1.csv")
+	second := filepath.Join(tmpDir, "day2.csv")
+
+	if err := os.WriteFile(first, []byte("id,value\n1,a\n2,b\n1,c\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("id,value\n2,d\n3,e\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	headers := []string{"id", "value"}
+	records := []*models.Record{
+		models.NewRecord(2, "day1.csv", []string{"1", "a"}, headers),
+		models.NewRecord(3, "day1.csv", []string{"2", "b"}, headers),
+		models.NewRecord(4, "day1.csv", []string{"1", "c"}, headers),
+		models.NewRecord(2, "day2.csv", []string{"2", "d"}, headers),
+		models.NewRecord(3, "day2.csv", []string{"3", "e"}, headers),
+	}
+
+	tests := []struct {
+		name      string
+		keep      KeepPolicy
+		survivors map[models.RecordRef]bool
+		pointer   map[models.RecordRef]models.RecordRef
+	}{
+		{
+			name: "keep first",
+			keep: KeepFirst,
+			survivors: map[models.RecordRef]bool{
+				{FileName: "day1.csv", LineNumber: 2}: true,
+				{FileName: "day1.csv", LineNumber: 3}: true,
+				{FileName: "day2.csv", LineNumber: 3}: true,
+			},
+			pointer: map[models.RecordRef]models.RecordRef{
+				{FileName: "day2.csv", LineNumber: 2}: {FileName: "day1.csv", LineNumber: 3},
+			},
+		},
+		{
+			name: "keep last",
+			keep: KeepLast,
+			survivors: map[models.RecordRef]bool{
+				{FileName: "day1.csv", LineNumber: 4}: true,
+				{FileName: "day2.csv", LineNumber: 2}: true,
+				{FileName: "day2.csv", LineNumber: 3}: true,
+			},
+			pointer: map[models.RecordRef]models.RecordRef{
+				{FileName: "day1.csv", LineNumber: 2}: {FileName: "day1.csv", LineNumber: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := NewDedupProcessor(context.Background(), DedupConfig{
+				Files:      []string{first, second},
+				HasHeader:  true,
+				KeyColumns: []string{"id"},
+				Keep:       tt.keep,
+			})
+			if err != nil {
+				t.Fatalf("NewDedupProcessor() error: %v", err)
+			}
+
+			for _, record := range records {
+				result, err := proc.Process(context.Background(), record)
+				if err != nil {
+					t.Fatalf("Process() error: %v", err)
+				}
+
+				ref := record.Ref()
+				if tt.survivors[ref] != result.IsSuccess() {
+					t.Errorf("%s: status %s, want survivor=%v", ref, result.Status, tt.survivors[ref])
+				}
+
+				if want, ok := tt.pointer[ref]; ok {
+					duplicate, _ := result.ProcessedData.(Duplicate)
+					if duplicate.Survivor != want {
+						t.Errorf("%s: survivor = %s, want %s", ref, duplicate.Survivor, want)
+					}
+				}
+			}
+
+			if proc.Duplicates() != 2 {
+				t.Errorf("expected 2 duplicates, got %d", proc.Duplicates())
+			}
+		})
+	}
+}
+
+func TestDedupProcessor_SameFileName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := filepath.Join(tmpDir, "a", "data.csv")
+	second := filepath.Join(tmpDir, "b", "data.csv")
+
+	for file, content := range map[string]string{
+		first:  "id,value\n1,a\n",
+		second: "id,value\n1,b\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("failed to create test dir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	headers := []string{"id", "value"}
+	firstRecord := models.NewRecord(2, "data.csv", []string{"1", "a"}, headers)
+	firstRecord.Path = first
+	secondRecord := models.NewRecord(2, "data.csv", []string{"1", "b"}, headers)
+	secondRecord.Path = second
+
+	tests := []struct {
+		name     string
+		keep     KeepPolicy
+		survivor *models.Record
+	}{
+		{name: "keep first", keep: KeepFirst, survivor: firstRecord},
+		{name: "keep last", keep: KeepLast, survivor: secondRecord},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := NewDedupProcessor(context.Background(), DedupConfig{
+				Files:      []string{first, second},
+				HasHeader:  true,
+				KeyColumns: []string{"id"},
+				Keep:       tt.keep,
+			})
+			if err != nil {
+				t.Fatalf("NewDedupProcessor() error: %v", err)
+			}
+
+			for _, record := range []*models.Record{firstRecord, secondRecord} {
+				result, err := proc.Process(context.Background(), record)
+				if err != nil {
+					t.Fatalf("Process() error: %v", err)
+				}
+
+				if want := record == tt.survivor; result.IsSuccess() != want {
+					t.Errorf("%s: status %s, want survivor=%v", record.Path, result.Status, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupProcessor_FullRow(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(file, []byte("a,b\n1,2\n1,2\n1,3\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	proc, err := NewDedupProcessor(context.Background(), DedupConfig{
+		Files:     []string{file},
+		HasHeader: true,
+	})
+	if err != nil {
+		t.Fatalf("NewDedupProcessor() error: %v", err)
+	}
+
+	if proc.Distinct() != 2 {
+		t.Errorf("expected 2 distinct rows, got %d", proc.Distinct())
+	}
+}
+
+func TestDedupProcessor_Delimiter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(file, []byte("id;amount\n1;1,5\n1;1,5\n2;1,5\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	proc, err := NewDedupProcessor(context.Background(), DedupConfig{
+		Files:      []string{file},
+		HasHeader:  true,
+		Delimiter:  ';',
+		KeyColumns: []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("NewDedupProcessor() error: %v", err)
+	}
+
+	if proc.Distinct() != 2 {
+		t.Errorf("expected 2 distinct rows, got %d", proc.Distinct())
+	}
+}
@@ -0,0 +1,242 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+// MissingKeyPolicy controls what happens when a record's key has no reference row
+type MissingKeyPolicy string
+
+const (
+	// MissingKeyFail marks the record as failed
+	MissingKeyFail MissingKeyPolicy = "fail"
+
+	// MissingKeySkip marks the record as skipped
+	MissingKeySkip MissingKeyPolicy = "skip"
+
+	// MissingKeyDefault fills the enrichment columns with default values
+	MissingKeyDefault MissingKeyPolicy = "default"
+)
+
+// Reference describes a reference CSV joined onto each record
+type Reference struct {
+	// File is the reference CSV path (must have a header row)
+	File string
+
+	// Delimiter separates the fields of File (default: ',')
+	Delimiter rune
+
+	// KeyColumn is the reference column holding the lookup key
+	KeyColumn string
+
+	// LookupColumn is the record column matched against KeyColumn (default: KeyColumn)
+	LookupColumn string
+
+	// Columns are the reference columns to copy (default: all except KeyColumn)
+	Columns []string
+
+	// Replace overwrites record columns with the same name instead of appending
+	Replace bool
+
+	// Missing is the policy for keys without a reference row (default: fail)
+	Missing MissingKeyPolicy
+
+	// Default is the MissingKeyDefault value for columns not listed in Defaults
+	Default string
+
+	// Defaults holds per-column values used by MissingKeyDefault
+	Defaults map[string]string
+
+	// Index selects in-memory or on-disk indexing (default: memory). The
+	// on-disk index is written to a temporary directory, removed by Close.
+	Index IndexMode
+}
+
+// EnrichConfig holds configuration for EnrichProcessor
+type EnrichConfig struct {
+	// References are applied in order; later references see earlier enrichments
+	References []Reference
+}
+
+// EnrichProcessor appends or replaces columns using reference CSV lookups
+type EnrichProcessor struct {
+	joins []*join
+}
+
+// join is a loaded Reference
+type join struct {
+	ref     Reference
+	table   referenceTable
+	columns []int // indexes into the reference row to copy
+}
+
+// NewEnrichProcessor loads all reference files and creates an EnrichProcessor
+func NewEnrichProcessor(ctx context.Context, config EnrichConfig) (*EnrichProcessor, error) {
+	if len(config.References) == 0 {
+		return nil, fmt.Errorf("no reference files specified")
+	}
+
+	p := &EnrichProcessor{}
+
+	for _, ref := range config.References {
+		j, err := loadJoin(ctx, ref)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("load reference %s: %w", ref.File, err)
+		}
+		p.joins = append(p.joins, j)
+	}
+
+	return p, nil
+}
+
+// loadJoin validates a Reference and loads its table
+func loadJoin(ctx context.Context, ref Reference) (*join, error) {
+	if ref.KeyColumn == "" {
+		return nil, fmt.Errorf("key column is required")
+	}
+	if ref.LookupColumn == "" {
+		ref.LookupColumn = ref.KeyColumn
+	}
+	if ref.Missing == "" {
+		ref.Missing = MissingKeyFail
+	}
+
+	switch ref.Missing {
+	case MissingKeyFail, MissingKeySkip, MissingKeyDefault:
+	default:
+		return nil, fmt.Errorf("unknown missing key policy: %s", ref.Missing)
+	}
+
+	table, err := loadReferenceTable(ctx, ref.File, ref.KeyColumn, ref.Delimiter, ref.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &join{ref: ref, table: table}
+	headers := table.headers()
+
+	if len(ref.Columns) == 0 {
+		for i, header := range headers {
+			if header != ref.KeyColumn {
+				j.columns = append(j.columns, i)
+			}
+		}
+	} else {
+		for _, column := range ref.Columns {
+			index := columnIndex(headers, column)
+			if index < 0 {
+				table.close()
+				return nil, fmt.Errorf("column %q not found", column)
+			}
+			j.columns = append(j.columns, index)
+		}
+	}
+
+	return j, nil
+}
+
+// Name implements the Named interface
+func (p *EnrichProcessor) Name() string {
+	return "enrich"
+}
+
+// Process implements the Processor interface
+func (p *EnrichProcessor) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !record.IsValid() {
+		return models.NewFailedResult(record, errors.ErrInvalidRecord, 0), nil
+	}
+
+	data := append([]string(nil), record.Data...)
+	headers := append([]string(nil), record.Headers...)
+
+	for _, j := range p.joins {
+		lookupIndex := columnIndex(headers, j.ref.LookupColumn)
+		if lookupIndex < 0 {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				j.ref.LookupColumn, "", "lookup column not present in record",
+			), 0), nil
+		}
+
+		key := data[lookupIndex]
+
+		row, found, err := j.table.lookup(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			switch j.ref.Missing {
+			case MissingKeySkip:
+				return models.NewResult(record, models.StatusSkipped, nil), nil
+			case MissingKeyFail:
+				return models.NewFailedResult(record, errors.NewValidationError(
+					j.ref.LookupColumn, key, fmt.Sprintf("no matching %s in %s", j.ref.KeyColumn, j.ref.File),
+				), 0), nil
+			}
+		}
+
+		refHeaders := j.table.headers()
+		for _, column := range j.columns {
+			name := refHeaders[column]
+
+			value := j.ref.Default
+			if found {
+				value = row[column]
+			} else if v, ok := j.ref.Defaults[name]; ok {
+				value = v
+			}
+
+			if target := columnIndex(headers, name); j.ref.Replace && target >= 0 {
+				data[target] = value
+				continue
+			}
+
+			data = append(data, value)
+			headers = append(headers, name)
+		}
+	}
+
+	return models.NewSuccessResult(record, data, 0), nil
+}
+
+// Headers implements the HeaderProvider interface
+func (p *EnrichProcessor) Headers(input []string) []string {
+	headers := append([]string(nil), input...)
+
+	for _, j := range p.joins {
+		refHeaders := j.table.headers()
+		for _, column := range j.columns {
+			name := refHeaders[column]
+			if j.ref.Replace && columnIndex(headers, name) >= 0 {
+				continue
+			}
+			headers = append(headers, name)
+		}
+	}
+
+	return headers
+}
+
+// Close releases resources held by on-disk reference indexes
+func (p *EnrichProcessor) Close() error {
+	var firstErr error
+
+	for _, j := range p.joins {
+		if err := j.table.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
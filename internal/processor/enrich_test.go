@@ -0,0 +1,208 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+func writeReference(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "merchants.csv")
+	content := "id,name,category\nm1,Coffee Shop,food\nm2,\"Books, Inc\",retail\n"
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create reference file: %v", err)
+	}
+
+	return file
+}
+
+func TestEnrichProcessor_Lookup(t *testing.T) {
+	refFile := writeReference(t)
+	headers := []string{"tx", "merchant_id"}
+
+	for _, mode := range []IndexMode{IndexMemory, IndexDisk} {
+		t.Run(string(mode), func(t *testing.T) {
+			proc, err := NewEnrichProcessor(context.Background(), EnrichConfig{
+				References: []Reference{{
+					File:         refFile,
+					KeyColumn:    "id",
+					LookupColumn: "merchant_id",
+					Index:        mode,
+				}},
+			})
+			if err != nil {
+				t.Fatalf("NewEnrichProcessor() error: %v", err)
+			}
+			defer proc.Close()
+
+			record := models.NewRecord(2, "tx.csv", []string{"t1", "m2"}, headers)
+			result, err := proc.Process(context.Background(), record)
+			if err != nil {
+				t.Fatalf("Process() error: %v", err)
+			}
+
+			want := []string{"t1", "m2", "Books, Inc", "retail"}
+			if !reflect.DeepEqual(result.ProcessedData, want) {
+				t.Errorf("ProcessedData = %v, want %v", result.ProcessedData, want)
+			}
+
+			wantHeaders := []string{"tx", "merchant_id", "name", "category"}
+			if got := proc.Headers(headers); !reflect.DeepEqual(got, wantHeaders) {
+				t.Errorf("Headers() = %v, want %v", got, wantHeaders)
+			}
+		})
+	}
+}
+
+func TestEnrichProcessor_MissingKey(t *testing.T) {
+	refFile := writeReference(t)
+	record := models.NewRecord(2, "tx.csv", []string{"t1", "m9"}, []string{"tx", "id"})
+
+	tests := []struct {
+		name   string
+		policy MissingKeyPolicy
+		status models.ProcessingStatus
+		data   interface{}
+	}{
+		{"fail", MissingKeyFail, models.StatusFailed, nil},
+		{"skip", MissingKeySkip, models.StatusSkipped, nil},
+		{"default", MissingKeyDefault, models.StatusSuccess, []string{"t1", "m9", "unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc, err := NewEnrichProcessor(context.Background(), EnrichConfig{
+				References: []Reference{{
+					File:      refFile,
+					KeyColumn: "id",
+					Columns:   []string{"name"},
+					Missing:   tt.policy,
+					Default:   "unknown",
+				}},
+			})
+			if err != nil {
+				t.Fatalf("NewEnrichProcessor() error: %v", err)
+			}
+			defer proc.Close()
+
+			result, err := proc.Process(context.Background(), record)
+			if err != nil {
+				t.Fatalf("Process() error: %v", err)
+			}
+
+			if result.Status != tt.status {
+				t.Errorf("Status = %s, want %s", result.Status, tt.status)
+			}
+
+			if tt.data != nil && !reflect.DeepEqual(result.ProcessedData, tt.data) {
+				t.Errorf("ProcessedData = %v, want %v", result.ProcessedData, tt.data)
+			}
+		})
+	}
+}
+
+func TestEnrichProcessor_Replace(t *testing.T) {
+	refFile := writeReference(t)
+
+	proc, err := NewEnrichProcessor(context.Background(), EnrichConfig{
+		References: []Reference{{
+			File:      refFile,
+			KeyColumn: "id",
+			Columns:   []string{"name"},
+			Replace:   true,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewEnrichProcessor() error: %v", err)
+	}
+	defer proc.Close()
+
+	record := models.NewRecord(2, "tx.csv", []string{"m1", "old"}, []string{"id", "name"})
+	result, err := proc.Process(context.Background(), record)
+	if err != nil {
+		t.Fatalf("Process() error: %v", err)
+	}
+
+	want := []string{"m1", "Coffee Shop"}
+	if !reflect.DeepEqual(result.ProcessedData, want) {
+		t.Errorf("ProcessedData = %v, want %v", result.ProcessedData, want)
+	}
+}
+
+func TestNewEnrichProcessor_UnknownColumn(t *testing.T) {
+	refFile := writeReference(t)
+
+	_, err := NewEnrichProcessor(context.Background(), EnrichConfig{
+		References: []Reference{{
+			File:      refFile,
+			KeyColumn: "missing",
+		}},
+	})
+	if err == nil {
+		t.Error("expected error for unknown key column")
+	}
+}
+
+func TestEnrichProcessor_ReferenceDelimiter(t *testing.T) {
+	refFile := filepath.Join(t.TempDir(), "merchants.csv")
+	content := "id;name\nm1;Coffee Shop\nm2;Books, Inc\n"
+	if err := os.WriteFile(refFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create reference file: %v", err)
+	}
+
+	for _, mode := range []IndexMode{IndexMemory, IndexDisk} {
+		t.Run(string(mode), func(t *testing.T) {
+			proc, err := NewEnrichProcessor(context.Background(), EnrichConfig{
+				References: []Reference{{
+					File:      refFile,
+					KeyColumn: "id",
+					Delimiter: ';',
+					Index:     mode,
+				}},
+			})
+			if err != nil {
+				t.Fatalf("NewEnrichProcessor() error: %v", err)
+			}
+			defer proc.Close()
+
+			record := models.NewRecord(2, "tx.csv", []string{"m2"}, []string{"id"})
+			result, err := proc.Process(context.Background(), record)
+			if err != nil {
+				t.Fatalf("Process() error: %v", err)
+			}
+
+			want := []string{"m2", "Books, Inc"}
+			if !reflect.DeepEqual(result.ProcessedData, want) {
+				t.Errorf("ProcessedData = %v, want %v", result.ProcessedData, want)
+			}
+		})
+	}
+}
+
+func TestLoadKeySet_Delimiter(t *testing.T) {
+	refFile := filepath.Join(t.TempDir(), "customers.csv")
+	if err := os.WriteFile(refFile, []byte("id;name\nc1;Ann\nc2;Bob\n"), 0644); err != nil {
+		t.Fatalf("failed to create reference file: %v", err)
+	}
+
+	keys, err := LoadKeySet(context.Background(), refFile, "id", ';')
+	if err != nil {
+		t.Fatalf("LoadKeySet() error: %v", err)
+	}
+
+	for _, key := range []string{"c1", "c2"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("expected key %q in set", key)
+		}
+	}
+	if _, ok := keys["c1;Ann"]; ok {
+		t.Errorf("expected rows split on ';'")
+	}
+}
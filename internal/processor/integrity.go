@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// ForeignKey declares that every value of Column must exist in RefColumn of RefFile
+type ForeignKey struct {
+	// Column is the record column being checked
+	Column string
+
+	// RefFile is the CSV holding the referenced keys (must have a header row)
+	RefFile string
+
+	// RefColumn is the referenced column (default: Column)
+	RefColumn string
+
+	// Delimiter separates the fields of RefFile (default: ','; a pipeline
+	// uses its own Delimiter)
+	Delimiter rune
+}
+
+// String returns the constraint in column -> file:column form
+func (fk ForeignKey) String() string {
+	return fmt.Sprintf("%s -> %s:%s", fk.Column, filepath.Base(fk.RefFile), fk.refColumn())
+}
+
+// refColumn returns RefColumn, defaulting to Column
+func (fk ForeignKey) refColumn() string {
+	if fk.RefColumn == "" {
+		return fk.Column
+	}
+	return fk.RefColumn
+}
+
+// KeySet is the set of distinct values of a column
+type KeySet map[string]struct{}
+
+// Contains reports whether key is in the set
+func (ks KeySet) Contains(key string) bool {
+	_, ok := ks[key]
+	return ok
+}
+
+// LoadKeySet reads every value of column from a CSV file with the given
+// delimiter (0 = ',') using CSVReader
+func LoadKeySet(ctx context.Context, filename, column string, delimiter rune) (KeySet, error) {
+	csvReader := reader.NewCSVReader(reader.Config{
+		Files:     []string{filename},
+		HasHeader: true,
+		Delimiter: delimiter,
+	})
+
+	recordCh, errCh := csvReader.Read(ctx)
+
+	keys := make(KeySet)
+	keyIndex := -1
+	seen := false
+
+	for record := range recordCh {
+		if !seen {
+			seen = true
+			keyIndex = columnIndex(record.Headers, column)
+		}
+		if keyIndex >= 0 {
+			keys[record.GetField(keyIndex)] = struct{}{}
+		}
+	}
+
+	for err := range errCh {
+		return nil, err
+	}
+
+	if seen && keyIndex < 0 {
+		return nil, fmt.Errorf("column %q not found in %s", column, filename)
+	}
+
+	return keys, nil
+}
+
+// integrityCheck is a loaded ForeignKey
+type integrityCheck struct {
+	fk   ForeignKey
+	keys KeySet
+}
+
+// IntegrityProcessor reports orphan rows before delegating to another processor
+type IntegrityProcessor struct {
+	next     Processor
+	nextName string
+	checks   []integrityCheck
+}
+
+// NewIntegrityProcessor builds the referenced key sets and wraps next.
+// A nil next defaults to DefaultProcessor.
+func NewIntegrityProcessor(ctx context.Context, next Processor, foreignKeys []ForeignKey) (*IntegrityProcessor, error) {
+	if next == nil {
+		next = NewDefaultProcessor()
+	}
+
+	p := &IntegrityProcessor{next: next, nextName: Name(next)}
+
+	// Files referenced by several constraints on the same column are read once
+	loaded := make(map[string]KeySet)
+
+	for _, fk := range foreignKeys {
+		if fk.Column == "" || fk.RefFile == "" {
+			return nil, fmt.Errorf("invalid foreign key %q: column and reference file are required", fk)
+		}
+
+		cacheKey := fk.RefFile + "\x00" + fk.refColumn() + "\x00" + string(fk.Delimiter)
+		keys, ok := loaded[cacheKey]
+		if !ok {
+			var err error
+			keys, err = LoadKeySet(ctx, fk.RefFile, fk.refColumn(), fk.Delimiter)
+			if err != nil {
+				return nil, fmt.Errorf("load keys for %s: %w", fk, err)
+			}
+			loaded[cacheKey] = keys
+		}
+
+		p.checks = append(p.checks, integrityCheck{fk: fk, keys: keys})
+	}
+
+	return p, nil
+}
+
+// Name implements the Named interface
+func (p *IntegrityProcessor) Name() string {
+	return "integrity"
+}
+
+// Process implements the Processor interface; orphan rows are attributed to
+// the integrity check and everything else to the wrapped processor
+func (p *IntegrityProcessor) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
+	for _, check := range p.checks {
+		index := columnIndex(record.Headers, check.fk.Column)
+		if index < 0 {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				check.fk.Column, "", "column not present in record",
+			), 0), nil
+		}
+
+		value := record.GetField(index)
+		if !check.keys.Contains(value) {
+			return models.NewFailedResult(record, errors.NewValidationError(
+				check.fk.Column,
+				value,
+				fmt.Sprintf("orphan row: no matching %s in %s", check.fk.refColumn(), filepath.Base(check.fk.RefFile)),
+			), 0), nil
+		}
+	}
+
+	result, err := p.next.Process(ctx, record)
+	if result != nil && result.Processor == "" {
+		result.Processor = p.nextName
+	}
+	return result, err
+}
@@ -0,0 +1,347 @@
+package processor
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// keyIndexRunBudget is the approximate number of bytes of keys sorted in
+// memory at a time while building a keyIndex
+const keyIndexRunBudget = 16 << 20
+
+// keyEntryOverhead approximates the memory of a keyEntry beyond its key bytes
+const keyEntryOverhead = 40
+
+// keyEntry maps a key to the byte offset of its row
+type keyEntry struct {
+	key    string
+	offset int64
+}
+
+// before orders entries by key, then offset, so the first row of a key wins
+func (e keyEntry) before(other keyEntry) bool {
+	if e.key != other.key {
+		return e.key < other.key
+	}
+	return e.offset < other.offset
+}
+
+// keyIndex maps keys to byte offsets with a file of entries sorted by key,
+// followed by a table of their positions for binary search. Memory use does
+// not depend on the number of keys.
+type keyIndex struct {
+	file  *os.File
+	count int64 // number of entries
+	table int64 // position of the position table
+}
+
+// lookup returns the offset recorded for key
+func (x *keyIndex) lookup(key string) (int64, bool, error) {
+	lo, hi := int64(0), x.count
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		entry, err := x.entry(mid)
+		if err != nil {
+			return 0, false, err
+		}
+
+		switch c := strings.Compare(entry.key, key); {
+		case c == 0:
+			return entry.offset, true, nil
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, false, nil
+}
+
+// entry reads the i-th entry; ReadAt makes it safe for concurrent use
+func (x *keyIndex) entry(i int64) (keyEntry, error) {
+	var buf [8]byte
+	if _, err := x.file.ReadAt(buf[:], x.table+8*i); err != nil {
+		return keyEntry{}, fmt.Errorf("read key index: %w", err)
+	}
+
+	pos := int64(binary.BigEndian.Uint64(buf[:]))
+	entry, err := readKeyEntry(bufio.NewReaderSize(io.NewSectionReader(x.file, pos, x.table-pos), 256))
+	if err != nil {
+		return keyEntry{}, fmt.Errorf("read key index: %w", err)
+	}
+
+	return entry, nil
+}
+
+// close closes the index file
+func (x *keyIndex) close() error {
+	return x.file.Close()
+}
+
+// keyIndexBuilder collects entries into sorted run files in dir and merges
+// them into a keyIndex
+type keyIndexBuilder struct {
+	dir     string
+	budget  int64 // bytes of keys per run (0 = keyIndexRunBudget)
+	pending []keyEntry
+	size    int64
+	runs    []string
+}
+
+// add records the offset of a key's row
+func (b *keyIndexBuilder) add(key string, offset int64) error {
+	b.pending = append(b.pending, keyEntry{key: key, offset: offset})
+	b.size += int64(len(key)) + keyEntryOverhead
+
+	budget := b.budget
+	if budget == 0 {
+		budget = keyIndexRunBudget
+	}
+	if b.size >= budget {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush sorts the pending entries into a new run file
+func (b *keyIndexBuilder) flush() error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	sort.Slice(b.pending, func(i, j int) bool {
+		return b.pending[i].before(b.pending[j])
+	})
+
+	path := filepath.Join(b.dir, fmt.Sprintf("run-%06d", len(b.runs)))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create key index run: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for i, entry := range b.pending {
+		// Later rows of a key are never looked up
+		if i > 0 && entry.key == b.pending[i-1].key {
+			continue
+		}
+		writeKeyEntry(w, entry)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write key index run: %w", err)
+	}
+
+	b.runs = append(b.runs, path)
+	b.pending = b.pending[:0]
+	b.size = 0
+
+	return file.Close()
+}
+
+// finish merges the runs into the index file at path, keeping the first
+// offset of every key, and removes the runs
+func (b *keyIndexBuilder) finish(path string) (*keyIndex, error) {
+	if err := b.flush(); err != nil {
+		return nil, err
+	}
+	b.pending = nil
+
+	defer func() {
+		for _, run := range b.runs {
+			os.Remove(run)
+		}
+	}()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create key index: %w", err)
+	}
+
+	index, err := b.merge(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// merge writes the merged entries to file, then their position table
+func (b *keyIndexBuilder) merge(file *os.File) (*keyIndex, error) {
+	positions, err := os.CreateTemp(b.dir, "positions-")
+	if err != nil {
+		return nil, fmt.Errorf("create key index: %w", err)
+	}
+	defer os.Remove(positions.Name())
+	defer positions.Close()
+
+	h := &entryHeap{}
+	for _, run := range b.runs {
+		f, err := os.Open(run)
+		if err != nil {
+			h.close()
+			return nil, fmt.Errorf("open key index run: %w", err)
+		}
+		cursor := &entryCursor{file: f, reader: bufio.NewReader(f)}
+		if err := cursor.advance(); err != nil {
+			f.Close()
+			h.close()
+			return nil, err
+		}
+		if cursor.done {
+			f.Close()
+			continue
+		}
+		h.cursors = append(h.cursors, cursor)
+	}
+	defer h.close()
+	heap.Init(h)
+
+	w := bufio.NewWriter(file)
+	pw := bufio.NewWriter(positions)
+
+	index := &keyIndex{file: file}
+	var (
+		pos     int64
+		lastKey string
+		buf     [8]byte
+	)
+
+	for h.Len() > 0 {
+		cursor := h.cursors[0]
+		entry := cursor.entry
+
+		if index.count == 0 || entry.key != lastKey {
+			binary.BigEndian.PutUint64(buf[:], uint64(pos))
+			pw.Write(buf[:])
+			pos += int64(writeKeyEntry(w, entry))
+			index.count++
+			lastKey = entry.key
+		}
+
+		if err := cursor.advance(); err != nil {
+			return nil, err
+		}
+		if cursor.done {
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+	}
+
+	if err := pw.Flush(); err != nil {
+		return nil, fmt.Errorf("write key index: %w", err)
+	}
+	if _, err := positions.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("write key index: %w", err)
+	}
+
+	index.table = pos
+	if _, err := io.Copy(w, positions); err != nil {
+		return nil, fmt.Errorf("write key index: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("write key index: %w", err)
+	}
+
+	return index, nil
+}
+
+// writeKeyEntry encodes an entry as key length, key and offset, returning
+// its size
+func writeKeyEntry(w *bufio.Writer, entry keyEntry) int {
+	var buf [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(buf[:], uint64(len(entry.key)))
+	w.Write(buf[:n])
+	w.WriteString(entry.key)
+	size := n + len(entry.key)
+
+	n = binary.PutUvarint(buf[:], uint64(entry.offset))
+	w.Write(buf[:n])
+
+	return size + n
+}
+
+// readKeyEntry decodes an entry written by writeKeyEntry
+func readKeyEntry(r *bufio.Reader) (keyEntry, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return keyEntry{}, err
+	}
+
+	key := make([]byte, n)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return keyEntry{}, err
+	}
+
+	offset, err := binary.ReadUvarint(r)
+	if err != nil {
+		return keyEntry{}, err
+	}
+
+	return keyEntry{key: string(key), offset: int64(offset)}, nil
+}
+
+// entryCursor reads the entries of one run in order
+type entryCursor struct {
+	file   *os.File
+	reader *bufio.Reader
+	entry  keyEntry
+	done   bool
+}
+
+// advance reads the next entry, setting done at the end of the run
+func (c *entryCursor) advance() error {
+	entry, err := readKeyEntry(c.reader)
+	if err == io.EOF {
+		c.done = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read key index run: %w", err)
+	}
+
+	c.entry = entry
+	return nil
+}
+
+// entryHeap orders cursors by their current entry
+type entryHeap struct {
+	cursors []*entryCursor
+}
+
+func (h *entryHeap) Len() int { return len(h.cursors) }
+
+func (h *entryHeap) Less(i, j int) bool {
+	return h.cursors[i].entry.before(h.cursors[j].entry)
+}
+
+func (h *entryHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *entryHeap) Push(x interface{}) { h.cursors = append(h.cursors, x.(*entryCursor)) }
+
+func (h *entryHeap) Pop() interface{} {
+	last := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	last.file.Close()
+	return last
+}
+
+// close closes the files of the remaining cursors
+func (h *entryHeap) close() {
+	for _, cursor := range h.cursors {
+		cursor.file.Close()
+	}
+	h.cursors = nil
+}
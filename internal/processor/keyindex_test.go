@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKeyIndex_Lookup(t *testing.T) {
+	dir := t.TempDir()
+
+	// A small budget spreads the keys over many runs
+	builder := &keyIndexBuilder{dir: dir, budget: 1024}
+	for i := 0; i < 1000; i++ {
+		if err := builder.add(fmt.Sprintf("k%04d", (i*7)%500), int64(i)); err != nil {
+			t.Fatalf("add() error: %v", err)
+		}
+	}
+	if len(builder.runs) < 2 {
+		t.Fatalf("expected several runs, got %d", len(builder.runs))
+	}
+
+	index, err := builder.finish(dir + "/index")
+	if err != nil {
+		t.Fatalf("finish() error: %v", err)
+	}
+	defer index.close()
+
+	if index.count != 500 {
+		t.Errorf("expected 500 keys, got %d", index.count)
+	}
+
+	tests := []struct {
+		key    string
+		offset int64
+		found  bool
+	}{
+		{"k0000", 0, true},
+		{"k0007", 1, true},
+		{"k0499", 357, true}, // 357*7 = 2499, again at 857
+		{"k0500", 0, false},
+		{"", 0, false},
+		{"zzz", 0, false},
+	}
+
+	for _, tt := range tests {
+		offset, found, err := index.lookup(tt.key)
+		if err != nil {
+			t.Fatalf("lookup(%q) error: %v", tt.key, err)
+		}
+		if found != tt.found || offset != tt.offset {
+			t.Errorf("lookup(%q) = %d, %v, want %d, %v", tt.key, offset, found, tt.offset, tt.found)
+		}
+	}
+}
+
+func TestKeyIndex_Empty(t *testing.T) {
+	dir := t.TempDir()
+
+	index, err := (&keyIndexBuilder{dir: dir}).finish(dir + "/index")
+	if err != nil {
+		t.Fatalf("finish() error: %v", err)
+	}
+	defer index.close()
+
+	if _, found, err := index.lookup("k"); found || err != nil {
+		t.Errorf("expected no match, got %v, %v", found, err)
+	}
+}
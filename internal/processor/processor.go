@@ -21,6 +21,35 @@ func (f ProcessorFunc) Process(ctx context.Context, record *models.Record) (*mod
 	return f(ctx, record)
 }
 
+// Named is implemented by processors that report a name for latency statistics
+type Named interface {
+	Name() string
+}
+
+// Name returns the name a processor reports, or "custom" if it reports none
+func Name(p Processor) string {
+	if named, ok := p.(Named); ok {
+		return named.Name()
+	}
+	return "custom"
+}
+
+// workerIDKey is the context key holding the ID of the calling worker
+type workerIDKey struct{}
+
+// WithWorkerID returns a context that tells Process which worker is calling it
+func WithWorkerID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, workerIDKey{}, id)
+}
+
+// WorkerID returns the calling worker's ID, or -1 if ctx has none
+func WorkerID(ctx context.Context) int {
+	if id, ok := ctx.Value(workerIDKey{}).(int); ok {
+		return id
+	}
+	return -1
+}
+
 // DefaultProcessor is a simple processor that just validates records
 type DefaultProcessor struct{}
 
@@ -29,6 +58,11 @@ func NewDefaultProcessor() *DefaultProcessor {
 	return &DefaultProcessor{}
 }
 
+// Name implements the Named interface
+func (p *DefaultProcessor) Name() string {
+	return "default"
+}
+
 // Process implements the Processor interface
 func (p *DefaultProcessor) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
 	// Check context cancellation
@@ -54,6 +88,22 @@ type BatchProcessor interface {
 	ProcessBatch(ctx context.Context, records []*models.Record) ([]*models.Result, error)
 }
 
+// Flusher is implemented by processors that produce their output only after
+// every record has been processed, such as aggregations. The pipeline writes
+// the flushed rows instead of per-record output.
+type Flusher interface {
+	// Flush returns the output header and rows
+	Flush(ctx context.Context) (header []string, rows [][]string, err error)
+}
+
+// HeaderProvider is implemented by processors whose results have different
+// columns than their input records, such as enrichment. The pipeline writes
+// the header it returns before the first output row.
+type HeaderProvider interface {
+	// Headers returns the output column names for records with the given input headers
+	Headers(input []string) []string
+}
+
 // ResultHandler handles processing results
 type ResultHandler interface {
 	// Handle processes a result (e.g., write to output, log errors)
@@ -0,0 +1,246 @@
+package processor
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+)
+
+// IndexMode selects how a reference table is held while enriching
+type IndexMode string
+
+const (
+	// IndexMemory loads every reference row into memory
+	IndexMemory IndexMode = "memory"
+
+	// IndexDisk keeps rows on disk, found through a key -> byte offset index
+	// that is also on disk
+	IndexDisk IndexMode = "disk"
+)
+
+// referenceTable looks up reference rows by key
+type referenceTable interface {
+	// headers returns the reference file column names
+	headers() []string
+
+	// lookup returns the row for key, if present
+	lookup(key string) ([]string, bool, error)
+
+	// close releases any resources held by the table
+	close() error
+}
+
+// loadReferenceTable loads a reference CSV with the given delimiter, indexed by keyColumn
+func loadReferenceTable(ctx context.Context, filename, keyColumn string, delimiter rune, mode IndexMode) (referenceTable, error) {
+	if delimiter == 0 {
+		delimiter = ','
+	}
+
+	switch mode {
+	case "", IndexMemory:
+		return loadMemoryTable(ctx, filename, keyColumn, delimiter)
+	case IndexDisk:
+		return loadDiskTable(ctx, filename, keyColumn, delimiter)
+	default:
+		return nil, fmt.Errorf("unknown index mode: %s", mode)
+	}
+}
+
+// columnIndex returns the position of column in headers, or -1
+func columnIndex(headers []string, column string) int {
+	for i, header := range headers {
+		if header == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// memoryTable holds all reference rows in a map
+type memoryTable struct {
+	header []string
+	rows   map[string][]string
+}
+
+// loadMemoryTable reads the whole reference file with CSVReader
+func loadMemoryTable(ctx context.Context, filename, keyColumn string, delimiter rune) (*memoryTable, error) {
+	csvReader := reader.NewCSVReader(reader.Config{
+		Files:     []string{filename},
+		HasHeader: true,
+		Delimiter: delimiter,
+	})
+
+	recordCh, errCh := csvReader.Read(ctx)
+
+	table := &memoryTable{rows: make(map[string][]string)}
+	keyIndex := -1
+
+	for record := range recordCh {
+		if table.header == nil {
+			table.header = record.Headers
+			keyIndex = columnIndex(record.Headers, keyColumn)
+		}
+		if keyIndex < 0 {
+			continue
+		}
+
+		// First occurrence of a key wins
+		key := record.GetField(keyIndex)
+		if _, exists := table.rows[key]; !exists {
+			table.rows[key] = record.Data
+		}
+	}
+
+	for err := range errCh {
+		return nil, err
+	}
+
+	if table.header == nil {
+		return nil, fmt.Errorf("reference file %s has no records", filename)
+	}
+
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("key column %q not found in %s", keyColumn, filename)
+	}
+
+	return table, nil
+}
+
+func (t *memoryTable) headers() []string {
+	return t.header
+}
+
+func (t *memoryTable) lookup(key string) ([]string, bool, error) {
+	row, ok := t.rows[key]
+	return row, ok, nil
+}
+
+func (t *memoryTable) close() error {
+	return nil
+}
+
+// diskTable keeps the reference file open and reads rows on demand, at
+// offsets found in a keyIndex written to a temporary directory
+type diskTable struct {
+	file      *os.File
+	header    []string
+	delimiter rune
+	dir       string
+	index     *keyIndex
+}
+
+// loadDiskTable scans the reference file once, indexing the byte offset of each key
+func loadDiskTable(ctx context.Context, filename, keyColumn string, delimiter rune) (*diskTable, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("open reference: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "csvref-")
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("create index dir: %w", err)
+	}
+
+	table := &diskTable{
+		file:      file,
+		delimiter: delimiter,
+		dir:       dir,
+	}
+
+	if err := table.build(ctx, filename, keyColumn); err != nil {
+		table.close()
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// build writes the key -> offset index
+func (t *diskTable) build(ctx context.Context, filename, keyColumn string) error {
+	csvReader := csv.NewReader(t.file)
+	csvReader.Comma = t.delimiter
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return errors.ErrEmptyFile
+		}
+		return fmt.Errorf("read reference header: %w", err)
+	}
+	t.header = append([]string(nil), header...)
+
+	keyIndex := columnIndex(t.header, keyColumn)
+	if keyIndex < 0 {
+		return fmt.Errorf("key column %q not found in %s", keyColumn, filename)
+	}
+
+	builder := &keyIndexBuilder{dir: t.dir}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		offset := csvReader.InputOffset()
+
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.NewProcessingError("index_reference", filename, 0, err)
+		}
+
+		if err := builder.add(row[keyIndex], offset); err != nil {
+			return err
+		}
+	}
+
+	t.index, err = builder.finish(filepath.Join(t.dir, "index"))
+	return err
+}
+
+func (t *diskTable) headers() []string {
+	return t.header
+}
+
+func (t *diskTable) lookup(key string) ([]string, bool, error) {
+	offset, ok, err := t.index.lookup(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	// ReadAt is safe for concurrent use, so every lookup gets its own section
+	section := io.NewSectionReader(t.file, offset, 1<<62)
+	csvReader := csv.NewReader(section)
+	csvReader.Comma = t.delimiter
+
+	row, err := csvReader.Read()
+	if err != nil {
+		return nil, false, fmt.Errorf("read reference row: %w", err)
+	}
+
+	return row, true, nil
+}
+
+func (t *diskTable) close() error {
+	err := t.file.Close()
+	if t.index != nil {
+		t.index.close()
+	}
+	os.RemoveAll(t.dir)
+	return err
+}
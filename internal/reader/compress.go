@@ -0,0 +1,60 @@
+package reader
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// IsCompressed reports whether path names a gzip-compressed file, which the
+// reader decompresses as it goes
+func IsCompressed(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gz")
+}
+
+// decompress returns r decompressed when path names a compressed file, and r
+// itself otherwise
+func decompress(path string, r io.Reader) (io.Reader, error) {
+	if !IsCompressed(path) {
+		return r, nil
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open gzip: %w", err)
+	}
+	return gz, nil
+}
+
+// uncompressedSize returns the size of a file's content once decompressed.
+// For gzip it is read from the trailer, which holds the size modulo 4GiB, so
+// it is only an estimate for larger content.
+func uncompressedSize(file *os.File, size int64) int64 {
+	if !IsCompressed(file.Name()) || size < 4 {
+		return size
+	}
+
+	var trailer [4]byte
+	if _, err := file.ReadAt(trailer[:], size-4); err != nil {
+		return size
+	}
+	return int64(binary.LittleEndian.Uint32(trailer[:]))
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+// Read implements io.Reader
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,159 @@
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeGzip writes content to path gzip-compressed
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+}
+
+func TestCSVReader_Compressed(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var rows strings.Builder
+	rows.WriteString("id,name\n")
+	for i := 1; i <= 500; i++ {
+		fmt.Fprintf(&rows, "%d,name-%d\n", i, i)
+	}
+
+	compressed := filepath.Join(tmpDir, "data.csv.GZ")
+	writeGzip(t, compressed, rows.String())
+
+	plain := filepath.Join(tmpDir, "plain.csv")
+	if err := os.WriteFile(plain, []byte("id,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	reader := NewCSVReader(Config{
+		Files:     []string{compressed, plain},
+		HasHeader: true,
+	})
+
+	// Sizes are known before reading starts
+	if _, size := reader.Progress(); size == 0 {
+		t.Error("expected total size before reading")
+	}
+
+	recordCh, errCh := reader.Read(context.Background())
+
+	counts := make(map[string]int)
+	var last string
+	for record := range recordCh {
+		counts[record.FileName]++
+		if record.FileName == "data.csv.GZ" {
+			last = record.Data[1]
+		}
+	}
+	for err := range errCh {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if counts["data.csv.GZ"] != 500 || counts["plain.csv"] != 1 {
+		t.Errorf("expected 500 and 1 records, got %v", counts)
+	}
+	if last != "name-500" {
+		t.Errorf("expected last record name-500, got %q", last)
+	}
+
+	// Compressed files are measured in compressed bytes
+	stat, _ := os.Stat(compressed)
+	read, size := reader.FileProgress(compressed)
+	if size != stat.Size() || read != size {
+		t.Errorf("expected %d of %d bytes read, got %d of %d", stat.Size(), stat.Size(), read, size)
+	}
+
+	totalRead, totalSize := reader.Progress()
+	if totalRead != totalSize {
+		t.Errorf("expected all bytes read, got %d of %d", totalRead, totalSize)
+	}
+
+	if read, size := reader.FileProgress("missing.csv"); read != 0 || size != 0 {
+		t.Errorf("expected no progress for unknown file, got %d of %d", read, size)
+	}
+}
+
+func TestCountFiles_Compressed(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	compressed := filepath.Join(tmpDir, "data.csv.gz")
+	writeGzip(t, compressed, "id,name\n1,a\n2,\"b\nc\"\n3,d\n")
+
+	plain := filepath.Join(tmpDir, "plain.csv")
+	if err := os.WriteFile(plain, []byte("id,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// Not gzip despite the extension
+	corrupt := filepath.Join(tmpDir, "corrupt.csv.gz")
+	if err := os.WriteFile(corrupt, []byte("id,name\n1,a\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	counts, errs := CountFiles(context.Background(), []string{compressed, plain, corrupt}, true)
+
+	if errs[0] != nil || counts[0] != 3 {
+		t.Errorf("expected 3 records in compressed file, got %d (%v)", counts[0], errs[0])
+	}
+	if errs[1] != nil || counts[1] != 1 {
+		t.Errorf("expected 1 record in plain file, got %d (%v)", counts[1], errs[1])
+	}
+	if errs[2] == nil {
+		t.Error("expected error for corrupt gzip file")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, errs := CountFiles(ctx, []string{plain}, true); errs[0] != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", errs[0])
+	}
+}
+
+func TestInspect_Compressed(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var rows strings.Builder
+	rows.WriteString("id,name\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&rows, "%04d,name-%04d\n", i, i)
+	}
+
+	path := filepath.Join(tmpDir, "data.csv.gz")
+	writeGzip(t, path, rows.String())
+
+	info, err := Inspect(path, true, 0, 100)
+	if err != nil {
+		t.Fatalf("Inspect() error: %v", err)
+	}
+
+	if info.DataSize != int64(rows.Len()) {
+		t.Errorf("expected data size %d, got %d", rows.Len(), info.DataSize)
+	}
+	if info.Header[0] != "id" || len(info.Sample) != 100 {
+		t.Errorf("expected header and 100 sampled records, got %v and %d", info.Header, len(info.Sample))
+	}
+	if got := info.EstimatedRows(); got != 1000 {
+		t.Errorf("expected 1000 estimated rows, got %d", got)
+	}
+}
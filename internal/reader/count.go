@@ -0,0 +1,116 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+)
+
+// countBufferSize is the read size used by CountRecords
+const countBufferSize = 256 * 1024
+
+// CountFiles counts the records of several files in parallel, at most one
+// file per CPU at a time. counts and errs line up with files. Canceling ctx
+// stops the counts that are still running with ctx.Err().
+func CountFiles(ctx context.Context, files []string, hasHeader bool) (counts []int64, errs []error) {
+	counts = make([]int64, len(files))
+	errs = make([]error, len(files))
+
+	slots := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			counts[i], errs[i] = countRecords(ctx, file, hasHeader)
+		}(i, file)
+	}
+	wg.Wait()
+
+	return counts, errs
+}
+
+// CountRecords counts the data records in a file without parsing fields. It
+// scans for line breaks outside quoted fields and skips empty lines, as
+// encoding/csv does, so quoted fields may contain newlines. The header, when
+// present, is not counted. Compressed files are decompressed as they are read.
+func CountRecords(path string, hasHeader bool) (int64, error) {
+	return countRecords(context.Background(), path, hasHeader)
+}
+
+// countRecords is CountRecords, stopping early when ctx is canceled
+func countRecords(ctx context.Context, path string, hasHeader bool) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, errors.ErrFileNotFound
+		}
+		return 0, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	input, err := decompress(path, file)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	quoted := false
+	lineHasData := false
+
+	buf := make([]byte, countBufferSize)
+	for {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		n, err := input.Read(buf)
+		for _, b := range buf[:n] {
+			switch b {
+			case '"':
+				quoted = !quoted
+				lineHasData = true
+			case '\n':
+				if !quoted && lineHasData {
+					count++
+					lineHasData = false
+				}
+			case '\r':
+				// Part of a CRLF line ending unless quoted
+				if quoted {
+					lineHasData = true
+				}
+			default:
+				lineHasData = true
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read file: %w", err)
+		}
+	}
+
+	// Last record without a trailing newline
+	if lineHasData {
+		count++
+	}
+
+	if hasHeader && count > 0 {
+		count--
+	}
+
+	return count, nil
+}
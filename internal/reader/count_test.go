@@ -0,0 +1,50 @@
+package reader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+)
+
+func TestCountRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		hasHeader bool
+		want      int64
+	}{
+		{"simple", "id,name\n1,a\n2,b\n", true, 2},
+		{"no trailing newline", "id,name\n1,a\n2,b", true, 2},
+		{"no header", "1,a\n2,b\n3,c\n", false, 3},
+		{"crlf", "id,name\r\n1,a\r\n2,b\r\n", true, 2},
+		{"blank lines", "id,name\n\n1,a\n\r\n2,b\n\n", true, 2},
+		{"quoted newline", "id,note\n1,\"line one\nline two\"\n2,\"a \"\"quoted\"\"\nvalue\"\n", true, 2},
+		{"header only", "id,name\n", true, 0},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, fmt.Sprintf("file%d.csv", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			got, err := CountRecords(path, tt.hasHeader)
+			if err != nil {
+				t.Fatalf("CountRecords() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d records, got %d", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := CountRecords(filepath.Join(tmpDir, "missing.csv"), true); err != errors.ErrFileNotFound {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+}
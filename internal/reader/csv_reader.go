@@ -5,12 +5,16 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/zuhrulumam/csv_processor/internal/errors"
 	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/tracing"
 )
 
 // CSVReader reads CSV files concurrently and sends records to a channel
@@ -26,6 +30,35 @@ type CSVReader struct {
 
 	// bufferSize is the size of the output channel buffer
 	bufferSize int
+
+	// skip reports records that should not be sent, e.g. completed by a previous run
+	skip func(file string, line int) bool
+
+	// delimiter separates fields
+	delimiter rune
+
+	// variableFields passes records whose field count differs from the header
+	variableFields bool
+
+	// follow keeps files open at EOF and waits for appended rows
+	follow       bool
+	pollInterval time.Duration
+
+	// progress counts the bytes read from each file, keyed by path
+	progress map[string]*byteProgress
+
+	// logger receives file lifecycle events at debug level
+	logger *slog.Logger
+
+	// tracer records a span per file and batch spans of parsing and queue waits
+	tracer *tracing.Tracer
+}
+
+// byteProgress is how much of one file has been read. For compressed files
+// both counts are of compressed bytes.
+type byteProgress struct {
+	read int64
+	size int64
 }
 
 // Config holds configuration for CSVReader
@@ -34,6 +67,31 @@ type Config struct {
 	HasHeader      bool
 	ValidateHeader bool
 	BufferSize     int
+
+	// Delimiter separates fields (default: ',')
+	Delimiter rune
+
+	// VariableFields sends records whose field count differs from the first
+	// row instead of stopping the file at the first one; callers check
+	// Record.IsValid
+	VariableFields bool
+
+	// Skip is called with the file base name and line number of every record;
+	// records it returns true for are read but not sent
+	Skip func(file string, line int) bool
+
+	// Follow keeps reading files as rows are appended, following truncation
+	// and rotation, until the context is canceled. PollInterval is how often
+	// to check for new data at EOF (default: 500ms).
+	Follow       bool
+	PollInterval time.Duration
+
+	// Logger receives file lifecycle events (default: discard)
+	Logger *slog.Logger
+
+	// Tracer, if set, records a span for each file read, with batch spans
+	// of parsing and of waits to send records on
+	Tracer *tracing.Tracer
 }
 
 // NewCSVReader creates a new CSVReader instance
@@ -41,13 +99,55 @@ func NewCSVReader(config Config) *CSVReader {
 	if config.BufferSize == 0 {
 		config.BufferSize = 100 // Default buffer size
 	}
+	if config.Delimiter == 0 {
+		config.Delimiter = ','
+	}
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	// Sizes are taken up front so progress covers files not opened yet
+	progress := make(map[string]*byteProgress, len(config.Files))
+	for _, file := range config.Files {
+		progress[file] = &byteProgress{}
+		if stat, err := os.Stat(file); err == nil {
+			progress[file].size = stat.Size()
+		}
+	}
 
 	return &CSVReader{
+		progress:       progress,
 		files:          config.Files,
 		hasHeader:      config.HasHeader,
 		validateHeader: config.ValidateHeader,
 		bufferSize:     config.BufferSize,
+		delimiter:      config.Delimiter,
+		variableFields: config.VariableFields,
+		skip:           config.Skip,
+		follow:         config.Follow,
+		pollInterval:   config.PollInterval,
+		logger:         config.Logger,
+		tracer:         config.Tracer,
+	}
+}
+
+// FileProgress returns how many bytes of file have been read and its size.
+// Compressed files are measured in compressed bytes.
+func (r *CSVReader) FileProgress(file string) (read, size int64) {
+	progress, ok := r.progress[file]
+	if !ok {
+		return 0, 0
+	}
+	return atomic.LoadInt64(&progress.read), atomic.LoadInt64(&progress.size)
+}
+
+// Progress returns the bytes read and the total size over all files
+func (r *CSVReader) Progress() (read, size int64) {
+	for _, progress := range r.progress {
+		read += atomic.LoadInt64(&progress.read)
+		size += atomic.LoadInt64(&progress.size)
 	}
+	return read, size
 }
 
 // Read reads all CSV files concurrently and sends records to the output channel
@@ -122,39 +222,118 @@ func (r *CSVReader) readFile(
 	}
 	defer file.Close()
 
-	// Check if file is empty
+	// Check if file is empty; a followed file may still be written to
 	stat, err := file.Stat()
 	if err != nil {
 		return nil, fmt.Errorf("stat file: %w", err)
 	}
-	if stat.Size() == 0 {
+	if stat.Size() == 0 && !r.follow {
 		return nil, errors.ErrEmptyFile
 	}
 
-	// Create CSV reader
-	csvReader := csv.NewReader(file)
-	csvReader.ReuseRecord = true // Optimize memory allocation
+	progress := r.progress[filename]
+	atomic.StoreInt64(&progress.size, stat.Size())
+
+	r.logger.Debug("reading file", "file", filename, "size", stat.Size(), "compressed", IsCompressed(filename))
+
+	// Each file is read on its own lane; records are traced in batches
+	lane := r.tracer.Lane("read " + filepath.Base(filename))
+	span := r.tracer.Start(lane, "read file", "read")
+	span.SetArg("file", filename)
+	defer span.End()
 
-	var headers []string
 	lineNumber := 0
+	defer func() { span.SetArg("lines", lineNumber) }()
+
+	batch := r.tracer.Batch(lane, "parse batch", "read", tracing.DefaultBatchSize)
+	defer batch.Flush()
+
+	var input io.Reader = countingReader{r: file, n: &progress.read}
+	if r.follow {
+		if IsCompressed(filename) {
+			return nil, fmt.Errorf("cannot follow compressed file")
+		}
+		follower := newFollowReader(ctx, filename, file, r.pollInterval)
+		defer follower.Close()
+		input = follower
+	}
+
+	input, err = decompress(filename, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []string
+	var csvReader *csv.Reader
+
+	// start begins parsing the current content, reading the header if present
+	start := func() error {
+		// Create CSV reader
+		csvReader = csv.NewReader(input)
+		csvReader.ReuseRecord = true // Optimize memory allocation
+		csvReader.Comma = r.delimiter
+		if r.variableFields {
+			csvReader.FieldsPerRecord = -1
+		}
+		lineNumber = 0
+
+		if !r.hasHeader {
+			return nil
+		}
 
-	// Read header if present
-	if r.hasHeader {
 		rawHeaders, err := csvReader.Read()
 		if err != nil {
 			if err == io.EOF {
-				return nil, errors.ErrEmptyFile
+				return errors.ErrEmptyFile
 			}
-			return nil, fmt.Errorf("read header: %w", err)
+			return err
 		}
 		lineNumber++
+
+		// A rotated or truncated file must keep the same columns
+		if headers != nil {
+			if !headersMatch(headers, rawHeaders) {
+				return errors.NewProcessingError("validate_header", filename, lineNumber, errors.ErrHeaderMismatch)
+			}
+			return nil
+		}
+
 		headers = make([]string, len(rawHeaders))
 		copy(headers, rawHeaders)
 
 		// Validate header
 		if err := validateHeaders(headers); err != nil {
-			return nil, errors.NewProcessingError("validate_header", filename, lineNumber, err)
+			return errors.NewProcessingError("validate_header", filename, lineNumber, err)
 		}
+
+		return nil
+	}
+
+	// done ends a read; in follow mode cancellation is the normal way to stop
+	done := func(err error) ([]string, error) {
+		if r.follow && ctx.Err() != nil {
+			return headers, nil
+		}
+		return headers, err
+	}
+
+	// Read header if present, starting over if a followed file is reset meanwhile
+	err = start()
+	for isReset(err) {
+		r.logger.Info("file truncated or rotated, reading from the start", "file", filename)
+		err = start()
+	}
+	if err != nil {
+		if err == errors.ErrEmptyFile {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return done(ctx.Err())
+		}
+		if _, ok := err.(*errors.ProcessingError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("read header: %w", err)
 	}
 
 	// Read records
@@ -162,7 +341,7 @@ func (r *CSVReader) readFile(
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
-			return headers, ctx.Err()
+			return done(ctx.Err())
 		default:
 		}
 
@@ -171,12 +350,28 @@ func (r *CSVReader) readFile(
 		if err == io.EOF {
 			break
 		}
+		if isReset(err) {
+			// Start over on the new content
+			r.logger.Info("file truncated or rotated, reading from the start", "file", filename)
+			if err := start(); err != nil && !isReset(err) {
+				return done(err)
+			}
+			continue
+		}
 		if err != nil {
+			if ctx.Err() != nil {
+				return done(ctx.Err())
+			}
 			return headers, errors.NewProcessingError("read_record", filename, lineNumber+1, err)
 		}
 
 		lineNumber++
 
+		if r.skip != nil && r.skip(filepath.Base(filename), lineNumber) {
+			batch.Work("parse")
+			continue
+		}
+
 		// Create a copy of data (since we're using ReuseRecord)
 		dataCopy := make([]string, len(data))
 		copy(dataCopy, data)
@@ -188,15 +383,22 @@ func (r *CSVReader) readFile(
 			dataCopy,
 			headers,
 		)
+		record.Path = filename
+
+		batch.Work("parse")
 
 		// Send record to channel (with context cancellation check)
 		select {
 		case <-ctx.Done():
-			return headers, ctx.Err()
+			return done(ctx.Err())
 		case recordCh <- record:
 		}
+		batch.Wait("queue wait")
+		batch.Done()
 	}
 
+	r.logger.Debug("finished file", "file", filename, "lines", lineNumber)
+
 	return headers, nil
 }
 
@@ -1,10 +1,13 @@
 package reader
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -224,6 +227,73 @@ func TestCSVReader_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestCSVReader_DelimiterAndVariableFields(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "ragged.csv")
+	content := "name;age\nAlice;30\nBob\nCarol;41;extra\nDave;28\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		variableFields bool
+		expectedCount  int
+		expectedValid  int
+		expectError    bool
+	}{
+		{
+			name:          "stops at first ragged record",
+			expectedCount: 1,
+			expectedValid: 1,
+			expectError:   true,
+		},
+		{
+			name:           "variable fields",
+			variableFields: true,
+			expectedCount:  4,
+			expectedValid:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewCSVReader(Config{
+				Files:          []string{file},
+				HasHeader:      true,
+				Delimiter:      ';',
+				VariableFields: tt.variableFields,
+			})
+
+			recordCh, errCh := reader.Read(context.Background())
+
+			count, valid := 0, 0
+			for record := range recordCh {
+				count++
+				if record.IsValid() {
+					valid++
+				}
+			}
+
+			var errs []error
+			for err := range errCh {
+				errs = append(errs, err)
+			}
+
+			if count != tt.expectedCount {
+				t.Errorf("expected %d records, got %d", tt.expectedCount, count)
+			}
+			if valid != tt.expectedValid {
+				t.Errorf("expected %d valid records, got %d", tt.expectedValid, valid)
+			}
+			if (len(errs) > 0) != tt.expectError {
+				t.Errorf("expected error %v, got %v", tt.expectError, errs)
+			}
+		})
+	}
+}
+
 func TestReadSingle(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -254,6 +324,39 @@ func TestReadSingle(t *testing.T) {
 	}
 }
 
+func TestCSVReader_Logger(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(file, []byte("name,value\ntest1,100\ntest2,200\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	reader := NewCSVReader(Config{
+		Files:     []string{file},
+		HasHeader: true,
+		Logger:    slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	recordCh, errCh := reader.Read(context.Background())
+	for range recordCh {
+	}
+	for err := range errCh {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		fmt.Sprintf(`msg="reading file" file=%s size=31 compressed=false`, file),
+		fmt.Sprintf(`msg="finished file" file=%s lines=3`, file),
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected %q in logs, got:\n%s", want, logs)
+		}
+	}
+}
+
 func BenchmarkCSVReader(b *testing.B) {
 	tmpDir := b.TempDir()
 
@@ -0,0 +1,123 @@
+package reader
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"os"
+	"time"
+)
+
+// errFileReset is returned by followReader when the file was truncated or
+// replaced and reading restarts from the beginning of the new content
+var errFileReset = stderrors.New("file truncated or rotated")
+
+// isReset reports whether err signals a truncated or rotated file
+func isReset(err error) bool {
+	return stderrors.Is(err, errFileReset)
+}
+
+// followReader reads a file like tail -F: at EOF it waits for more data
+// instead of returning io.EOF, and it reopens the path when the file is
+// truncated or rotated. Data is only handed to the CSV parser as it is
+// appended, so a partially written line waits until its newline arrives.
+type followReader struct {
+	ctx  context.Context
+	path string
+	file *os.File
+	poll time.Duration
+
+	// offset is the number of bytes read from the current file
+	offset int64
+}
+
+// newFollowReader creates a followReader positioned at the start of file
+func newFollowReader(ctx context.Context, path string, file *os.File, poll time.Duration) *followReader {
+	if poll <= 0 {
+		poll = 500 * time.Millisecond
+	}
+
+	return &followReader{
+		ctx:  ctx,
+		path: path,
+		file: file,
+		poll: poll,
+	}
+}
+
+// Read implements io.Reader
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.file.Read(p)
+		f.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		reset, err := f.checkReset()
+		if err != nil {
+			return 0, err
+		}
+		if reset {
+			return 0, errFileReset
+		}
+
+		select {
+		case <-f.ctx.Done():
+			return 0, f.ctx.Err()
+		case <-time.After(f.poll):
+		}
+	}
+}
+
+// checkReset switches to the new content when the path was truncated or now
+// names a different file. It is only called at EOF, so everything written to
+// a rotated file before the switch has been read.
+func (f *followReader) checkReset() (bool, error) {
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		// Rotated away and not yet recreated; keep waiting
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(info, current) {
+		file, err := os.Open(f.path)
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		f.file.Close()
+		f.file = file
+		f.offset = 0
+		return true, nil
+	}
+
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.offset = 0
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Close closes the file currently being followed
+func (f *followReader) Close() error {
+	return f.file.Close()
+}
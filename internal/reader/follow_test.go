@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+func TestCSVReader_Follow(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "live.csv")
+
+	if err := os.WriteFile(path, []byte("id,value\n1,a\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader := NewCSVReader(Config{
+		Files:        []string{path},
+		HasHeader:    true,
+		Follow:       true,
+		PollInterval: 10 * time.Millisecond,
+	})
+
+	recordCh, errCh := reader.Read(ctx)
+
+	expect := func(id string, line int) {
+		t.Helper()
+		select {
+		case record := <-recordCh:
+			if record.Data[0] != id || record.LineNumber != line {
+				t.Fatalf("expected id %s at line %d, got %v at line %d", id, line, record.Data, record.LineNumber)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for id %s", id)
+		}
+	}
+
+	expectNone := func() {
+		t.Helper()
+		select {
+		case record := <-recordCh:
+			t.Fatalf("unexpected record %v", record.Data)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	appendTo := func(content string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("failed to open for append: %v", err)
+		}
+		defer f.Close()
+		f.WriteString(content)
+	}
+
+	expect("1", 2)
+
+	// A partial line waits for its newline
+	appendTo("2,b")
+	expectNone()
+	appendTo("\n")
+	expect("2", 3)
+
+	// Truncation restarts from the top, skipping the header again
+	if err := os.WriteFile(path, []byte("id,value\n3,c\n"), 0644); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	expect("3", 2)
+
+	// Rotation switches to the new file
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("failed to rotate: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("id,value\n4,d\n"), 0644); err != nil {
+		t.Fatalf("failed to create rotated file: %v", err)
+	}
+	expect("4", 2)
+
+	cancel()
+
+	var remaining []*models.Record
+	for record := range recordCh {
+		remaining = append(remaining, record)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("expected no further records, got %d", len(remaining))
+	}
+
+	for err := range errCh {
+		t.Errorf("expected cancellation to end follow cleanly, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package reader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+)
+
+// sniffBytes is how much of a file is examined to guess its dialect
+const sniffBytes = 64 * 1024
+
+// candidateDelimiters are the delimiters Inspect recognizes
+var candidateDelimiters = []rune{',', ';', '\t', '|'}
+
+// FileInfo describes a CSV file from its first records, without reading all of it
+type FileInfo struct {
+	Path string
+	Size int64
+
+	// DataSize is the size of the content, which for a compressed file is
+	// its uncompressed size as recorded in the file (modulo 4GiB for gzip)
+	DataSize int64
+
+	// Header is the first row when the file has a header
+	Header []string
+
+	// HeaderErr is why Header would be rejected by the reader, if it would be
+	HeaderErr error
+
+	// Delimiter is the most likely field delimiter, from the first lines
+	Delimiter rune
+
+	// BOM is set when the file starts with a UTF-8 byte order mark
+	BOM bool
+
+	// CRLF is set when lines end with \r\n
+	CRLF bool
+
+	// Sample holds up to the requested number of data records
+	Sample [][]string
+
+	// SampleErr is the parse error that ended the sample early, if any
+	SampleErr error
+
+	// HeaderBytes and SampleBytes are the bytes taken by the header and the sampled records
+	HeaderBytes int64
+	SampleBytes int64
+
+	// Complete is set when the sample covers the whole file
+	Complete bool
+
+	// ParseTime is how long parsing the sample took
+	ParseTime time.Duration
+}
+
+// Inspect reads a file's header and up to sampleSize records the way the
+// reader would with delimiter (0 = ','), and guesses its dialect
+func Inspect(path string, hasHeader bool, delimiter rune, sampleSize int) (*FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	info := &FileInfo{Path: path, Size: stat.Size(), Delimiter: ','}
+	if info.Size == 0 {
+		return nil, errors.ErrEmptyFile
+	}
+	info.DataSize = uncompressedSize(file, info.Size)
+
+	input, err := decompress(path, file)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReaderSize(input, sniffBytes)
+	head, _ := buffered.Peek(sniffBytes)
+	info.BOM = bytes.HasPrefix(head, []byte("\xef\xbb\xbf"))
+	info.CRLF = bytes.Contains(head, []byte("\r\n"))
+	info.Delimiter = sniffDelimiter(head)
+
+	start := time.Now()
+	csvReader := csv.NewReader(buffered)
+	if delimiter != 0 {
+		csvReader.Comma = delimiter
+	}
+
+	if hasHeader {
+		header, err := csvReader.Read()
+		if err == io.EOF {
+			return nil, errors.ErrEmptyFile
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read header: %w", err)
+		}
+		info.Header = append([]string(nil), header...)
+		info.HeaderErr = validateHeaders(info.Header)
+		info.HeaderBytes = csvReader.InputOffset()
+	}
+
+	for len(info.Sample) < sampleSize {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			info.Complete = true
+			break
+		}
+		if err != nil {
+			info.SampleErr = err
+			break
+		}
+		info.Sample = append(info.Sample, record)
+	}
+
+	info.ParseTime = time.Since(start)
+	info.SampleBytes = csvReader.InputOffset() - info.HeaderBytes
+
+	return info, nil
+}
+
+// EstimatedRows returns the number of data records, exact when the sample
+// covers the file and otherwise extrapolated from the sampled record size
+func (f *FileInfo) EstimatedRows() int64 {
+	if f.Complete || len(f.Sample) == 0 || f.SampleBytes == 0 {
+		return int64(len(f.Sample))
+	}
+
+	perRecord := float64(f.SampleBytes) / float64(len(f.Sample))
+	return int64(float64(f.DataSize-f.HeaderBytes) / perRecord)
+}
+
+// ParseRate returns the sampled parse rate in records per second
+func (f *FileInfo) ParseRate() float64 {
+	if f.ParseTime <= 0 {
+		return 0
+	}
+	return float64(len(f.Sample)) / f.ParseTime.Seconds()
+}
+
+// sniffDelimiter picks the candidate that appears the same nonzero number of
+// times, outside quotes, on the most lines; ties go to the earlier candidate
+func sniffDelimiter(head []byte) rune {
+	// Drop a trailing partial line
+	if i := bytes.LastIndexByte(head, '\n'); i >= 0 && len(head) == sniffBytes {
+		head = head[:i]
+	}
+
+	lines := bytes.Split(head, []byte("\n"))
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+
+	best, bestScore := ',', 0
+	for _, delim := range candidateDelimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			if n := countOutsideQuotes(line, byte(delim)); n > 0 {
+				counts[n]++
+			}
+		}
+
+		score := 0
+		for _, lines := range counts {
+			if lines > score {
+				score = lines
+			}
+		}
+
+		if score > bestScore {
+			best, bestScore = delim, score
+		}
+	}
+
+	return best
+}
+
+// countOutsideQuotes counts occurrences of c outside double-quoted fields
+func countOutsideQuotes(line []byte, c byte) int {
+	count := 0
+	quoted := false
+	for _, b := range line {
+		switch {
+		case b == '"':
+			quoted = !quoted
+		case b == c && !quoted:
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,142 @@
+package reader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/errors"
+)
+
+func TestInspect(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var rows strings.Builder
+	rows.WriteString("id,name,amount\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&rows, "%04d,name-%04d,\"1,000.%02d\"\n", i, i, i%100)
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		hasHeader  bool
+		sample     int
+		wantRows   int64
+		wantDelim  rune
+		wantHeader bool
+		wantBOM    bool
+		wantCRLF   bool
+	}{
+		{
+			name:      "estimated from sample",
+			content:   rows.String(),
+			hasHeader: true,
+			sample:    100,
+			wantRows:  1000,
+			wantDelim: ',',
+		},
+		{
+			name:      "complete sample",
+			content:   "id,name\n1,a\n2,b\n",
+			hasHeader: true,
+			sample:    100,
+			wantRows:  2,
+			wantDelim: ',',
+		},
+		{
+			name:      "no header",
+			content:   "1,a\n2,b\n3,c\n",
+			hasHeader: false,
+			sample:    100,
+			wantRows:  3,
+			wantDelim: ',',
+		},
+		{
+			name:       "semicolon with BOM and CRLF",
+			content:    "\xef\xbb\xbfid;name\r\n1;a\r\n2;c\r\n",
+			hasHeader:  true,
+			sample:     100,
+			wantRows:   2,
+			wantDelim:  ';',
+			wantHeader: true,
+			wantBOM:    true,
+			wantCRLF:   true,
+		},
+		{
+			name:       "tab",
+			content:    "id\tname\tcity\n1\ta\tx\n2\tb\ty\n",
+			hasHeader:  true,
+			sample:     100,
+			wantRows:   2,
+			wantDelim:  '\t',
+			wantHeader: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, fmt.Sprintf("file%d.csv", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			info, err := Inspect(path, tt.hasHeader, 0, tt.sample)
+			if err != nil {
+				t.Fatalf("Inspect() error: %v", err)
+			}
+
+			// Estimates may be off by a little since record sizes vary
+			if got := info.EstimatedRows(); got < tt.wantRows*95/100 || got > tt.wantRows*105/100 {
+				t.Errorf("expected about %d rows, got %d", tt.wantRows, got)
+			}
+			if info.Delimiter != tt.wantDelim {
+				t.Errorf("expected delimiter %q, got %q", tt.wantDelim, info.Delimiter)
+			}
+			if (info.HeaderErr != nil) != tt.wantHeader {
+				t.Errorf("expected header error %v, got %v", tt.wantHeader, info.HeaderErr)
+			}
+			if info.BOM != tt.wantBOM {
+				t.Errorf("expected BOM %v, got %v", tt.wantBOM, info.BOM)
+			}
+			if info.CRLF != tt.wantCRLF {
+				t.Errorf("expected CRLF %v, got %v", tt.wantCRLF, info.CRLF)
+			}
+		})
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	empty := filepath.Join(tmpDir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := Inspect(empty, true, 0, 10); err != errors.ErrEmptyFile {
+		t.Errorf("expected ErrEmptyFile, got %v", err)
+	}
+
+	if _, err := Inspect(filepath.Join(tmpDir, "missing.csv"), true, 0, 10); err != errors.ErrFileNotFound {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+
+	ragged := filepath.Join(tmpDir, "ragged.csv")
+	if err := os.WriteFile(ragged, []byte("id,name\n1,a\n2\n3,c\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	info, err := Inspect(ragged, true, 0, 10)
+	if err != nil {
+		t.Fatalf("Inspect() error: %v", err)
+	}
+	if info.SampleErr == nil {
+		t.Error("expected sample error for ragged record")
+	}
+	if len(info.Sample) != 1 {
+		t.Errorf("expected 1 sampled record, got %d", len(info.Sample))
+	}
+}
@@ -0,0 +1,147 @@
+package sorter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyType determines how a sort column is compared
+type KeyType string
+
+const (
+	// KeyString compares values lexically
+	KeyString KeyType = "string"
+
+	// KeyNumber compares values as floating point numbers
+	KeyNumber KeyType = "number"
+
+	// KeyTime compares values as timestamps (RFC3339, "2006-01-02 15:04:05" or "2006-01-02")
+	KeyTime KeyType = "time"
+)
+
+// timeLayouts are tried in order when parsing KeyTime values
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// SortKey describes one sort column
+type SortKey struct {
+	// Column is the header name, or a 1-based column number for files without a header
+	Column string
+
+	// Type selects typed comparison (default: string)
+	Type KeyType
+
+	// Descending reverses the order for this key
+	Descending bool
+}
+
+// String returns the key in the same form accepted by ParseKeys
+func (k SortKey) String() string {
+	s := k.Column
+	if k.Type != "" && k.Type != KeyString {
+		s += ":" + string(k.Type)
+	}
+	if k.Descending {
+		s += ":desc"
+	}
+	return s
+}
+
+// ParseKeys parses a key list such as "amount:number:desc,merchant".
+// Each key is a column followed by optional ":asc", ":desc", ":string",
+// ":number" (or ":num") and ":time" modifiers in any order.
+func ParseKeys(spec string) ([]SortKey, error) {
+	var keys []SortKey
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ":")
+		key := SortKey{Column: fields[0], Type: KeyString}
+		if key.Column == "" {
+			return nil, fmt.Errorf("empty column name in sort key %q", part)
+		}
+
+		for _, modifier := range fields[1:] {
+			switch strings.ToLower(modifier) {
+			case "asc":
+				key.Descending = false
+			case "desc":
+				key.Descending = true
+			case "string", "str":
+				key.Type = KeyString
+			case "number", "num":
+				key.Type = KeyNumber
+			case "time", "date":
+				key.Type = KeyTime
+			default:
+				return nil, fmt.Errorf("unknown sort key modifier %q in %q", modifier, part)
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort keys specified")
+	}
+
+	return keys, nil
+}
+
+// keyValue is a parsed sort value
+type keyValue struct {
+	str   string
+	num   float64
+	valid bool // false when a typed value failed to parse
+}
+
+// parseValue converts a raw field into a comparable keyValue
+func parseValue(raw string, keyType KeyType) keyValue {
+	switch keyType {
+	case KeyNumber:
+		n, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		return keyValue{str: raw, num: n, valid: err == nil}
+	case KeyTime:
+		raw = strings.TrimSpace(raw)
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return keyValue{str: raw, num: float64(t.UnixNano()), valid: true}
+			}
+		}
+		return keyValue{str: raw}
+	default:
+		return keyValue{str: raw, valid: true}
+	}
+}
+
+// compareValues compares two parsed values of the same key.
+// Values that fail to parse sort after valid ones, then lexically.
+func compareValues(a, b keyValue, keyType KeyType) int {
+	if keyType != KeyString {
+		switch {
+		case a.valid && !b.valid:
+			return -1
+		case !a.valid && b.valid:
+			return 1
+		case a.valid && b.valid:
+			switch {
+			case a.num < b.num:
+				return -1
+			case a.num > b.num:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	return strings.Compare(a.str, b.str)
+}
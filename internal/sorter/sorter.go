@@ -0,0 +1,664 @@
+package sorter
+
+import (
+	"container/heap"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/internal/reader"
+	"github.com/zuhrulumam/csv_processor/internal/worker"
+)
+
+const (
+	// DefaultMemoryBudget is used when Config.MemoryBudget is zero
+	DefaultMemoryBudget = 64 << 20
+
+	// maxFanIn bounds the number of run files merged at once
+	maxFanIn = 128
+
+	// rowOverhead approximates per-row memory beyond the field bytes
+	rowOverhead = 96
+)
+
+// Config holds configuration for Sorter
+type Config struct {
+	// Keys are the sort columns, most significant first
+	Keys []SortKey
+
+	// HasHeader indicates the input files have a header row
+	HasHeader bool
+
+	// Delimiter separates fields of the input and output (default: ',')
+	Delimiter rune
+
+	// MemoryBudget is the approximate number of bytes of rows held in memory
+	MemoryBudget int64
+
+	// Workers is the number of pool workers building and sorting runs in
+	// parallel, each with an equal share of MemoryBudget (0 = NumCPU)
+	Workers int
+
+	// TempDir is where run files are written (default: os.TempDir())
+	TempDir string
+
+	// BufferSize is the reader channel buffer size
+	BufferSize int
+}
+
+// Stats describes a completed sort
+type Stats struct {
+	// Records is the number of data rows written
+	Records int
+
+	// Runs is the number of sorted run files generated
+	Runs int
+}
+
+// Sorter sorts CSV files larger than memory using an external merge sort
+type Sorter struct {
+	config Config
+
+	// keyIndexes are the resolved column positions of config.Keys
+	keyIndexes []int
+}
+
+// NewSorter creates a new Sorter
+func NewSorter(config Config) (*Sorter, error) {
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("no sort keys specified")
+	}
+
+	if config.MemoryBudget <= 0 {
+		config.MemoryBudget = DefaultMemoryBudget
+	}
+
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	if config.TempDir == "" {
+		config.TempDir = os.TempDir()
+	}
+
+	if config.Delimiter == 0 {
+		config.Delimiter = ','
+	}
+
+	return &Sorter{config: config}, nil
+}
+
+// row is a record plus its parsed sort keys and original position
+type row struct {
+	data []string
+	keys []keyValue
+	file int
+	line int
+}
+
+// Sort reads all files, sorts their records and writes CSV to out
+func (s *Sorter) Sort(ctx context.Context, files []string, out io.Writer) (Stats, error) {
+	var stats Stats
+
+	if len(files) == 0 {
+		return stats, fmt.Errorf("no input files specified")
+	}
+
+	s.keyIndexes = nil
+
+	tmpDir, err := os.MkdirTemp(s.config.TempDir, "csvsort-")
+	if err != nil {
+		return stats, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	header, runs, records, err := s.generateRuns(ctx, files, tmpDir)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Runs = len(runs)
+	stats.Records = records
+
+	if header == nil && s.config.HasHeader {
+		header, err = readHeader(files[0], s.config.Delimiter)
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Comma = s.config.Delimiter
+
+	if header != nil {
+		if err := writer.Write(header); err != nil {
+			return stats, fmt.Errorf("write header: %w", err)
+		}
+	}
+
+	if err := s.mergeAll(ctx, runs, tmpDir, writer); err != nil {
+		return stats, err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return stats, fmt.Errorf("write output: %w", err)
+	}
+
+	return stats, nil
+}
+
+// generateRuns reads the input and writes it as sorted run files. Records
+// are spread over a worker pool; each worker fills its own chunk and sorts
+// and writes it once it reaches its share of the memory budget.
+func (s *Sorter) generateRuns(ctx context.Context, files []string, tmpDir string) ([]string, []string, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	builder := &runBuilder{
+		sorter:    s,
+		tmpDir:    tmpDir,
+		fileOrder: make(map[string]int, len(files)),
+		budget:    s.config.MemoryBudget / int64(s.config.Workers),
+		chunks:    make([]chunk, s.config.Workers),
+	}
+	for i, file := range files {
+		builder.fileOrder[file] = i
+	}
+
+	csvReader := reader.NewCSVReader(reader.Config{
+		Files:          files,
+		HasHeader:      s.config.HasHeader,
+		ValidateHeader: s.config.HasHeader,
+		BufferSize:     s.config.BufferSize,
+		Delimiter:      s.config.Delimiter,
+	})
+
+	recordCh, errCh := csvReader.Read(ctx)
+
+	input := make(chan *models.Record, s.config.BufferSize)
+	pool := worker.NewPool(worker.Config{
+		Workers:      s.config.Workers,
+		Processor:    builder,
+		InputChannel: input,
+	})
+	if err := pool.Start(); err != nil {
+		return nil, nil, 0, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		pool.Stop()
+	}()
+
+	// The first failed run cancels the rest
+	var runErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range pool.Results() {
+			if result.IsFailed() && runErr == nil {
+				runErr = result.Error
+				cancel()
+			}
+		}
+	}()
+
+	var (
+		header  []string
+		keyErr  error
+		records int
+	)
+
+	for record := range recordCh {
+		if ctx.Err() != nil {
+			continue // drain so the reader can exit
+		}
+
+		if header == nil && record.Headers != nil {
+			header = record.Headers
+		}
+
+		if s.keyIndexes == nil {
+			if keyErr = s.resolveKeys(record); keyErr != nil {
+				cancel()
+				continue
+			}
+		}
+
+		select {
+		case input <- record:
+			records++
+		case <-ctx.Done():
+		}
+	}
+
+	close(input)
+	<-done
+
+	if keyErr != nil {
+		return nil, nil, 0, keyErr
+	}
+	if runErr != nil {
+		return nil, nil, 0, runErr
+	}
+
+	for err := range errCh {
+		return nil, nil, 0, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// The workers have stopped, so their last chunks are written here
+	if err := builder.flushAll(); err != nil {
+		return nil, nil, 0, err
+	}
+
+	return header, builder.runs, records, nil
+}
+
+// chunk is the rows a worker has collected for its next run
+type chunk struct {
+	rows  []*row
+	bytes int64
+}
+
+// runBuilder is the processor of the run generation pool. Chunks are
+// indexed by worker ID, so workers fill them without locking.
+type runBuilder struct {
+	sorter    *Sorter
+	tmpDir    string
+	fileOrder map[string]int // by path, so files of the same name stay apart
+	budget    int64
+	chunks    []chunk
+
+	// mu protects runs
+	mu   sync.Mutex
+	runs []string
+}
+
+// Name implements the processor.Named interface
+func (b *runBuilder) Name() string {
+	return "sort"
+}
+
+// Process implements the processor.Processor interface
+func (b *runBuilder) Process(ctx context.Context, record *models.Record) (*models.Result, error) {
+	c := &b.chunks[processor.WorkerID(ctx)]
+
+	c.rows = append(c.rows, b.sorter.newRow(record.Data, b.fileOrder[record.Path], record.LineNumber))
+	c.bytes += rowSize(record.Data)
+
+	if c.bytes >= b.budget {
+		if err := b.flush(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return models.NewSuccessResult(record, nil, 0), nil
+}
+
+// flush sorts a chunk into a new run file and empties it
+func (b *runBuilder) flush(c *chunk) error {
+	if len(c.rows) == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	path := filepath.Join(b.tmpDir, fmt.Sprintf("run-%06d.csv", len(b.runs)))
+	b.runs = append(b.runs, path)
+	b.mu.Unlock()
+
+	err := b.sorter.writeRun(path, c.rows)
+	c.rows, c.bytes = nil, 0
+	return err
+}
+
+// flushAll writes the remaining chunks in parallel
+func (b *runBuilder) flushAll() error {
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		first error
+	)
+
+	for i := range b.chunks {
+		wg.Add(1)
+		go func(c *chunk) {
+			defer wg.Done()
+			if err := b.flush(c); err != nil {
+				errMu.Lock()
+				if first == nil {
+					first = err
+				}
+				errMu.Unlock()
+			}
+		}(&b.chunks[i])
+	}
+
+	wg.Wait()
+	return first
+}
+
+// resolveKeys maps sort columns to field positions using the first record
+func (s *Sorter) resolveKeys(record *models.Record) error {
+	indexes := make([]int, len(s.config.Keys))
+
+	for i, key := range s.config.Keys {
+		index := -1
+		for j, header := range record.Headers {
+			if header == key.Column {
+				index = j
+				break
+			}
+		}
+
+		if index < 0 {
+			n, err := strconv.Atoi(key.Column)
+			if err != nil || n < 1 || n > len(record.Data) {
+				return fmt.Errorf("sort column %q not found", key.Column)
+			}
+			index = n - 1
+		}
+
+		indexes[i] = index
+	}
+
+	s.keyIndexes = indexes
+	return nil
+}
+
+// newRow parses the sort keys of a record
+func (s *Sorter) newRow(data []string, file, line int) *row {
+	r := &row{
+		data: data,
+		keys: make([]keyValue, len(s.config.Keys)),
+		file: file,
+		line: line,
+	}
+
+	for i, key := range s.config.Keys {
+		var raw string
+		if index := s.keyIndexes[i]; index < len(data) {
+			raw = data[index]
+		}
+		r.keys[i] = parseValue(raw, key.Type)
+	}
+
+	return r
+}
+
+// compare orders rows by key, falling back to original file and line order
+func (s *Sorter) compare(a, b *row) int {
+	for i, key := range s.config.Keys {
+		c := compareValues(a.keys[i], b.keys[i], key.Type)
+		if key.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case a.file != b.file:
+		return a.file - b.file
+	default:
+		return a.line - b.line
+	}
+}
+
+// writeRun sorts a chunk and writes it to a run file.
+// Run rows are prefixed with their original file index and line number.
+func (s *Sorter) writeRun(path string, rows []*row) error {
+	sort.Slice(rows, func(i, j int) bool {
+		return s.compare(rows[i], rows[j]) < 0
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create run: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	for _, r := range rows {
+		if err := writer.Write(runRecord(r)); err != nil {
+			return fmt.Errorf("write run: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write run: %w", err)
+	}
+
+	return file.Close()
+}
+
+// runRecord encodes a row for a run file
+func runRecord(r *row) []string {
+	fields := make([]string, 0, len(r.data)+2)
+	fields = append(fields, strconv.Itoa(r.file), strconv.Itoa(r.line))
+	return append(fields, r.data...)
+}
+
+// mergeAll merges runs into writer, reducing them in passes of at most maxFanIn
+func (s *Sorter) mergeAll(ctx context.Context, runs []string, tmpDir string, writer *csv.Writer) error {
+	pass := 0
+
+	for len(runs) > maxFanIn {
+		var next []string
+
+		for start := 0; start < len(runs); start += maxFanIn {
+			end := start + maxFanIn
+			if end > len(runs) {
+				end = len(runs)
+			}
+
+			path := filepath.Join(tmpDir, fmt.Sprintf("merge-%d-%06d.csv", pass, len(next)))
+			if err := s.mergeToRun(ctx, runs[start:end], path); err != nil {
+				return err
+			}
+
+			for _, run := range runs[start:end] {
+				os.Remove(run)
+			}
+			next = append(next, path)
+		}
+
+		runs = next
+		pass++
+	}
+
+	return s.merge(ctx, runs, func(r *row) error {
+		return writer.Write(r.data)
+	})
+}
+
+// mergeToRun merges runs into a new run file
+func (s *Sorter) mergeToRun(ctx context.Context, runs []string, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create run: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	if err := s.merge(ctx, runs, func(r *row) error {
+		return writer.Write(runRecord(r))
+	}); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write run: %w", err)
+	}
+
+	return file.Close()
+}
+
+// merge performs a k-way merge of run files, calling emit for each row in order
+func (s *Sorter) merge(ctx context.Context, runs []string, emit func(*row) error) error {
+	h := &mergeHeap{sorter: s}
+
+	defer func() {
+		for _, c := range h.cursors {
+			c.file.Close()
+		}
+	}()
+
+	for _, path := range runs {
+		c, err := s.openCursor(path)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			continue
+		}
+		h.cursors = append(h.cursors, c)
+	}
+
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		c := h.cursors[0]
+		if err := emit(c.current); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+
+		ok, err := c.advance(s)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			c.file.Close()
+			heap.Pop(h)
+		}
+	}
+
+	return nil
+}
+
+// runCursor reads rows from a run file in order
+type runCursor struct {
+	file    *os.File
+	reader  *csv.Reader
+	current *row
+}
+
+// openCursor opens a run file positioned at its first row; nil for empty runs
+func (s *Sorter) openCursor(path string) (*runCursor, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open run: %w", err)
+	}
+
+	csvReader := csv.NewReader(file)
+	csvReader.FieldsPerRecord = -1
+
+	c := &runCursor{file: file, reader: csvReader}
+
+	ok, err := c.advance(s)
+	if err != nil || !ok {
+		file.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// advance loads the next row, returning false at end of run
+func (c *runCursor) advance(s *Sorter) (bool, error) {
+	fields, err := c.reader.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read run: %w", err)
+	}
+	if len(fields) < 2 {
+		return false, fmt.Errorf("read run: malformed row")
+	}
+
+	file, _ := strconv.Atoi(fields[0])
+	line, _ := strconv.Atoi(fields[1])
+	c.current = s.newRow(fields[2:], file, line)
+
+	return true, nil
+}
+
+// mergeHeap is a min-heap of run cursors ordered by their current row
+type mergeHeap struct {
+	sorter  *Sorter
+	cursors []*runCursor
+}
+
+func (h *mergeHeap) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	return h.sorter.compare(h.cursors[i].current, h.cursors[j].current) < 0
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap) Push(x interface{}) { h.cursors = append(h.cursors, x.(*runCursor)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	last := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return last
+}
+
+// rowSize estimates the in-memory size of a row
+func rowSize(data []string) int64 {
+	size := int64(rowOverhead)
+	for _, field := range data {
+		size += int64(len(field)) + 16
+	}
+	return size
+}
+
+// readHeader returns the first row of a CSV file
+func readHeader(filename string, delimiter rune) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.Comma = delimiter
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	return header, nil
+}
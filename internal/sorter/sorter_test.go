@@ -0,0 +1,234 @@
+package sorter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("amount:num:desc, merchant,ts:time")
+	if err != nil {
+		t.Fatalf("ParseKeys() error: %v", err)
+	}
+
+	want := []SortKey{
+		{Column: "amount", Type: KeyNumber, Descending: true},
+		{Column: "merchant", Type: KeyString},
+		{Column: "ts", Type: KeyTime},
+	}
+
+	if len(keys) != len(want) {
+		t.Fatalf("expected %d keys, got %d", len(want), len(keys))
+	}
+
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d = %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+
+	if _, err := ParseKeys("amount:sideways"); err == nil {
+		t.Error("expected error for unknown modifier")
+	}
+}
+
+func TestSorter_Sort(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	content := "id,merchant,amount\n1,b,10\n2,a,9.5\n3,b,100\n4,a,x\n5,a,9.5\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	keys, _ := ParseKeys("merchant,amount:num:desc")
+	sorter, err := NewSorter(Config{Keys: keys, HasHeader: true, TempDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewSorter() error: %v", err)
+	}
+
+	var out bytes.Buffer
+	stats, err := sorter.Sort(context.Background(), []string{file}, &out)
+	if err != nil {
+		t.Fatalf("Sort() error: %v", err)
+	}
+
+	// Unparseable numbers sort last ascending, so first when descending;
+	// ties keep their original line order
+	want := "id,merchant,amount\n4,a,x\n2,a,9.5\n5,a,9.5\n3,b,100\n1,b,10\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if stats.Records != 5 {
+		t.Errorf("expected 5 records, got %d", stats.Records)
+	}
+}
+
+func TestSorter_Delimiter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte("id;amount\n1;10,5\n2;9,5\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	keys, _ := ParseKeys("amount")
+	sorter, err := NewSorter(Config{Keys: keys, HasHeader: true, Delimiter: ';', TempDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewSorter() error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := sorter.Sort(context.Background(), []string{file}, &out); err != nil {
+		t.Fatalf("Sort() error: %v", err)
+	}
+
+	// Output keeps the input dialect
+	want := "id;amount\n1;10,5\n2;9,5\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestSorter_SameFileName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Ties keep input order, which must tell apart files of the same name
+	const rows = 100
+	var files []string
+	var want strings.Builder
+	want.WriteString("id,key\n")
+	for _, dir := range []string{"b", "a"} {
+		var content strings.Builder
+		content.WriteString("id,key\n")
+		for i := 0; i < rows; i++ {
+			fmt.Fprintf(&content, "%s%d,1\n", dir, i)
+			fmt.Fprintf(&want, "%s%d,1\n", dir, i)
+		}
+
+		file := filepath.Join(tmpDir, dir, "data.csv")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("failed to create test dir: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content.String()), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		files = append(files, file)
+	}
+
+	keys, _ := ParseKeys("key")
+	sorter, err := NewSorter(Config{Keys: keys, HasHeader: true, TempDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewSorter() error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := sorter.Sort(context.Background(), files, &out); err != nil {
+		t.Fatalf("Sort() error: %v", err)
+	}
+
+	if out.String() != want.String() {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want.String())
+	}
+}
+
+func TestSorter_MultiPassMerge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	const rows = 3 * maxFanIn
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&content, "%d,%d\n", i, (i*7919)%rows)
+	}
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// A one-byte budget forces one run per row and several merge passes
+	sorter, _ := NewSorter(Config{
+		Keys:         []SortKey{{Column: "value", Type: KeyNumber}},
+		HasHeader:    true,
+		MemoryBudget: 1,
+		Workers:      4,
+		TempDir:      tmpDir,
+	})
+
+	var out bytes.Buffer
+	stats, err := sorter.Sort(context.Background(), []string{file}, &out)
+	if err != nil {
+		t.Fatalf("Sort() error: %v", err)
+	}
+
+	if stats.Runs != rows {
+		t.Errorf("expected %d runs, got %d", rows, stats.Runs)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != rows+1 {
+		t.Fatalf("expected %d lines, got %d", rows+1, len(lines))
+	}
+
+	for i, line := range lines[1:] {
+		if !strings.HasSuffix(line, fmt.Sprintf(",%d", i)) {
+			t.Fatalf("line %d out of order: %s", i+1, line)
+		}
+	}
+}
+
+func TestSorter_ParallelRuns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Rows go to whichever worker is free, so ties must still come out in
+	// input order when they end up in different runs
+	const rows = 2000
+	var content strings.Builder
+	content.WriteString("id,key\n")
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&content, "%d,%d\n", i, i%3)
+	}
+
+	file := filepath.Join(tmpDir, "data.csv")
+	if err := os.WriteFile(file, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	keys, _ := ParseKeys("key")
+	sorter, _ := NewSorter(Config{
+		Keys:         keys,
+		HasHeader:    true,
+		MemoryBudget: 16 << 10,
+		Workers:      4,
+		TempDir:      tmpDir,
+	})
+
+	var out bytes.Buffer
+	stats, err := sorter.Sort(context.Background(), []string{file}, &out)
+	if err != nil {
+		t.Fatalf("Sort() error: %v", err)
+	}
+
+	if stats.Runs < 4 || stats.Records != rows {
+		t.Errorf("expected %d records in at least 4 runs, got %+v", rows, stats)
+	}
+
+	var want strings.Builder
+	want.WriteString("id,key\n")
+	for key := 0; key < 3; key++ {
+		for i := key; i < rows; i += 3 {
+			fmt.Fprintf(&want, "%d,%d\n", i, key)
+		}
+	}
+
+	if out.String() != want.String() {
+		t.Error("expected rows with equal keys in input order")
+	}
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is how many records a batch span covers
+	DefaultBatchSize = 1000
+
+	// SampleEvery is how often a record's phases are timed; reading the
+	// clock for every phase of every record would slow a run noticeably
+	SampleEvery = 8
+
+	// LongWait is how long a wait must take to get a span of its own
+	LongWait = time.Millisecond
+)
+
+// Batch groups the records a lane handles into spans of up to a batch size,
+// so a trace stays small however many records a run has.
+//
+// A lane's time is split into phases, such as parsing, processing or waiting
+// on a channel, by marking the end of each one: the time since the previous
+// mark goes to the phase named. Phases are timed for every SampleEvery-th
+// record, and each batch span shows the mean time per record of each phase
+// as "<phase>_us" args ("queue wait" becomes queue_wait_us). Waits of
+// LongWait or more in a timed record also get a span of their own, inside
+// the batch span.
+type Batch struct {
+	tracer   *Tracer
+	lane     int
+	name     string
+	category string
+	size     int
+	sample   int
+
+	// timed is set while the phases of the current record are timed; last
+	// is the time of its previous mark
+	timed bool
+	last  time.Time
+
+	start        time.Time
+	records      int
+	timedRecords int
+	phases       []phase
+}
+
+// phase is the time spent in one phase over the timed records of a batch
+type phase struct {
+	name string
+	d    time.Duration
+}
+
+// Batch returns a batch recorder for one goroutine's lane. Time is counted
+// from now.
+func (t *Tracer) Batch(lane int, name, category string, size int) *Batch {
+	if t == nil {
+		return nil
+	}
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	now := time.Now()
+	return &Batch{
+		tracer:   t,
+		lane:     lane,
+		name:     name,
+		category: category,
+		size:     size,
+		sample:   SampleEvery,
+		timed:    true,
+		last:     now,
+		start:    now,
+	}
+}
+
+// Work ends a phase of work, adding the time since the previous mark to it
+func (b *Batch) Work(name string) {
+	if b == nil || !b.timed {
+		return
+	}
+	b.mark(name)
+}
+
+// Wait ends a wait, adding the time since the previous mark to it, and
+// records a span for it when it took LongWait or more
+func (b *Batch) Wait(name string) {
+	if b == nil || !b.timed {
+		return
+	}
+
+	start := b.last
+	if d := b.mark(name); d >= LongWait {
+		b.tracer.record(b.lane, name, b.category, start, b.last, nil)
+	}
+}
+
+// mark adds the time since the previous mark to the named phase
+func (b *Batch) mark(name string) time.Duration {
+	now := time.Now()
+	d := now.Sub(b.last)
+	b.last = now
+
+	for i := range b.phases {
+		if b.phases[i].name == name {
+			b.phases[i].d += d
+			return d
+		}
+	}
+	b.phases = append(b.phases, phase{name: name, d: d})
+
+	return d
+}
+
+// Done counts a record, recording the batch span once it is full
+func (b *Batch) Done() {
+	if b == nil {
+		return
+	}
+
+	if b.timed {
+		b.timedRecords++
+	}
+	b.records++
+
+	var now time.Time
+	if b.records >= b.size {
+		now = time.Now()
+		b.flush(now)
+	}
+
+	// The next record is timed from here
+	b.timed = b.records%b.sample == 0
+	if b.timed {
+		if now.IsZero() {
+			now = time.Now()
+		}
+		b.last = now
+	}
+}
+
+// Flush records the current batch, if it has any records
+func (b *Batch) Flush() {
+	if b == nil || b.records == 0 {
+		return
+	}
+	b.flush(time.Now())
+}
+
+// flush records the batch span ending at end and starts the next batch there
+func (b *Batch) flush(end time.Time) {
+	args := map[string]any{"records": b.records}
+	if b.timedRecords > 0 {
+		for _, p := range b.phases {
+			mean := p.d / time.Duration(b.timedRecords)
+			args[strings.ReplaceAll(p.name, " ", "_")+"_us"] = micros(mean)
+		}
+	}
+
+	b.tracer.record(b.lane, b.name, b.category, b.start, end, args)
+
+	b.start = end
+	b.records = 0
+	b.timedRecords = 0
+	b.phases = b.phases[:0]
+}
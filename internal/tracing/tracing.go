@@ -0,0 +1,187 @@
+// Package tracing records spans of a run and writes them in the Chrome trace
+// event format, for viewing in chrome://tracing or Perfetto without a tracing
+// backend. A nil *Tracer, and the spans and batches it returns, do nothing,
+// so instrumented code needs no checks when tracing is off.
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEvents bounds the events a Tracer keeps; later ones are counted
+// as dropped
+const DefaultMaxEvents = 1_000_000
+
+// Event is one entry of a Chrome trace. Times are in microseconds since the
+// tracer was created.
+type Event struct {
+	Name     string         `json:"name"`
+	Category string         `json:"cat,omitempty"`
+	Phase    string         `json:"ph"`
+	Time     float64        `json:"ts"`
+	Duration float64        `json:"dur,omitempty"`
+	PID      int            `json:"pid"`
+	TID      int            `json:"tid"`
+	Args     map[string]any `json:"args,omitempty"`
+}
+
+// Tracer collects spans from any goroutine. Each goroutine that records
+// spans uses its own lane, shown as a thread in the viewer.
+type Tracer struct {
+	mu        sync.Mutex
+	start     time.Time
+	events    []Event
+	lanes     int
+	maxEvents int
+	dropped   int
+}
+
+// New creates a tracer that keeps up to DefaultMaxEvents events
+func New() *Tracer {
+	return &Tracer{
+		start:     time.Now(),
+		maxEvents: DefaultMaxEvents,
+	}
+}
+
+// Lane allocates a lane with the given name, for one goroutine's spans
+func (t *Tracer) Lane(name string) int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lanes++
+	t.events = append(t.events, Event{
+		Name:  "thread_name",
+		Phase: "M",
+		PID:   1,
+		TID:   t.lanes,
+		Args:  map[string]any{"name": name},
+	})
+
+	return t.lanes
+}
+
+// Start begins a span on lane; End records it
+func (t *Tracer) Start(lane int, name, category string) *Span {
+	if t == nil {
+		return nil
+	}
+	return &Span{tracer: t, lane: lane, name: name, category: category, start: time.Now()}
+}
+
+// record adds a span that ran from start to end
+func (t *Tracer) record(lane int, name, category string, start, end time.Time, args map[string]any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.events) >= t.maxEvents {
+		t.dropped++
+		return
+	}
+
+	t.events = append(t.events, Event{
+		Name:     name,
+		Category: category,
+		Phase:    "X",
+		Time:     micros(start.Sub(t.start)),
+		Duration: micros(end.Sub(start)),
+		PID:      1,
+		TID:      lane,
+		Args:     args,
+	})
+}
+
+// Events returns the number of events recorded and dropped so far
+func (t *Tracer) Events() (recorded, dropped int) {
+	if t == nil {
+		return 0, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.events), t.dropped
+}
+
+// Write writes the trace as a Chrome trace JSON object
+func (t *Tracer) Write(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trace := struct {
+		TraceEvents     []Event        `json:"traceEvents"`
+		DisplayTimeUnit string         `json:"displayTimeUnit"`
+		OtherData       map[string]any `json:"otherData"`
+	}{
+		TraceEvents:     t.events,
+		DisplayTimeUnit: "ms",
+		OtherData: map[string]any{
+			"start":          t.start.UTC().Format(time.RFC3339Nano),
+			"dropped_events": t.dropped,
+		},
+	}
+
+	if trace.TraceEvents == nil {
+		trace.TraceEvents = []Event{}
+	}
+
+	return json.NewEncoder(w).Encode(trace)
+}
+
+// WriteFile writes the trace to path
+func (t *Tracer) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create trace: %w", err)
+	}
+
+	if err := t.Write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("write trace: %w", err)
+	}
+
+	return file.Close()
+}
+
+// micros converts d to microseconds, the unit of Chrome trace times
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// Span is an operation in progress on one lane
+type Span struct {
+	tracer   *Tracer
+	lane     int
+	name     string
+	category string
+	start    time.Time
+	args     map[string]any
+}
+
+// SetArg attaches a value shown with the span in the viewer
+func (s *Span) SetArg(key string, value any) {
+	if s == nil {
+		return
+	}
+	if s.args == nil {
+		s.args = make(map[string]any)
+	}
+	s.args[key] = value
+}
+
+// End records the span as ending now
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.tracer.record(s.lane, s.name, s.category, s.start, time.Now(), s.args)
+}
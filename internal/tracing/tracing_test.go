@@ -0,0 +1,210 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// trace is the decoded form of a written trace
+type trace struct {
+	TraceEvents []Event        `json:"traceEvents"`
+	OtherData   map[string]any `json:"otherData"`
+}
+
+func decode(t *testing.T, tracer *Tracer) trace {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := tracer.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var tr trace
+	if err := json.Unmarshal(buf.Bytes(), &tr); err != nil {
+		t.Fatalf("invalid trace JSON: %v", err)
+	}
+	return tr
+}
+
+func TestTracer_Spans(t *testing.T) {
+	tracer := New()
+
+	lane := tracer.Lane("reader")
+	span := tracer.Start(lane, "read file", "read")
+	span.SetArg("file", "a.csv")
+	time.Sleep(2 * time.Millisecond)
+	span.End()
+
+	tr := decode(t, tracer)
+	if len(tr.TraceEvents) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(tr.TraceEvents))
+	}
+
+	meta := tr.TraceEvents[0]
+	if meta.Phase != "M" || meta.Name != "thread_name" || meta.TID != lane || meta.Args["name"] != "reader" {
+		t.Errorf("unexpected lane metadata: %+v", meta)
+	}
+
+	ev := tr.TraceEvents[1]
+	if ev.Phase != "X" || ev.Name != "read file" || ev.Category != "read" || ev.TID != lane {
+		t.Errorf("unexpected span: %+v", ev)
+	}
+	if ev.Duration < 2000 {
+		t.Errorf("expected duration of at least 2000us, got %v", ev.Duration)
+	}
+	if ev.Args["file"] != "a.csv" {
+		t.Errorf("expected file arg a.csv, got %v", ev.Args["file"])
+	}
+}
+
+func TestTracer_Nil(t *testing.T) {
+	var tracer *Tracer
+
+	lane := tracer.Lane("reader")
+	span := tracer.Start(lane, "read file", "read")
+	span.SetArg("file", "a.csv")
+	span.End()
+
+	batch := tracer.Batch(lane, "parse batch", "read", 0)
+	batch.Work("parse")
+	batch.Wait("queue wait")
+	batch.Done()
+	batch.Flush()
+
+	if recorded, dropped := tracer.Events(); recorded != 0 || dropped != 0 {
+		t.Errorf("expected no events, got %d recorded, %d dropped", recorded, dropped)
+	}
+}
+
+func TestTracer_MaxEvents(t *testing.T) {
+	tracer := New()
+	tracer.maxEvents = 3
+
+	lane := tracer.Lane("worker 0")
+	for i := 0; i < 5; i++ {
+		tracer.Start(lane, "process", "process").End()
+	}
+
+	recorded, dropped := tracer.Events()
+	if recorded != 3 || dropped != 3 {
+		t.Errorf("expected 3 recorded and 3 dropped, got %d and %d", recorded, dropped)
+	}
+
+	tr := decode(t, tracer)
+	if tr.OtherData["dropped_events"] != float64(3) {
+		t.Errorf("expected dropped_events 3, got %v", tr.OtherData["dropped_events"])
+	}
+}
+
+func TestBatch(t *testing.T) {
+	tracer := New()
+	lane := tracer.Lane("worker 0")
+	batch := tracer.Batch(lane, "process batch", "process", 4)
+	batch.sample = 2
+
+	for i := 0; i < 10; i++ {
+		// One wait long enough for a span of its own, in a timed record
+		if i == 2 {
+			time.Sleep(2 * LongWait)
+		}
+		batch.Wait("input wait")
+		batch.Work("process")
+		batch.Done()
+	}
+	batch.Flush()
+
+	// Nothing is left to record
+	batch.Flush()
+
+	var batches []Event
+	var waits int
+	for _, ev := range decode(t, tracer).TraceEvents {
+		switch ev.Name {
+		case "process batch":
+			batches = append(batches, ev)
+		case "input wait":
+			waits++
+		}
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("expected 3 batch spans, got %d", len(batches))
+	}
+	for i, want := range []float64{4, 4, 2} {
+		if batches[i].Args["records"] != want {
+			t.Errorf("batch %d: expected %v records, got %v", i, want, batches[i].Args["records"])
+		}
+	}
+
+	// Two timed records, one of which waited 2ms
+	if wait, _ := batches[0].Args["input_wait_us"].(float64); wait < 1000 {
+		t.Errorf("expected mean input_wait_us of at least 1000, got %v", batches[0].Args["input_wait_us"])
+	}
+	if _, ok := batches[0].Args["process_us"]; !ok {
+		t.Error("expected process_us arg on batch span")
+	}
+
+	// Batches follow one another
+	for i := 1; i < len(batches); i++ {
+		if end := batches[i-1].Time + batches[i-1].Duration; batches[i].Time < end-0.001 {
+			t.Errorf("batch %d starts at %v, before the previous one ends at %v", i, batches[i].Time, end)
+		}
+	}
+
+	if waits != 1 {
+		t.Errorf("expected 1 long wait span, got %d", waits)
+	}
+}
+
+func TestBatch_Sampling(t *testing.T) {
+	tracer := New()
+	batch := tracer.Batch(tracer.Lane("reader"), "parse batch", "read", 100)
+
+	// Only every SampleEvery-th record is timed, starting with the first
+	var timed int
+	for i := 0; i < 3*SampleEvery; i++ {
+		if batch.timed {
+			timed++
+		}
+		batch.Work("parse")
+		batch.Done()
+	}
+
+	if timed != 3 {
+		t.Errorf("expected 3 timed records, got %d", timed)
+	}
+	if batch.timedRecords != 3 {
+		t.Errorf("expected 3 timed records in the batch, got %d", batch.timedRecords)
+	}
+}
+
+func TestTracer_WriteFile(t *testing.T) {
+	tracer := New()
+	tracer.Start(tracer.Lane("pipeline"), "run", "pipeline").End()
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	if err := tracer.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace: %v", err)
+	}
+
+	var tr trace
+	if err := json.Unmarshal(data, &tr); err != nil {
+		t.Fatalf("invalid trace JSON: %v", err)
+	}
+	if len(tr.TraceEvents) != 2 {
+		t.Errorf("expected 2 events, got %d", len(tr.TraceEvents))
+	}
+
+	if err := tracer.WriteFile(filepath.Join(t.TempDir(), "missing", "trace.json")); err == nil {
+		t.Error("expected error writing to a missing directory")
+	}
+}
@@ -0,0 +1,249 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxDashboardFiles is how many active files get their own bar
+	maxDashboardFiles = 8
+
+	// maxRecentErrors is how many recent error messages are shown
+	maxRecentErrors = 3
+
+	// maxLabelWidth caps the label column, so long file names do not crowd
+	// out the bars
+	maxLabelWidth = 24
+
+	// maxBarWidth is the widest a bar or the sparkline is drawn
+	maxBarWidth = 40
+)
+
+// sparkTicks are the levels of the throughput sparkline, lowest first
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// ErrorStats is the error breakdown shown by the dashboard
+type ErrorStats struct {
+	// ByCategory counts errors per category
+	ByCategory map[string]int
+
+	// Recent holds the latest error messages, oldest first
+	Recent []string
+}
+
+// dashboard is the state the terminal dashboard keeps between frames
+type dashboard struct {
+	// lines is the height of the previous frame, which the next one
+	// is drawn over
+	lines int
+
+	// rates is the throughput at each update, newest last
+	rates         []float64
+	lastProcessed uint64
+	lastAt        time.Time
+}
+
+// SetErrorStats sets where the dashboard gets error counts and recent
+// messages from. It must be called before Start.
+func (pt *ProgressTracker) SetErrorStats(fn func() ErrorStats) {
+	pt.errorStats = fn
+}
+
+// printDashboard redraws the dashboard over the previous frame
+func (pt *ProgressTracker) printDashboard() {
+	d := &pt.dash
+	now := time.Now()
+	processed := pt.Processed()
+
+	// Throughput since the previous frame, for the sparkline
+	if !d.lastAt.IsZero() {
+		if seconds := now.Sub(d.lastAt).Seconds(); seconds > 0 {
+			d.rates = append(d.rates, float64(processed-d.lastProcessed)/seconds)
+		}
+		if len(d.rates) > maxBarWidth {
+			d.rates = d.rates[len(d.rates)-maxBarWidth:]
+		}
+	}
+	d.lastProcessed, d.lastAt = processed, now
+
+	width := terminalWidth(pt.writer)
+	lines := pt.dashboardLines(width)
+
+	var b strings.Builder
+	if d.lines > 0 {
+		// Move to the start of the previous frame and clear it
+		fmt.Fprintf(&b, "\x1b[%dF\x1b[J", d.lines)
+	}
+	for _, line := range lines {
+		b.WriteString(truncate(line, width-1))
+		b.WriteByte('\n')
+	}
+
+	pt.writer.Write([]byte(b.String()))
+	d.lines = len(lines)
+}
+
+// dashboardLines builds the lines of one frame for a terminal width columns wide
+func (pt *ProgressTracker) dashboardLines(width int) []string {
+	all := pt.Files()
+	files := activeFiles(all)
+
+	// Sized for every file, so the bars do not shift as files finish
+	labelWidth := len("Overall")
+	for _, file := range all {
+		labelWidth = max(labelWidth, min(utf8.RuneCountInString(file.File)+2, maxLabelWidth))
+	}
+
+	// The bar takes what is left after the label and the numbers after it
+	barWidth := min(max(width-labelWidth-45, 10), maxBarWidth)
+
+	label := func(s string) string {
+		s = truncate(s, labelWidth)
+		return s + strings.Repeat(" ", labelWidth-utf8.RuneCountInString(s))
+	}
+
+	stats := pt.Stats()
+	lines := []string{
+		fmt.Sprintf("%s  %s | %d success | %d failed | %d skipped",
+			label("Elapsed"), stats.Elapsed.Round(time.Second), stats.Success, stats.Failed, stats.Skipped),
+	}
+
+	if pt.HasProgress() {
+		lines = append(lines, fmt.Sprintf("%s  %s %5.1f%%  %d processed  ETA %s",
+			label("Overall"), bar(stats.PercentComplete, barWidth), stats.PercentComplete,
+			stats.Processed, stats.ETA.Round(time.Second)))
+	} else {
+		lines = append(lines, fmt.Sprintf("%s  %d processed", label("Overall"), stats.Processed))
+	}
+
+	for i, file := range files {
+		if i == maxDashboardFiles {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(files)-i))
+			break
+		}
+
+		name := label("  " + file.File)
+		if file.Percent > 0 {
+			lines = append(lines, fmt.Sprintf("%s  %s %5.1f%%  %d processed",
+				name, bar(file.Percent, barWidth), file.Percent, file.Processed))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s  %d processed", name, file.Processed))
+		}
+	}
+
+	rate := stats.Throughput
+	if n := len(pt.dash.rates); n > 0 {
+		rate = pt.dash.rates[n-1]
+	}
+	lines = append(lines, fmt.Sprintf("%s  %s %.0f rec/s (avg %.0f)",
+		label("Rate"), sparkline(pt.dash.rates, barWidth), rate, stats.Throughput))
+
+	return append(lines, pt.errorLines(label)...)
+}
+
+// activeFiles returns the files that are being read: started, and not yet
+// complete when their progress is known
+func activeFiles(files []FileStats) []FileStats {
+	var active []FileStats
+	for _, file := range files {
+		if file.Processed > 0 && file.Percent < 100 {
+			active = append(active, file)
+		}
+	}
+	return active
+}
+
+// errorLines returns the error counts by category and the most recent error
+// messages, or nothing when there are no errors
+func (pt *ProgressTracker) errorLines(label func(string) string) []string {
+	if pt.errorStats == nil {
+		return nil
+	}
+
+	errs := pt.errorStats()
+
+	total := 0
+	categories := make([]string, 0, len(errs.ByCategory))
+	for category, n := range errs.ByCategory {
+		total += n
+		categories = append(categories, category)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	// Most frequent first
+	sort.Slice(categories, func(i, j int) bool {
+		ci, cj := errs.ByCategory[categories[i]], errs.ByCategory[categories[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return categories[i] < categories[j]
+	})
+
+	counts := fmt.Sprintf("%d", total)
+	for _, category := range categories {
+		counts += fmt.Sprintf("  %s %d", category, errs.ByCategory[category])
+	}
+
+	lines := []string{fmt.Sprintf("%s  %s", label("Errors"), counts)}
+
+	recent := errs.Recent
+	if len(recent) > maxRecentErrors {
+		recent = recent[len(recent)-maxRecentErrors:]
+	}
+	for _, msg := range recent {
+		lines = append(lines, "  "+strings.Join(strings.Fields(msg), " "))
+	}
+
+	return lines
+}
+
+// bar draws a progress bar width runes wide, filled to percent
+func bar(percent float64, width int) string {
+	filled := int(percent / 100 * float64(width))
+	filled = min(max(filled, 0), width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// sparkline draws the last width rates, scaled to the highest of them
+func sparkline(rates []float64, width int) string {
+	if len(rates) > width {
+		rates = rates[len(rates)-width:]
+	}
+
+	peak := 0.0
+	for _, rate := range rates {
+		peak = max(peak, rate)
+	}
+
+	var b strings.Builder
+	for _, rate := range rates {
+		level := 0
+		if peak > 0 {
+			level = int(rate / peak * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[level])
+	}
+
+	// Pad so the numbers after the sparkline do not move as it fills
+	b.WriteString(strings.Repeat(" ", width-len(rates)))
+	return b.String()
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zuhrulumam/csv_processor/internal/models"
+)
+
+func TestProgressTracker_Dashboard(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	buf := &bytes.Buffer{}
+
+	multi := NewMultiTrackerWithConfig(Config{
+		Writer: buf,
+		Format: FormatDashboard,
+	})
+
+	for _, name := range []string{"orders.csv", "customers.csv", "done.csv"} {
+		multi.AddFile(name, 0)
+	}
+	multi.GetFileTracker("orders.csv").SetTotal(10)
+	multi.GetFileTracker("done.csv").SetTotal(1)
+	multi.Global().SetTotal(20)
+
+	for i := 0; i < 4; i++ {
+		multi.RecordProcessed("orders.csv", models.NewSuccessResult(models.NewRecord(i+2, "orders.csv", nil, nil), nil, 0))
+	}
+	multi.RecordProcessed("customers.csv", models.NewSuccessResult(models.NewRecord(2, "customers.csv", nil, nil), nil, 0))
+	multi.RecordProcessed("done.csv", models.NewSuccessResult(models.NewRecord(2, "done.csv", nil, nil), nil, 0))
+
+	multi.Global().SetErrorStats(func() ErrorStats {
+		return ErrorStats{
+			ByCategory: map[string]int{"VALIDATION": 3, "IO": 1},
+			Recent:     []string{"first", "second", "bad value\non two lines", "latest"},
+		}
+	})
+
+	multi.Global().printProgress()
+	frame := buf.String()
+
+	for _, want := range []string{
+		"Overall          ██████░░░░░░░░░░░░░░  30.0%",
+		"  orders.csv     ████████░░░░░░░░░░░░  40.0%  4 processed",
+		"  customers.csv  1 processed",
+		"Errors           4  VALIDATION 3  IO 1",
+		"  bad value on two lines\n  latest\n",
+	} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("expected %q in dashboard, got:\n%s", want, frame)
+		}
+	}
+
+	// Complete files and the oldest errors are left out
+	if strings.Contains(frame, "done.csv") || strings.Contains(frame, "first") {
+		t.Errorf("expected finished file and old errors to be hidden, got:\n%s", frame)
+	}
+
+	// The next frame is drawn over this one
+	lines := strings.Count(frame, "\n")
+	buf.Reset()
+	multi.Global().printProgress()
+	if want := fmt.Sprintf("\x1b[%dF\x1b[J", lines); !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected redraw over %d lines, got %q", lines, buf.String()[:10])
+	}
+}
+
+func TestDashboard_Helpers(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"empty bar", bar(0, 4), "░░░░"},
+		{"half bar", bar(50, 4), "██░░"},
+		{"overfull bar", bar(150, 4), "████"},
+		{"sparkline", sparkline([]float64{0, 50, 100}, 5), "▁▄█  "},
+		{"sparkline keeps latest", sparkline([]float64{100, 0, 100}, 2), "▁█"},
+		{"short string", truncate("abc", 5), "abc"},
+		{"truncated string", truncate("abcdef", 4), "abc…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "progress.log"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	if IsTerminal(file) {
+		t.Error("expected a regular file not to be a terminal")
+	}
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("expected a buffer not to be a terminal")
+	}
+}
@@ -0,0 +1,136 @@
+package tracker
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Progress output formats
+const (
+	// FormatText writes progress for a terminal
+	FormatText = "text"
+
+	// FormatJSON writes one JSON event per line for other programs to read
+	FormatJSON = "json"
+
+	// FormatDashboard redraws a multi-line dashboard in place, for a terminal
+	FormatDashboard = "dashboard"
+)
+
+// Event types in the JSON stream
+const (
+	EventProgress = "progress"
+	EventSummary  = "summary"
+)
+
+// Event is one line of the JSON progress stream. Total is 0 when the number
+// of records is not known in advance; Percent and ETA then come from bytes
+// read, or are 0 when that is not known either.
+type Event struct {
+	Event      string    `json:"event"`
+	Time       time.Time `json:"time"`
+	Elapsed    float64   `json:"elapsed_seconds"`
+	Processed  uint64    `json:"processed"`
+	Success    uint64    `json:"success"`
+	Failed     uint64    `json:"failed"`
+	Skipped    uint64    `json:"skipped"`
+	Total      uint64    `json:"total"`
+	Percent    float64   `json:"percent"`
+	Throughput float64   `json:"throughput"`
+	ETA        float64   `json:"eta_seconds"`
+
+	// Rate is the throughput over the trailing rate window, when one is set
+	Rate float64 `json:"rate,omitempty"`
+
+	// BytesRead and BytesTotal measure input read so far, which Percent and
+	// ETA are based on when Total is 0
+	BytesRead  int64 `json:"bytes_read,omitempty"`
+	BytesTotal int64 `json:"bytes_total,omitempty"`
+
+	Files []FileStats `json:"files,omitempty"`
+
+	// Latency is only included in the summary event
+	Latency map[string]LatencyStats `json:"latency,omitempty"`
+}
+
+// FileStats is the progress of one input file. Duration is the time between
+// its first and latest processed records, and Throughput is measured over it.
+type FileStats struct {
+	File       string  `json:"file"`
+	Processed  uint64  `json:"processed"`
+	Success    uint64  `json:"success"`
+	Failed     uint64  `json:"failed"`
+	Skipped    uint64  `json:"skipped"`
+	Percent    float64 `json:"percent"`
+	Duration   float64 `json:"duration_seconds"`
+	Throughput float64 `json:"throughput"`
+}
+
+// ErrorRate returns the percentage of the file's records that failed
+func (f FileStats) ErrorRate() float64 {
+	if f.Processed == 0 {
+		return 0
+	}
+	return float64(f.Failed) / float64(f.Processed) * 100
+}
+
+// LatencyStats summarizes one processor's latency histogram in seconds
+type LatencyStats struct {
+	Count uint64  `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	P99   float64 `json:"p99_seconds"`
+	Max   float64 `json:"max_seconds"`
+}
+
+// event builds a JSON event of the given type from the current counters
+func (pt *ProgressTracker) event(kind string) Event {
+	stats := pt.Stats()
+
+	event := Event{
+		Event:      kind,
+		Time:       time.Now().UTC(),
+		Elapsed:    stats.Elapsed.Seconds(),
+		Processed:  stats.Processed,
+		Success:    stats.Success,
+		Failed:     stats.Failed,
+		Skipped:    stats.Skipped,
+		Total:      stats.Total,
+		Percent:    stats.PercentComplete,
+		Throughput: stats.Throughput,
+		ETA:        stats.ETA.Seconds(),
+		Files:      pt.Files(),
+	}
+
+	if pt.rateWindow > 0 {
+		event.Rate = pt.RollingRate()
+	}
+
+	event.BytesRead, event.BytesTotal = pt.Bytes()
+
+	if kind == EventSummary {
+		event.Latency = make(map[string]LatencyStats)
+		for _, name := range pt.latency.Names() {
+			h := pt.latency.Get(name)
+			event.Latency[name] = LatencyStats{
+				Count: h.Count(),
+				P50:   h.Quantile(0.50).Seconds(),
+				P90:   h.Quantile(0.90).Seconds(),
+				P99:   h.Quantile(0.99).Seconds(),
+				Max:   h.Max().Seconds(),
+			}
+		}
+	}
+
+	return event
+}
+
+// writeEvent writes one event as a line of JSON. Progress is best effort, so
+// a reader that has gone away does not stop the run.
+func (pt *ProgressTracker) writeEvent(kind string) {
+	data, err := json.Marshal(pt.event(kind))
+	if err != nil {
+		return
+	}
+	pt.writer.Write(append(data, '\n'))
+}
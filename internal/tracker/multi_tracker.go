@@ -13,18 +13,30 @@ type MultiTracker struct {
 	trackers map[string]*ProgressTracker
 	global   *ProgressTracker
 	mu       sync.RWMutex
+
+	// order lists files in the order they were added
+	order []string
 }
 
 // NewMultiTracker creates a new multi-file progress tracker
 func NewMultiTracker(writer io.Writer, verbose bool) *MultiTracker {
-	return &MultiTracker{
+	return NewMultiTrackerWithConfig(Config{
+		Writer:         writer,
+		UpdateInterval: 1 * time.Second,
+		Verbose:        verbose,
+	})
+}
+
+// NewMultiTrackerWithConfig creates a multi-file progress tracker whose global
+// tracker prints with the given config, including per-file progress
+func NewMultiTrackerWithConfig(config Config) *MultiTracker {
+	mt := &MultiTracker{
 		trackers: make(map[string]*ProgressTracker),
-		global: NewProgressTracker(Config{
-			Writer:         writer,
-			UpdateInterval: 1 * time.Second,
-			Verbose:        verbose,
-		}),
+		global:   NewProgressTracker(config),
 	}
+	mt.global.fileStats = mt.Files
+
+	return mt
 }
 
 // AddFile adds a tracker for a specific file
@@ -38,6 +50,9 @@ func (mt *MultiTracker) AddFile(filename string, expectedRecords uint64) *Progre
 		TotalRecords:   expectedRecords,
 	})
 
+	if _, exists := mt.trackers[filename]; !exists {
+		mt.order = append(mt.order, filename)
+	}
 	mt.trackers[filename] = tracker
 
 	return tracker
@@ -88,11 +103,44 @@ func (mt *MultiTracker) Stop() {
 	mt.global.StopAndPrintFinal()
 }
 
+// Global returns the tracker that counts records of every file
+func (mt *MultiTracker) Global() *ProgressTracker {
+	return mt.global
+}
+
 // GlobalStats returns global statistics
 func (mt *MultiTracker) GlobalStats() Stats {
 	return mt.global.Stats()
 }
 
+// Files returns the progress of each file in the order they were added
+func (mt *MultiTracker) Files() []FileStats {
+	mt.mu.RLock()
+	defer mt.mu.RUnlock()
+
+	files := make([]FileStats, 0, len(mt.order))
+	for _, filename := range mt.order {
+		tracker := mt.trackers[filename]
+
+		stats := FileStats{
+			File:      filename,
+			Processed: tracker.Processed(),
+			Success:   tracker.Success(),
+			Failed:    tracker.Failed(),
+			Skipped:   tracker.Skipped(),
+			Percent:   tracker.PercentComplete(),
+			Duration:  tracker.ProcessingTime().Seconds(),
+		}
+		if stats.Duration > 0 {
+			stats.Throughput = float64(stats.Processed) / stats.Duration
+		}
+
+		files = append(files, stats)
+	}
+
+	return files
+}
+
 // FileStats returns statistics for a specific file
 func (mt *MultiTracker) FileStats(filename string) Stats {
 	mt.mu.RLock()
@@ -47,6 +47,44 @@ type ProgressTracker struct {
 
 	// Verbose mode
 	verbose bool
+
+	// rateWindow enables the rolling-rate display (0 = overall average)
+	rateWindow time.Duration
+
+	// rateMu protects samples
+	rateMu  sync.Mutex
+	samples []rateSample
+
+	// latency holds processing latency per processor
+	latency *models.LatencySet
+
+	// fileStats reports per-file progress when the tracker is the global
+	// tracker of a MultiTracker
+	fileStats func() []FileStats
+
+	// byteProgress reports bytes read and total input size, for percent
+	// complete and ETA when the number of records is not known
+	byteProgress func() (read, size int64)
+
+	// firstAt and lastAt are when the first and latest results were
+	// recorded, in Unix nanoseconds
+	firstAt int64
+	lastAt  int64
+
+	// format is FormatText, FormatJSON or FormatDashboard
+	format string
+
+	// errorStats reports error counts and recent messages for the dashboard
+	errorStats func() ErrorStats
+
+	// dash is the dashboard drawn in FormatDashboard
+	dash dashboard
+}
+
+// rateSample is the processed count at one update tick
+type rateSample struct {
+	at        time.Time
+	processed uint64
 }
 
 // Config holds configuration for progress tracker
@@ -62,6 +100,16 @@ type Config struct {
 
 	// TotalRecords is the expected total (0 = unknown)
 	TotalRecords uint64
+
+	// RateWindow shows the throughput over this trailing window instead of
+	// the overall average, for open-ended runs such as following a file
+	RateWindow time.Duration
+
+	// Format is FormatText (default); FormatJSON, which writes an Event per
+	// update and a summary Event at the end instead of terminal text; or
+	// FormatDashboard, which redraws bars for the run and each active file in
+	// place of the progress line. Verbose does not apply to the dashboard.
+	Format string
 }
 
 // NewProgressTracker creates a new progress tracker
@@ -74,6 +122,10 @@ func NewProgressTracker(config Config) *ProgressTracker {
 		config.UpdateInterval = 1 * time.Second
 	}
 
+	if config.Format == "" {
+		config.Format = FormatText
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	tracker := &ProgressTracker{
@@ -84,6 +136,9 @@ func NewProgressTracker(config Config) *ProgressTracker {
 		ctx:          ctx,
 		cancel:       cancel,
 		verbose:      config.Verbose,
+		rateWindow:   config.RateWindow,
+		latency:      models.NewLatencySet(),
+		format:       config.Format,
 	}
 
 	return tracker
@@ -101,6 +156,7 @@ func (pt *ProgressTracker) Start() error {
 	pt.started = true
 	pt.startTime = time.Now()
 	pt.ticker = time.NewTicker(pt.interval)
+	pt.sample(pt.startTime)
 
 	// Start update loop
 	pt.wg.Add(1)
@@ -118,6 +174,7 @@ func (pt *ProgressTracker) updateLoop() {
 		case <-pt.ctx.Done():
 			return
 		case <-pt.ticker.C:
+			pt.sample(time.Now())
 			pt.printProgress()
 		}
 	}
@@ -131,6 +188,10 @@ func (pt *ProgressTracker) RecordProcessed(result *models.Result) {
 		return
 	}
 
+	now := time.Now().UnixNano()
+	atomic.CompareAndSwapInt64(&pt.firstAt, 0, now)
+	atomic.StoreInt64(&pt.lastAt, now)
+
 	switch result.Status {
 	case models.StatusSuccess:
 		atomic.AddUint64(&pt.successCount, 1)
@@ -139,6 +200,8 @@ func (pt *ProgressTracker) RecordProcessed(result *models.Result) {
 	case models.StatusSkipped:
 		atomic.AddUint64(&pt.skippedCount, 1)
 	}
+
+	pt.latency.Record(result.Processor, result.Duration)
 }
 
 // IncrementProcessed increments the processed counter
@@ -169,6 +232,21 @@ func (pt *ProgressTracker) SetTotal(total uint64) {
 	atomic.StoreUint64(&pt.totalRecords, total)
 }
 
+// SetByteProgress sets where bytes read and total input size come from, so
+// percent complete and ETA are available when the total is not. It must be
+// called before Start.
+func (pt *ProgressTracker) SetByteProgress(fn func() (read, size int64)) {
+	pt.byteProgress = fn
+}
+
+// Bytes returns the bytes read and total input size, or zeros when unknown
+func (pt *ProgressTracker) Bytes() (read, size int64) {
+	if pt.byteProgress == nil {
+		return 0, 0
+	}
+	return pt.byteProgress()
+}
+
 // Processed returns the number of processed records
 func (pt *ProgressTracker) Processed() uint64 {
 	return atomic.LoadUint64(&pt.processedCount)
@@ -199,6 +277,24 @@ func (pt *ProgressTracker) Elapsed() time.Duration {
 	return time.Since(pt.startTime)
 }
 
+// ProcessingTime returns the time between the first and latest results
+// recorded with RecordProcessed
+func (pt *ProgressTracker) ProcessingTime() time.Duration {
+	first := atomic.LoadInt64(&pt.firstAt)
+	if first == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&pt.lastAt) - first)
+}
+
+// Files returns per-file progress when the tracker belongs to a MultiTracker
+func (pt *ProgressTracker) Files() []FileStats {
+	if pt.fileStats == nil {
+		return nil
+	}
+	return pt.fileStats()
+}
+
 // Throughput returns records processed per second
 func (pt *ProgressTracker) Throughput() float64 {
 	elapsed := pt.Elapsed().Seconds()
@@ -226,13 +322,57 @@ func (pt *ProgressTracker) FailureRate() float64 {
 	return float64(pt.Failed()) / float64(processed) * 100
 }
 
+// sample records the processed count for the rolling rate
+func (pt *ProgressTracker) sample(now time.Time) {
+	if pt.rateWindow <= 0 {
+		return
+	}
+
+	pt.rateMu.Lock()
+	defer pt.rateMu.Unlock()
+
+	pt.samples = append(pt.samples, rateSample{at: now, processed: pt.Processed()})
+
+	// Keep one sample at or before the window start as the baseline
+	cutoff := now.Add(-pt.rateWindow)
+	drop := 0
+	for drop+1 < len(pt.samples) && !pt.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	pt.samples = pt.samples[drop:]
+}
+
+// RollingRate returns records processed per second over the rate window,
+// falling back to the overall throughput until two samples exist
+func (pt *ProgressTracker) RollingRate() float64 {
+	pt.rateMu.Lock()
+	defer pt.rateMu.Unlock()
+
+	if len(pt.samples) < 2 {
+		return pt.Throughput()
+	}
+
+	first, last := pt.samples[0], pt.samples[len(pt.samples)-1]
+	seconds := last.at.Sub(first.at).Seconds()
+	if seconds == 0 {
+		return 0
+	}
+
+	return float64(last.processed-first.processed) / seconds
+}
+
 // PercentComplete returns the completion percentage
 func (pt *ProgressTracker) PercentComplete() float64 {
 	total := pt.Total()
-	if total == 0 {
+	if total > 0 {
+		return float64(pt.Processed()) / float64(total) * 100
+	}
+
+	read, size := pt.Bytes()
+	if size == 0 {
 		return 0
 	}
-	return float64(pt.Processed()) / float64(total) * 100
+	return float64(read) / float64(size) * 100
 }
 
 // ETA returns the estimated time to completion
@@ -240,7 +380,11 @@ func (pt *ProgressTracker) ETA() time.Duration {
 	total := pt.Total()
 	processed := pt.Processed()
 
-	if total == 0 || processed == 0 {
+	if total == 0 {
+		return pt.byteETA()
+	}
+
+	if processed == 0 {
 		return 0
 	}
 
@@ -255,8 +399,38 @@ func (pt *ProgressTracker) ETA() time.Duration {
 	return avgTimePerRecord * time.Duration(remaining)
 }
 
+// byteETA estimates the time left from the rate bytes have been read at
+func (pt *ProgressTracker) byteETA() time.Duration {
+	read, size := pt.Bytes()
+	if read == 0 || read >= size {
+		return 0
+	}
+
+	elapsed := pt.Elapsed()
+	return time.Duration(float64(elapsed) * float64(size-read) / float64(read))
+}
+
+// HasProgress reports whether percent complete and ETA can be estimated,
+// from a known total or from bytes read
+func (pt *ProgressTracker) HasProgress() bool {
+	if pt.Total() > 0 {
+		return true
+	}
+	_, size := pt.Bytes()
+	return size > 0
+}
+
 // printProgress prints current progress to the writer
 func (pt *ProgressTracker) printProgress() {
+	switch pt.format {
+	case FormatJSON:
+		pt.writeEvent(EventProgress)
+		return
+	case FormatDashboard:
+		pt.printDashboard()
+		return
+	}
+
 	processed := pt.Processed()
 	success := pt.Success()
 	failed := pt.Failed()
@@ -289,6 +463,32 @@ func (pt *ProgressTracker) printCompactProgress(processed, success, failed, tota
 			throughput,
 			eta.Round(time.Second),
 		)
+	} else if pt.HasProgress() {
+		read, size := pt.Bytes()
+
+		fmt.Fprintf(pt.writer,
+			"\r[%s] Progress: %.1f%% (%s/%s) | Processed: %d | Success: %d | Failed: %d | %.0f rec/s | ETA: %s",
+			elapsed.Round(time.Second),
+			pt.PercentComplete(),
+			formatBytes(read),
+			formatBytes(size),
+			processed,
+			success,
+			failed,
+			throughput,
+			pt.ETA().Round(time.Second),
+		)
+	} else if pt.rateWindow > 0 {
+		fmt.Fprintf(pt.writer,
+			"\r[%s] Processed: %d | Success: %d | Failed: %d | %.0f rec/s (last %s) | avg %.0f rec/s",
+			elapsed.Round(time.Second),
+			processed,
+			success,
+			failed,
+			pt.RollingRate(),
+			pt.rateWindow,
+			throughput,
+		)
 	} else {
 		fmt.Fprintf(pt.writer,
 			"\r[%s] Processed: %d | Success: %d | Failed: %d | %.0f rec/s",
@@ -311,6 +511,11 @@ func (pt *ProgressTracker) printVerboseProgress(processed, success, failed, skip
 
 	if total > 0 {
 		fmt.Fprintf(pt.writer, "Total:       %d\n", total)
+	} else if read, size := pt.Bytes(); size > 0 {
+		fmt.Fprintf(pt.writer, "Read:        %s of %s\n", formatBytes(read), formatBytes(size))
+	}
+
+	if pt.HasProgress() {
 		fmt.Fprintf(pt.writer, "Complete:    %.1f%%\n", pt.PercentComplete())
 		fmt.Fprintf(pt.writer, "ETA:         %s\n", pt.ETA().Round(time.Second))
 	}
@@ -322,12 +527,34 @@ func (pt *ProgressTracker) printVerboseProgress(processed, success, failed, skip
 		fmt.Fprintf(pt.writer, "Skipped:     %d\n", skipped)
 	}
 
+	if pt.rateWindow > 0 {
+		fmt.Fprintf(pt.writer, "Rate (%s): %.0f records/sec\n", pt.rateWindow, pt.RollingRate())
+	}
+
 	fmt.Fprintf(pt.writer, "Throughput:  %.0f records/sec\n", throughput)
+	pt.printLatency()
+
+	if files := pt.Files(); len(files) > 1 {
+		fmt.Fprintf(pt.writer, "Files:\n")
+		for _, file := range files {
+			complete := ""
+			if file.Percent > 0 {
+				complete = fmt.Sprintf("%.1f%%, ", file.Percent)
+			}
+			fmt.Fprintf(pt.writer, "  %s: %s%d processed, %d failed, %.0f records/sec\n",
+				file.File, complete, file.Processed, file.Failed, file.Throughput)
+		}
+	}
 	fmt.Fprintf(pt.writer, "========================================\n")
 }
 
 // PrintFinal prints the final summary
 func (pt *ProgressTracker) PrintFinal() {
+	if pt.format == FormatJSON {
+		pt.writeEvent(EventSummary)
+		return
+	}
+
 	processed := pt.Processed()
 	success := pt.Success()
 	failed := pt.Failed()
@@ -349,9 +576,22 @@ func (pt *ProgressTracker) PrintFinal() {
 	}
 
 	fmt.Fprintf(pt.writer, "Avg Throughput:   %.0f records/sec\n", throughput)
+	pt.printLatency()
 	fmt.Fprintf(pt.writer, "========================================\n")
 }
 
+// printLatency prints one line of latency percentiles per processor
+func (pt *ProgressTracker) printLatency() {
+	for _, name := range pt.latency.Names() {
+		fmt.Fprintf(pt.writer, "Latency (%s): %s\n", name, pt.latency.Get(name))
+	}
+}
+
+// Latency returns the processing latency histograms per processor
+func (pt *ProgressTracker) Latency() *models.LatencySet {
+	return pt.latency
+}
+
 // Stats returns current statistics
 func (pt *ProgressTracker) Stats() Stats {
 	return Stats{
@@ -423,3 +663,19 @@ func (s Stats) String() string {
 		s.Elapsed.Round(time.Second),
 	)
 }
+
+// formatBytes formats a byte count such as 12.3MB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -1,7 +1,9 @@
 package tracker
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -90,6 +92,45 @@ func TestProgressTracker_PercentComplete(t *testing.T) {
 	}
 }
 
+func TestProgressTracker_BytePercentComplete(t *testing.T) {
+	tracker := NewProgressTracker(Config{})
+
+	if tracker.HasProgress() {
+		t.Error("expected no progress estimate without a total or byte sizes")
+	}
+
+	var read int64 = 250
+	tracker.SetByteProgress(func() (int64, int64) {
+		return read, 1000
+	})
+
+	if !tracker.HasProgress() {
+		t.Error("expected progress estimate from byte sizes")
+	}
+	if percent := tracker.PercentComplete(); percent != 25.0 {
+		t.Errorf("expected 25%% complete, got %.1f%%", percent)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if eta := tracker.ETA(); eta <= 0 {
+		t.Error("expected positive ETA from bytes read")
+	}
+
+	read = 1000
+	if eta := tracker.ETA(); eta != 0 {
+		t.Errorf("expected zero ETA once all bytes are read, got %s", eta)
+	}
+
+	// A known total takes precedence over bytes
+	tracker.SetTotal(200)
+	for i := 0; i < 50; i++ {
+		tracker.IncrementSuccess()
+	}
+	if percent := tracker.PercentComplete(); percent != 25.0 {
+		t.Errorf("expected 25%% complete from total, got %.1f%%", percent)
+	}
+}
+
 func TestProgressTracker_Throughput(t *testing.T) {
 	tracker := NewProgressTracker(Config{})
 
@@ -116,6 +157,35 @@ func TestProgressTracker_Throughput(t *testing.T) {
 	t.Logf("Throughput: %.0f records/sec", throughput)
 }
 
+func TestProgressTracker_RollingRate(t *testing.T) {
+	tracker := NewProgressTracker(Config{RateWindow: 10 * time.Second})
+
+	start := time.Now()
+
+	// 1000 records in the first 10s, then 100 records in the next 10s
+	tracker.sample(start)
+	for i := 0; i < 1000; i++ {
+		tracker.IncrementSuccess()
+	}
+	tracker.sample(start.Add(10 * time.Second))
+	for i := 0; i < 100; i++ {
+		tracker.IncrementSuccess()
+	}
+	tracker.sample(start.Add(20 * time.Second))
+
+	if rate := tracker.RollingRate(); rate != 10 {
+		t.Errorf("expected rolling rate 10 rec/s, got %.1f", rate)
+	}
+
+	var buf bytes.Buffer
+	tracker.writer = &buf
+	tracker.printProgress()
+
+	if !strings.Contains(buf.String(), "10 rec/s (last 10s)") {
+		t.Errorf("expected rolling rate in progress output, got %q", buf.String())
+	}
+}
+
 func TestProgressTracker_ETA(t *testing.T) {
 	tracker := NewProgressTracker(Config{
 		TotalRecords: 1000,
@@ -360,3 +430,171 @@ func BenchmarkProgressTracker_Concurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestProgressTracker_Latency(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	tracker := NewProgressTracker(Config{
+		Writer:  buf,
+		Verbose: true,
+	})
+
+	for i := 1; i <= 100; i++ {
+		record := models.NewRecord(i+1, "test.csv", []string{"data"}, nil)
+		result := models.NewSuccessResult(record, nil, time.Duration(i)*time.Millisecond)
+		result.Processor = "enrich"
+		tracker.RecordProcessed(result)
+	}
+
+	latency := tracker.Latency().Get("enrich")
+	if latency == nil {
+		t.Fatal("expected latency for enrich")
+	}
+	if latency.Count() != 100 {
+		t.Errorf("expected 100 latencies, got %d", latency.Count())
+	}
+	if latency.Max() != 100*time.Millisecond {
+		t.Errorf("expected max 100ms, got %s", latency.Max())
+	}
+
+	tracker.PrintFinal()
+
+	if !strings.Contains(buf.String(), "Latency (enrich): p50 ") {
+		t.Errorf("expected latency line in final output, got:\n%s", buf.String())
+	}
+}
+
+func TestProgressTracker_JSONEvents(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	multi := NewMultiTrackerWithConfig(Config{
+		Writer:         buf,
+		UpdateInterval: 50 * time.Millisecond,
+		TotalRecords:   4,
+		Format:         FormatJSON,
+	})
+	multi.AddFile("a.csv", 0)
+	multi.AddFile("b.csv", 0)
+
+	if err := multi.Start(); err != nil {
+		t.Fatalf("failed to start tracker: %v", err)
+	}
+
+	statuses := []models.ProcessingStatus{models.StatusSuccess, models.StatusFailed, models.StatusSuccess}
+	for i, status := range statuses {
+		file := "a.csv"
+		if i == 2 {
+			file = "b.csv"
+		}
+		record := models.NewRecord(i+2, file, []string{"data"}, nil)
+		multi.RecordProcessed(file, &models.Result{Record: record, Status: status, Duration: time.Millisecond, Processor: "default"})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	multi.Stop()
+
+	var events []Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) < 2 {
+		t.Fatalf("expected progress and summary events, got %d", len(events))
+	}
+	if events[0].Event != EventProgress {
+		t.Errorf("expected first event %q, got %q", EventProgress, events[0].Event)
+	}
+
+	summary := events[len(events)-1]
+	if summary.Event != EventSummary {
+		t.Fatalf("expected last event %q, got %q", EventSummary, summary.Event)
+	}
+	if summary.Processed != 3 || summary.Success != 2 || summary.Failed != 1 || summary.Total != 4 {
+		t.Errorf("unexpected summary counts: %+v", summary)
+	}
+	if summary.Percent != 75 {
+		t.Errorf("expected 75 percent, got %v", summary.Percent)
+	}
+
+	if len(summary.Files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", summary.Files)
+	}
+	if f := summary.Files[0]; f.File != "a.csv" || f.Processed != 2 || f.Success != 1 || f.Failed != 1 {
+		t.Errorf("unexpected a.csv stats: %+v", f)
+	}
+	if f := summary.Files[1]; f.File != "b.csv" || f.Processed != 1 || f.Success != 1 || f.Failed != 0 {
+		t.Errorf("unexpected b.csv stats: %+v", f)
+	}
+
+	if latency := summary.Latency["default"]; latency.Count != 3 || latency.Max != 0.001 {
+		t.Errorf("expected 3 latencies up to 1ms, got %+v", latency)
+	}
+	if events[0].Latency != nil {
+		t.Error("expected no latency in progress events")
+	}
+}
+
+func TestMultiTracker_Files(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	multi := NewMultiTrackerWithConfig(Config{
+		Writer:  buf,
+		Verbose: true,
+	})
+
+	// Files are reported in the order they were added
+	for _, name := range []string{"orders.csv", "customers.csv", "items.csv"} {
+		multi.AddFile(name, 0)
+	}
+
+	for i := 0; i < 10; i++ {
+		status := models.StatusSuccess
+		if i%5 == 0 {
+			status = models.StatusFailed
+		}
+		record := models.NewRecord(i+2, "customers.csv", []string{"data"}, nil)
+		multi.RecordProcessed("customers.csv", &models.Result{Record: record, Status: status})
+		time.Sleep(time.Millisecond)
+	}
+	multi.RecordProcessed("orders.csv", models.NewSuccessResult(models.NewRecord(2, "orders.csv", nil, nil), nil, 0))
+
+	files := multi.Files()
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+
+	names := []string{files[0].File, files[1].File, files[2].File}
+	if strings.Join(names, ",") != "orders.csv,customers.csv,items.csv" {
+		t.Errorf("expected files in the order added, got %v", names)
+	}
+
+	customers := files[1]
+	if customers.Processed != 10 || customers.Failed != 2 {
+		t.Errorf("expected 10 processed and 2 failed, got %+v", customers)
+	}
+	if customers.ErrorRate() != 20 {
+		t.Errorf("expected 20%% error rate, got %.1f", customers.ErrorRate())
+	}
+	if customers.Duration <= 0 || customers.Throughput <= 0 {
+		t.Errorf("expected duration and throughput, got %+v", customers)
+	}
+
+	if files[2].Processed != 0 || files[2].Throughput != 0 {
+		t.Errorf("expected no progress for items.csv, got %+v", files[2])
+	}
+
+	if multi.GlobalStats().Processed != 11 {
+		t.Errorf("expected 11 processed globally, got %d", multi.GlobalStats().Processed)
+	}
+
+	// Verbose progress lists each file
+	multi.Global().printProgress()
+	if !strings.Contains(buf.String(), "  customers.csv: 10 processed, 2 failed") {
+		t.Errorf("expected per-file line in verbose output, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,38 @@
+package tracker
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// defaultWidth is the dashboard width when the terminal size is unknown
+const defaultWidth = 80
+
+// IsTerminal reports whether w is a terminal, which the dashboard can be
+// drawn on
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	_, ok = windowWidth(f)
+	return ok
+}
+
+// terminalWidth returns the width of the terminal w in columns, from the
+// terminal itself or $COLUMNS, defaulting to 80
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok {
+		if width, _ := windowWidth(f); width > 0 {
+			return width
+		}
+	}
+
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+
+	return defaultWidth
+}
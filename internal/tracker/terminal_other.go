@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package tracker
+
+import "os"
+
+// windowWidth reports whether f is a character device, taken to be a
+// terminal; its width is not known here
+func windowWidth(f *os.File) (int, bool) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, false
+	}
+	return 0, info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config holds configuration for Watcher
+type Config struct {
+	// Dir is the directory to watch; subdirectories are ignored
+	Dir string
+
+	// Pattern selects file names, as in filepath.Match (default: *.csv)
+	Pattern string
+
+	// PollInterval is how often Dir is listed (default: 1s)
+	PollInterval time.Duration
+
+	// SettleTime is how long a file's size and modification time must stay
+	// unchanged before it is considered complete (default: 2s)
+	SettleTime time.Duration
+}
+
+// Watcher polls a directory and reports files once they stop growing
+type Watcher struct {
+	config Config
+
+	// files tracks every matching file seen in the last poll
+	files map[string]*fileState
+}
+
+// fileState is what the watcher knows about one file
+type fileState struct {
+	size    int64
+	modTime time.Time
+
+	// changedAt is when size or modTime last changed
+	changedAt time.Time
+
+	// emitted is set once the file has been reported
+	emitted bool
+}
+
+// NewWatcher creates a new Watcher
+func NewWatcher(config Config) (*Watcher, error) {
+	if config.Pattern == "" {
+		config.Pattern = "*.csv"
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.SettleTime <= 0 {
+		config.SettleTime = 2 * time.Second
+	}
+
+	if _, err := filepath.Match(config.Pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", config.Pattern, err)
+	}
+
+	info, err := os.Stat(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", config.Dir)
+	}
+
+	return &Watcher{
+		config: config,
+		files:  make(map[string]*fileState),
+	}, nil
+}
+
+// Watch polls until ctx is canceled, sending each batch of files that became
+// complete during one poll. A file is reported once, until it changes or is
+// removed, so callers that move processed files away must retry failed moves.
+// A file that appears later with the same name is reported again.
+func (w *Watcher) Watch(ctx context.Context) (<-chan []string, <-chan error) {
+	batchCh := make(chan []string)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(batchCh)
+		defer close(errCh)
+
+		ticker := time.NewTicker(w.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			batch, err := w.Poll(time.Now())
+			if err != nil {
+				// Listing errors are usually transient (e.g. the directory is
+				// being recreated); report them without stopping
+				select {
+				case errCh <- err:
+				default:
+				}
+			}
+
+			if len(batch) > 0 {
+				select {
+				case batchCh <- batch:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return batchCh, errCh
+}
+
+// Poll lists the directory once and returns the files that are complete as of
+// now and have not been reported before, sorted by name
+func (w *Watcher) Poll(now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(w.config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", w.config.Dir, err)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	var ready []string
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if ok, _ := filepath.Match(w.config.Pattern, name); !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between listing and stat
+			continue
+		}
+
+		seen[name] = true
+
+		state, ok := w.files[name]
+		if !ok || state.size != info.Size() || !state.modTime.Equal(info.ModTime()) {
+			w.files[name] = &fileState{
+				size:      info.Size(),
+				modTime:   info.ModTime(),
+				changedAt: now,
+			}
+			continue
+		}
+
+		if !state.emitted && now.Sub(state.changedAt) >= w.config.SettleTime {
+			state.emitted = true
+			ready = append(ready, filepath.Join(w.config.Dir, name))
+		}
+	}
+
+	// Forget files that were moved away
+	for name := range w.files {
+		if !seen[name] {
+			delete(w.files, name)
+		}
+	}
+
+	sort.Strings(ready)
+	return ready, nil
+}
@@ -0,0 +1,107 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWatcher(Config{Dir: dir, SettleTime: time.Second})
+	if err != nil {
+		t.Fatalf("NewWatcher() error: %v", err)
+	}
+
+	file := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, ".partial.csv"), []byte("x"), 0644)
+	os.Mkdir(filepath.Join(dir, "done"), 0755)
+
+	start := time.Now()
+
+	// First sighting only starts the settle timer
+	if ready, _ := w.Poll(start); len(ready) != 0 {
+		t.Fatalf("expected no files on first poll, got %v", ready)
+	}
+
+	// Still growing: the timer restarts
+	if err := os.WriteFile(file, []byte("id\n1\n2\n"), 0644); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	if ready, _ := w.Poll(start.Add(2 * time.Second)); len(ready) != 0 {
+		t.Fatalf("expected growing file to be held back, got %v", ready)
+	}
+
+	if ready, _ := w.Poll(start.Add(2500 * time.Millisecond)); len(ready) != 0 {
+		t.Fatalf("expected file to wait for settle time, got %v", ready)
+	}
+
+	ready, _ := w.Poll(start.Add(3 * time.Second))
+	if len(ready) != 1 || ready[0] != file {
+		t.Fatalf("expected %s to be ready, got %v", file, ready)
+	}
+
+	// Reported only once
+	if ready, _ := w.Poll(start.Add(10 * time.Second)); len(ready) != 0 {
+		t.Errorf("expected file to be reported once, got %v", ready)
+	}
+
+	// A new file with the same name after a move is reported again
+	os.Remove(file)
+	w.Poll(start.Add(11 * time.Second))
+	os.WriteFile(file, []byte("id\n3\n"), 0644)
+	w.Poll(start.Add(12 * time.Second))
+	if ready, _ := w.Poll(start.Add(14 * time.Second)); len(ready) != 1 {
+		t.Errorf("expected replaced file to be reported, got %v", ready)
+	}
+}
+
+func TestWatcher_Watch(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWatcher(Config{
+		Dir:          dir,
+		PollInterval: 10 * time.Millisecond,
+		SettleTime:   20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id\n1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.csv"), []byte("id\n2\n"), 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batchCh, _ := w.Watch(ctx)
+
+	select {
+	case batch := <-batchCh:
+		if len(batch) != 2 {
+			t.Errorf("expected both files in one batch, got %v", batch)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for batch")
+	}
+
+	cancel()
+	for range batchCh {
+	}
+}
+
+func TestNewWatcher_NotADirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.csv")
+	os.WriteFile(file, []byte("id\n"), 0644)
+
+	if _, err := NewWatcher(Config{Dir: file}); err == nil {
+		t.Error("expected error for non-directory")
+	}
+}
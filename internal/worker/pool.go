@@ -3,12 +3,16 @@ package worker
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/zuhrulumam/csv_processor/internal/errors"
 	"github.com/zuhrulumam/csv_processor/internal/models"
 	"github.com/zuhrulumam/csv_processor/internal/processor"
+	"github.com/zuhrulumam/csv_processor/internal/tracing"
 )
 
 // Pool manages a pool of workers that process records concurrently
@@ -19,6 +23,9 @@ type Pool struct {
 	// processor processes individual records
 	processor processor.Processor
 
+	// name is the processor's name, recorded on results that lack one
+	name string
+
 	// inputCh receives records to process
 	inputCh <-chan *models.Record
 
@@ -40,11 +47,29 @@ type Pool struct {
 	// cancel cancels the context
 	cancel context.CancelFunc
 
+	// active counts workers currently processing a record
+	active int64
+
+	// stats counts the records each worker processed, indexed by worker ID
+	stats []workerCounters
+
 	// started indicates if the pool has been started
 	started bool
 
 	// mu protects started flag
 	mu sync.Mutex
+
+	// logger receives failed records and dropped errors at debug level
+	logger *slog.Logger
+
+	// tracer records batch spans of each worker's waits and processor calls
+	tracer *tracing.Tracer
+}
+
+// workerCounters are one worker's record counts, updated atomically
+type workerCounters struct {
+	processed atomic.Uint64
+	failed    atomic.Uint64
 }
 
 // Config holds configuration for the worker pool
@@ -63,6 +88,13 @@ type Config struct {
 
 	// ErrorBufferSize is the size of the error channel buffer
 	ErrorBufferSize int
+
+	// Logger receives pool and record failure events (default: discard)
+	Logger *slog.Logger
+
+	// Tracer, if set, records batch spans per worker of input waits,
+	// processor calls and output waits
+	Tracer *tracing.Tracer
 }
 
 // NewPool creates a new worker pool
@@ -85,14 +117,22 @@ func NewPool(config Config) *Pool {
 		config.Processor = processor.NewDefaultProcessor()
 	}
 
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.DiscardHandler)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Pool{
 		workers:   config.Workers,
 		processor: config.Processor,
+		name:      processor.Name(config.Processor),
 		inputCh:   config.InputChannel,
 		outputCh:  make(chan *models.Result, config.OutputBufferSize),
 		errorCh:   make(chan error, config.ErrorBufferSize),
+		stats:     make([]workerCounters, config.Workers),
+		logger:    config.Logger,
+		tracer:    config.Tracer,
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -109,6 +149,8 @@ func (p *Pool) Start() error {
 
 	p.started = true
 
+	p.logger.Debug("starting workers", "workers", p.workers, "processor", p.name)
+
 	// Start workers
 	for i := 0; i < p.workers; i++ {
 		p.wg.Add(1)
@@ -129,6 +171,10 @@ func (p *Pool) Start() error {
 func (p *Pool) worker(id int) {
 	defer p.wg.Done()
 
+	lane := p.tracer.Lane(fmt.Sprintf("worker %d", id))
+	batch := p.tracer.Batch(lane, "process batch", "process", tracing.DefaultBatchSize)
+	defer batch.Flush()
+
 	for {
 		p.ctxMu.RLock()
 		ctx := p.ctx
@@ -145,8 +191,13 @@ func (p *Pool) worker(id int) {
 				return
 			}
 
+			batch.Wait("input wait")
+
 			// Process the record
-			result := p.processRecord(record)
+			atomic.AddInt64(&p.active, 1)
+			result := p.processRecord(id, record)
+			atomic.AddInt64(&p.active, -1)
+			batch.Work("process")
 
 			// Send result to output channel (non-blocking)
 			select {
@@ -154,16 +205,18 @@ func (p *Pool) worker(id int) {
 			case <-ctx.Done():
 				return
 			}
+			batch.Wait("output wait")
+			batch.Done()
 		}
 	}
 }
 
 // processRecord processes a single record and measures duration
-func (p *Pool) processRecord(record *models.Record) *models.Result {
+func (p *Pool) processRecord(id int, record *models.Record) *models.Result {
 	startTime := time.Now()
 
 	p.ctxMu.RLock()
-	ctx := p.ctx
+	ctx := processor.WithWorkerID(p.ctx, id)
 	p.ctxMu.RUnlock()
 
 	// Process with context
@@ -171,22 +224,39 @@ func (p *Pool) processRecord(record *models.Record) *models.Result {
 
 	duration := time.Since(startTime)
 
+	// Count the record against this worker
+	if err != nil || (result != nil && result.IsFailed()) {
+		p.stats[id].failed.Add(1)
+	} else {
+		p.stats[id].processed.Add(1)
+	}
+
 	// Handle processing error
 	if err != nil {
+		if p.logger.Enabled(ctx, slog.LevelDebug) {
+			p.logger.Debug("record failed", append(errors.Attrs(err, record), "worker", id, "processor", p.name)...)
+		}
+
 		// Send error to error channel (non-blocking)
 		select {
 		case p.errorCh <- err:
 		default:
 			// Error channel full, skip
+			p.logger.Debug("error channel full, error dropped", "worker", id)
 		}
 
-		return models.NewFailedResult(record, err, duration)
+		failed := models.NewFailedResult(record, err, duration)
+		failed.Processor = p.name
+		return failed
 	}
 
-	// Set duration if not already set
+	// Set duration and processor if not already set
 	if result.Duration == 0 {
 		result.Duration = duration
 	}
+	if result.Processor == "" {
+		result.Processor = p.name
+	}
 
 	return result
 }
@@ -196,6 +266,24 @@ func (p *Pool) Results() <-chan *models.Result {
 	return p.outputCh
 }
 
+// Active returns the number of workers currently processing a record
+func (p *Pool) Active() int {
+	return int(atomic.LoadInt64(&p.active))
+}
+
+// WorkerStats returns the records each worker has processed and failed so far
+func (p *Pool) WorkerStats() []WorkerStats {
+	stats := make([]WorkerStats, len(p.stats))
+	for i := range p.stats {
+		stats[i] = WorkerStats{
+			ID:        i,
+			Processed: p.stats[i].processed.Load(),
+			Failed:    p.stats[i].failed.Load(),
+		}
+	}
+	return stats
+}
+
 // Errors returns the error channel
 func (p *Pool) Errors() <-chan error {
 	return p.errorCh
@@ -75,6 +75,42 @@ func TestPool_BasicProcessing(t *testing.T) {
 	}
 }
 
+func TestPool_ResultDurationAndProcessor(t *testing.T) {
+	inputCh := make(chan *models.Record, 2)
+	inputCh <- models.NewRecord(1, "test.csv", []string{"data"}, nil)
+	inputCh <- models.NewRecord(2, "test.csv", []string{"data"}, nil)
+	close(inputCh)
+
+	mock := &mockProcessor{
+		processFunc: func(ctx context.Context, record *models.Record) (*models.Result, error) {
+			time.Sleep(time.Millisecond)
+			if record.LineNumber == 2 {
+				return nil, fmt.Errorf("boom")
+			}
+			return models.NewSuccessResult(record, nil, 0), nil
+		},
+	}
+
+	pool := NewPool(Config{
+		Workers:      1,
+		Processor:    mock,
+		InputChannel: inputCh,
+	})
+
+	if err := pool.Start(); err != nil {
+		t.Fatalf("failed to start pool: %v", err)
+	}
+
+	for result := range pool.Results() {
+		if result.Duration < time.Millisecond {
+			t.Errorf("line %d: expected duration of at least 1ms, got %s", result.Record.LineNumber, result.Duration)
+		}
+		if result.Processor != "custom" {
+			t.Errorf("line %d: expected processor custom, got %q", result.Record.LineNumber, result.Processor)
+		}
+	}
+}
+
 func TestPool_ErrorHandling(t *testing.T) {
 	inputCh := make(chan *models.Record, 5)
 
@@ -123,6 +159,61 @@ func TestPool_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestPool_WorkerStats(t *testing.T) {
+	inputCh := make(chan *models.Record, 6)
+	for i := 0; i < 6; i++ {
+		inputCh <- models.NewRecord(i+1, "test.csv", []string{"data"}, nil)
+	}
+	close(inputCh)
+
+	// Failures are counted whether returned as an error or a failed result
+	mock := &mockProcessor{
+		processFunc: func(ctx context.Context, record *models.Record) (*models.Result, error) {
+			switch record.LineNumber {
+			case 1:
+				return nil, fmt.Errorf("boom")
+			case 2:
+				return models.NewFailedResult(record, fmt.Errorf("bad line"), 0), nil
+			}
+			return models.NewSuccessResult(record, record.Data, 0), nil
+		},
+	}
+
+	pool := NewPool(Config{
+		Workers:      3,
+		Processor:    mock,
+		InputChannel: inputCh,
+	})
+
+	if err := pool.Start(); err != nil {
+		t.Fatalf("failed to start pool: %v", err)
+	}
+
+	for range pool.Results() {
+	}
+
+	stats := pool.WorkerStats()
+	if len(stats) != 3 {
+		t.Fatalf("expected stats for 3 workers, got %d", len(stats))
+	}
+
+	var processed, failed uint64
+	for i, s := range stats {
+		if s.ID != i {
+			t.Errorf("expected worker ID %d, got %d", i, s.ID)
+		}
+		processed += s.Processed
+		failed += s.Failed
+	}
+
+	if processed != 4 {
+		t.Errorf("expected 4 processed records, got %d", processed)
+	}
+	if failed != 2 {
+		t.Errorf("expected 2 failed records, got %d", failed)
+	}
+}
+
 func TestPool_ContextCancellation(t *testing.T) {
 	inputCh := make(chan *models.Record, 100)
 
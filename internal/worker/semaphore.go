@@ -123,7 +123,7 @@ func (p *PoolWithSemaphore) workerWithSemaphore(id int) {
 			}
 
 			// Process record
-			result := p.processRecord(record)
+			result := p.processRecord(id, record)
 
 			// Release semaphore slot
 			p.semaphore.Release()
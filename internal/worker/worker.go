@@ -74,9 +74,9 @@ func (w *Worker) Stats() WorkerStats {
 
 // WorkerStats holds statistics for a worker
 type WorkerStats struct {
-	ID        int
-	Processed uint64
-	Failed    uint64
+	ID        int    `json:"id"`
+	Processed uint64 `json:"processed"`
+	Failed    uint64 `json:"failed"`
 }
 
 // BatchWorker processes multiple records in batches
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -46,7 +47,7 @@ func TestIntegration_EndToEnd(t *testing.T) {
 
 	startTime := time.Now()
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -104,7 +105,7 @@ func TestIntegration_MultipleFiles(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -139,7 +140,7 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -186,7 +187,7 @@ func TestIntegration_LargeFile(t *testing.T) {
 
 	startTime := time.Now()
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -231,7 +232,7 @@ func TestIntegration_GracefulShutdown(t *testing.T) {
 	// Run in background
 	done := make(chan error)
 	go func() {
-		done <- pipe.Run()
+		done <- pipe.Run(context.Background())
 	}()
 
 	// Wait a bit then stop
@@ -288,7 +289,7 @@ func TestIntegration_ErrorThreshold(t *testing.T) {
 	}
 
 	// Run pipeline - should abort
-	err = pipe.Run()
+	err = pipe.Run(context.Background())
 
 	if err == nil {
 		t.Error("expected error when threshold exceeded")
@@ -324,7 +325,7 @@ func TestIntegration_EmptyFile(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	err = pipe.Run()
+	err = pipe.Run(context.Background())
 
 	// Should handle empty file gracefully
 	if pipe.Errors().HasErrors() {
@@ -355,7 +356,7 @@ func TestIntegration_HeaderOnly(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -386,7 +387,7 @@ func BenchmarkIntegration_1000Records(b *testing.B) {
 			ShowProgress: false,
 		})
 
-		pipe.Run()
+		pipe.Run(context.Background())
 	}
 }
 
@@ -414,7 +415,7 @@ func BenchmarkIntegration_ConcurrentWorkers(b *testing.B) {
 					ShowProgress: false,
 				})
 
-				pipe.Run()
+				pipe.Run(context.Background())
 			}
 		})
 	}
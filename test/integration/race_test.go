@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -45,7 +46,7 @@ func TestRace_ConcurrentPipelines(t *testing.T) {
 				return
 			}
 
-			if err := pipe.Run(); err != nil {
+			if err := pipe.Run(context.Background()); err != nil {
 				errors <- err
 			}
 		}(i)
@@ -84,7 +85,7 @@ func TestRace_SharedResources(t *testing.T) {
 	}
 
 	// Run pipeline (will be tested with -race flag)
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -117,7 +118,7 @@ func TestRace_ProgressTracker(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 }
@@ -144,7 +145,7 @@ func TestRace_ErrorCollector(t *testing.T) {
 		t.Fatalf("failed to create pipeline: %v", err)
 	}
 
-	if err := pipe.Run(); err != nil {
+	if err := pipe.Run(context.Background()); err != nil {
 		t.Fatalf("pipeline execution failed: %v", err)
 	}
 
@@ -182,7 +183,7 @@ func TestRace_StopDuringExecution(t *testing.T) {
 		// Run in background
 		done := make(chan error)
 		go func() {
-			done <- pipe.Run()
+			done <- pipe.Run(context.Background())
 		}()
 
 		// Stop at random time